@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/backstagefood/video-processor-worker/pkg/config"
+)
+
+// runConfigCommand executa os subcomandos de configuração e retorna o código de saída. config print
+// escreve a configuração efetiva, já validada, com os segredos omitidos
+func runConfigCommand(args []string) int {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, "uso: config print [--config arquivo] [flags]")
+		return 2
+	}
+	settings, err := config.Load(args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "configuração inválida:\n%v\n", err)
+		return 1
+	}
+	if err := settings.Print(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "não foi possível imprimir a configuração: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// modeArgs converte os subcomandos serve-api, run-worker e all na flag --mode, equivalente a RUN_MODE
+func modeArgs(args []string) []string {
+	if len(args) == 0 {
+		return args
+	}
+	switch args[0] {
+	case config.ModeAPI, config.ModeWorker, config.ModeAll:
+		return append([]string{"--mode=" + args[0]}, args[1:]...)
+	}
+	return args
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestModeArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{name: "no arguments", args: nil, expected: nil},
+		{name: "serve-api", args: []string{"serve-api", "--server-port=9090"}, expected: []string{"--mode=serve-api", "--server-port=9090"}},
+		{name: "run-worker", args: []string{"run-worker"}, expected: []string{"--mode=run-worker"}},
+		{name: "all", args: []string{"all", "--config", "config.yaml"}, expected: []string{"--mode=all", "--config", "config.yaml"}},
+		{name: "flags only", args: []string{"--mode=run-worker"}, expected: []string{"--mode=run-worker"}},
+		{name: "unknown subcommand", args: []string{"migrate"}, expected: []string{"migrate"}},
+		{name: "mode after flags", args: []string{"--config", "config.yaml", "serve-api"}, expected: []string{"--config", "config.yaml", "serve-api"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := modeArgs(tt.args); !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
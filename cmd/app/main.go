@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"github.com/backstagefood/video-processor-worker/pkg/adapter"
 	"log"
@@ -13,7 +15,10 @@ import (
 	"time"
 
 	routes "github.com/backstagefood/video-processor-worker/internal/controller/router"
-	"github.com/backstagefood/video-processor-worker/utils"
+	"github.com/backstagefood/video-processor-worker/internal/usecase"
+	"github.com/backstagefood/video-processor-worker/pkg/adapter/metrics"
+	"github.com/backstagefood/video-processor-worker/pkg/adapter/tracing"
+	"github.com/backstagefood/video-processor-worker/pkg/config"
 )
 
 // @title Video Processor Worker
@@ -30,15 +35,69 @@ import (
 // @host localhost:8080
 // @BasePath /
 func main() {
+	// os logs passam a carregar os ids do trace e do span ativos no contexto. O nível acompanha LOG_LEVEL,
+	// inclusive nas recargas da configuração
+	logLevel := new(slog.LevelVar)
+	slog.SetDefault(slog.New(tracing.NewLogHandler(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel}))))
 
-	serverPort := utils.GetEnvVarOrDefault("SERVER_PORT", "8080")
-	slog.Info(fmt.Sprintf("🎬 servidor iniciado na porta %s", serverPort))
-	slog.Info(fmt.Sprintf("📂 acesse: http://localhost:%s\n", serverPort))
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "config" {
+		os.Exit(runConfigCommand(args[1:]))
+	}
+	args = modeArgs(args)
+	// a configuração inteira é validada antes de abrir qualquer conexão
+	settings, err := config.Load(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatalf("configuração inválida:\n%v", err)
+	}
+	logLevel.Set(settings.Log.SlogLevel())
+	if settings.File() != "" {
+		slog.Info("arquivo de configuração carregado", "file", settings.File())
+	}
+	runtime := config.NewRuntime(settings, args)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), settings.Tracing)
+	if err != nil {
+		log.Fatalf("erro ao configurar os traces: %v", err)
+	}
+
+	serverPort := settings.Server.Port
+	shutdownGracePeriod := settings.Server.ShutdownGracePeriod
+	slog.Info(fmt.Sprintf("🎬 servidor iniciado na porta %d", serverPort), "mode", settings.Mode)
+	slog.Info(fmt.Sprintf("📂 acesse: http://localhost:%d\n", serverPort))
 
-	router := routes.NewRouter(adapter.NewConnectionManager())
+	applicationMetrics := metrics.NewPrometheusMetrics()
+	connectionManager := adapter.NewConnectionManager(settings, applicationMetrics)
+	jobRegistry := usecase.NewJobRegistry()
+	backgroundTasks := usecase.NewBackgroundTasks()
+
+	// intakeCtx controla o recebimento de novas mensagens e as rotinas em segundo plano
+	intakeCtx, stopIntake := context.WithCancel(context.Background())
+	var jobWorker *worker
+	if settings.RunsWorker() {
+		jobWorker = startWorker(intakeCtx, runtime, connectionManager, jobRegistry, applicationMetrics)
+	}
+
+	// as recargas da configuração, por SIGHUP, pelo arquivo ou pela rota administrativa, alteram os
+	// componentes em execução sem reiniciar
+	runtime.Subscribe(func(settings *config.Config) {
+		logLevel.Set(settings.Log.SlogLevel())
+	})
+	go reloadOnSignal(intakeCtx, runtime)
+	go runtime.Watch(intakeCtx, settings.Reload.WatchInterval)
+
+	healthService := usecase.NewHealthService(connectionManager.GetHealthChecks(), settings.Health)
+	// o modo run-worker não expõe a API, apenas a saúde e as métricas para as sondas e o Prometheus
+	router := routes.NewOpsRouter(applicationMetrics, healthService)
+	if settings.RunsAPI() {
+		router = routes.NewRouter(connectionManager, jobRegistry, backgroundTasks, applicationMetrics, healthService, runtime)
+	}
 
 	srv := &http.Server{
-		Addr:    fmt.Sprintf(":%s", serverPort),
+		Addr:    fmt.Sprintf(":%d", serverPort),
 		Handler: router.Handler(),
 	}
 
@@ -51,15 +110,66 @@ func main() {
 	quit := make(chan os.Signal, 21)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	slog.Info("parando servidor")
+	slog.Info("parando servidor", "gracePeriod", shutdownGracePeriod)
+	// a prontidão passa a falhar para que o balanceador pare de enviar requisições durante o desligamento
+	healthService.StartDraining()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	// 1. para de receber novas mensagens e aguarda os processamentos em execução; os que não terminarem no
+	// prazo voltam para a fila. O source é fechado em seguida, depois das confirmações
+	stopIntake()
+	// as solicitações sobre os dados da conta têm o mesmo prazo; as interrompidas ficam registradas com falha
+	tasksDone := make(chan struct{})
+	go func() {
+		defer close(tasksDone)
+		tasksCtx, cancelTasks := context.WithTimeout(context.Background(), shutdownGracePeriod)
+		defer cancelTasks()
+		if err := backgroundTasks.Shutdown(tasksCtx); err != nil {
+			slog.Error("erro ao finalizar as tarefas em segundo plano", "err", err)
+		}
+	}()
+	if jobWorker != nil {
+		jobWorker.shutdown(shutdownGracePeriod)
+		if err := connectionManager.GetDeadLetterProducer().Close(); err != nil {
+			slog.Error("erro ao fechar o produtor de dead letter", "err", err)
+		}
+	}
+
+	<-tasksDone
+
+	// 2. fecha as conexões. O servidor HTTP é parado antes da base de dados, usada pelas requisições em andamento
+	if err := connectionManager.GetMessageProducer().Close(); err != nil {
+		slog.Error("erro ao fechar o produtor de mensagens", "err", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
 		slog.Error("erro ao parar servidor", "err", err)
 	}
 
-	<-ctx.Done()
-	slog.Info("tempo esgotado - 1 segundo")
+	if err := connectionManager.GetDBConn().Close(); err != nil {
+		slog.Error("erro ao fechar a conexão com o banco de dados", "err", err)
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("erro ao enviar os traces pendentes", "err", err)
+	}
 	slog.Info("servidor parado com sucesso")
 }
+
+// reloadOnSignal recarrega a configuração a cada SIGHUP até o contexto ser cancelado
+func reloadOnSignal(ctx context.Context, runtime *config.Runtime) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			slog.Info("SIGHUP recebido, recarregando a configuração")
+			if _, err := runtime.Reload(); err != nil {
+				slog.Error("recarga da configuração descartada", "error", err)
+			}
+		}
+	}
+}
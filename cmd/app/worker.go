@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	portServices "github.com/backstagefood/video-processor-worker/internal/domain/interface/services"
+	"github.com/backstagefood/video-processor-worker/internal/repositories"
+	"github.com/backstagefood/video-processor-worker/internal/usecase"
+	"github.com/backstagefood/video-processor-worker/pkg/adapter"
+	databaseconnection "github.com/backstagefood/video-processor-worker/pkg/adapter/postgres"
+	"github.com/backstagefood/video-processor-worker/pkg/config"
+)
+
+// worker é o papel que consome as mensagens e executa os processamentos, junto das rotinas de limpeza e
+// de recuperação dos processamentos travados. Executa nos modos run-worker e all
+type worker struct {
+	processor portServices.JobProcessor
+	source    adapters.JobSource
+	done      chan struct{}
+}
+
+// startWorker inicia o consumo das mensagens e as rotinas em segundo plano, que param quando intakeCtx é
+// cancelado. Os componentes acompanham as recargas da configuração
+func startWorker(
+	intakeCtx context.Context,
+	runtime *config.Runtime,
+	connectionManager adapter.ConnectionManager,
+	jobRegistry portServices.JobRegistry,
+	metrics adapters.Metrics,
+) *worker {
+	settings := runtime.Current()
+	usersRepository := repositories.NewUsersRepository(connectionManager.GetDBConn())
+	filesRepository := repositories.NewFilesRepository(connectionManager.GetDBConn())
+	bucketRepository := repositories.NewBucketRepository(connectionManager.GetBucketConn())
+	payloadDecoder := usecase.NewPayloadDecoder()
+	for _, format := range connectionManager.GetPayloadFormats() {
+		payloadDecoder.RegisterFormat(format)
+	}
+	jobProcessor := usecase.NewJobProcessor(
+		usersRepository,
+		filesRepository,
+		bucketRepository,
+		usecase.NewVideoValidator(settings.Video),
+		usecase.NewQuotaService(repositories.NewUserPlansRepository(connectionManager.GetDBConn()), filesRepository, usersRepository, settings.Quota),
+		jobRegistry,
+		connectionManager.GetMessageProducer(),
+		usecase.NewJobRouter(connectionManager.GetDeadLetterProducer(), settings.Worker.DefaultJobType),
+		payloadDecoder,
+		metrics,
+		settings.Worker,
+		settings.Extraction,
+		settings.Email,
+		settings.Features,
+	)
+
+	w := &worker{processor: jobProcessor, source: connectionManager.GetJobSource(), done: make(chan struct{})}
+	go func() {
+		defer close(w.done)
+		if err := jobProcessor.Run(intakeCtx, w.source); err != nil {
+			slog.Error("erro ao receber os processamentos", slog.String("error", err.Error()))
+		}
+	}()
+
+	retentionJanitor := usecase.NewRetentionJanitor(
+		filesRepository,
+		bucketRepository,
+		databaseconnection.NewAdvisoryLock(connectionManager.GetDBConn()),
+		metrics,
+		settings.Retention,
+	)
+	go retentionJanitor.Run(intakeCtx)
+
+	stuckJobReaper := usecase.NewStuckJobReaper(
+		filesRepository,
+		connectionManager.GetMessageProducer(),
+		databaseconnection.NewAdvisoryLock(connectionManager.GetDBConn()),
+		settings.Reaper,
+	)
+	go stuckJobReaper.Run(intakeCtx)
+
+	runtime.Subscribe(func(settings *config.Config) {
+		jobProcessor.ApplySettings(settings.Worker, settings.Extraction, settings.Features)
+		stuckJobReaper.ApplySettings(settings.Reaper)
+	})
+	return w
+}
+
+// shutdown aguarda o fim do recebimento, já interrompido pelo cancelamento do intakeCtx, e os processamentos
+// em execução até o fim do prazo; os que não terminarem no prazo voltam para a fila. O source para de receber
+// antes da espera, assim o kafka repassa as partições às outras réplicas sem aguardar o prazo. A conexão só é
+// fechada depois dela, pois no amqp e no nats as entregas são confirmadas quando o processamento termina ou
+// volta para a fila, e fechá-la antes as entregaria novamente às outras réplicas
+func (w *worker) shutdown(gracePeriod time.Duration) {
+	<-w.done
+	if err := w.source.Stop(); err != nil {
+		slog.Error("erro ao encerrar o recebimento de mensagens", "err", err)
+	}
+	slog.Info("recebimento de mensagens encerrado")
+
+	graceCtx, cancelGrace := context.WithTimeout(context.Background(), gracePeriod)
+	defer cancelGrace()
+	if err := w.processor.Shutdown(graceCtx); err != nil {
+		slog.Error("erro ao finalizar os processamentos", "err", err)
+	}
+	if err := w.source.Close(); err != nil {
+		slog.Error("erro ao fechar o recebimento de mensagens", "err", err)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	portServices "github.com/backstagefood/video-processor-worker/internal/domain/interface/services"
+)
+
+// recordingSource registra as chamadas do desligamento. As entregas só podem ser confirmadas enquanto a
+// conexão está aberta, como no amqp e no nats
+type recordingSource struct {
+	adapters.JobSource
+	calls []string
+}
+
+func (s *recordingSource) Stop() error {
+	s.calls = append(s.calls, "stop")
+	return nil
+}
+
+func (s *recordingSource) Close() error {
+	s.calls = append(s.calls, "close")
+	return nil
+}
+
+type recordingDelivery struct {
+	adapters.DeferredDelivery
+	source *recordingSource
+}
+
+func (d *recordingDelivery) Ack() error {
+	if slices.Contains(d.source.calls, "close") {
+		return errors.New("conexão fechada")
+	}
+	d.source.calls = append(d.source.calls, "ack")
+	return nil
+}
+
+// settlingProcessor confirma as entregas em andamento ao terminar, como o processador ao fim dos processamentos
+type settlingProcessor struct {
+	portServices.JobProcessor
+	inFlight []adapters.DeferredDelivery
+	errs     []error
+}
+
+func (p *settlingProcessor) Shutdown(context.Context) error {
+	for _, delivery := range p.inFlight {
+		p.errs = append(p.errs, delivery.Ack())
+	}
+	return nil
+}
+
+func TestWorkerShutdownClosesSourceAfterSettlingDeliveries(t *testing.T) {
+	source := &recordingSource{}
+	processor := &settlingProcessor{inFlight: []adapters.DeferredDelivery{
+		&recordingDelivery{source: source},
+		&recordingDelivery{source: source},
+	}}
+	done := make(chan struct{})
+	close(done)
+	w := &worker{processor: processor, source: source, done: done}
+
+	w.shutdown(time.Second)
+
+	if expected := []string{"stop", "ack", "ack", "close"}; !slices.Equal(source.calls, expected) {
+		t.Errorf("Expected calls %v, got %v", expected, source.calls)
+	}
+	for _, err := range processor.errs {
+		if err != nil {
+			t.Errorf("Expected the in-flight deliveries to be acked, got %v", err)
+		}
+	}
+}
@@ -23,33 +23,598 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/health": {
+        "/admin/accounts/erasure": {
+            "post": {
+                "description": "Start an asynchronous job that deletes every object and file of the user and anonymises the account",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Erase account data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "User email",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "email": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "account request",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "id": {
+                                    "type": "string"
+                                },
+                                "kind": {
+                                    "type": "string"
+                                },
+                                "status": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "generic error response",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/accounts/export": {
+            "post": {
+                "description": "Start an asynchronous job that builds a JSON bundle with the user data and links to the user objects",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Export account data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "User email",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "email": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "account request",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "id": {
+                                    "type": "string"
+                                },
+                                "kind": {
+                                    "type": "string"
+                                },
+                                "status": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "generic error response",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/accounts/requests/{id}": {
+            "get": {
+                "description": "Get the progress of an account export or erasure request",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Account request progress",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Request id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "account request",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error_message": {
+                                    "type": "string"
+                                },
+                                "export_url": {
+                                    "type": "string"
+                                },
+                                "id": {
+                                    "type": "string"
+                                },
+                                "kind": {
+                                    "type": "string"
+                                },
+                                "objects_processed": {
+                                    "type": "integer"
+                                },
+                                "rows_processed": {
+                                    "type": "integer"
+                                },
+                                "status": {
+                                    "type": "string"
+                                },
+                                "user_email": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid id",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "request not found",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "generic error response",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/config": {
+            "patch": {
+                "description": "Override reloadable settings by configuration key (e.g. worker.max_videos) until restart. A null or empty value removes the override",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Update reloadable settings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Settings by key, e.g. {\\",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "applied changes",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "changes": {
+                                    "type": "array",
+                                    "items": {
+                                        "type": "object",
+                                        "properties": {
+                                            "current": {
+                                                "type": "string"
+                                            },
+                                            "key": {
+                                                "type": "string"
+                                            },
+                                            "previous": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "unknown, non reloadable or invalid settings",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/config/reload": {
+            "post": {
+                "description": "Read the configuration file, environment and flags again and apply the reloadable settings. Changes to other settings are ignored until restart",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Reload configuration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "applied changes",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "changes": {
+                                    "type": "array",
+                                    "items": {
+                                        "type": "object",
+                                        "properties": {
+                                            "current": {
+                                                "type": "string"
+                                            },
+                                            "key": {
+                                                "type": "string"
+                                            },
+                                            "previous": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid configuration, the current configuration is kept",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/health/live": {
+            "get": {
+                "description": "Check that the application process is responding, without checking its dependencies",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Application liveness",
+                "responses": {
+                    "200": {
+                        "description": "liveness response",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HealthReport"
+                        }
+                    }
+                }
+            }
+        },
+        "/health/ready": {
+            "get": {
+                "description": "Check the application dependencies. Reports DOWN when a dependency is unavailable or during shutdown",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Application readiness",
+                "responses": {
+                    "200": {
+                        "description": "application ready",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HealthReport"
+                        }
+                    },
+                    "503": {
+                        "description": "application not ready",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HealthReport"
+                        }
+                    }
+                }
+            }
+        },
+        "/info": {
+            "get": {
+                "description": "Check the application info(name and version)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "info"
+                ],
+                "summary": "Application info",
+                "responses": {
+                    "200": {
+                        "description": "info response",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "name": {
+                                    "type": "string"
+                                },
+                                "version": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "generic error response",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/download/{filename}": {
             "get": {
-                "description": "Check the application health",
-                "consumes": [
-                    "application/json"
+                "description": "Download zip file with screenshots of the video",
+                "produces": [
+                    "application/zip"
+                ],
+                "tags": [
+                    "download"
+                ],
+                "summary": "Download zip file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filename",
+                        "name": "filename",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
+                "responses": {
+                    "200": {
+                        "description": "ZIP file",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "500": {
+                        "description": "generic error response",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/{id}": {
+            "delete": {
+                "description": "Delete the zip file and the source video from the bucket and remove the file from the listing",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "health"
+                    "files"
+                ],
+                "summary": "Delete a file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "File id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Keep the source video in the bucket",
+                        "name": "keep_video",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Application health",
                 "responses": {
-                    "200": {
-                        "description": "health response",
+                    "204": {
+                        "description": "file deleted"
+                    },
+                    "400": {
+                        "description": "invalid request",
                         "schema": {
                             "type": "object",
                             "properties": {
-                                "status": {
+                                "error": {
                                     "type": "string"
                                 }
                             }
                         }
                     },
                     "404": {
-                        "description": "not found error response",
+                        "description": "file not found",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "file still in progress",
                         "schema": {
                             "type": "object",
                             "properties": {
@@ -73,9 +638,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/info": {
-            "get": {
-                "description": "Check the application info(name and version)",
+        "/v1/files/{id}/reprocess": {
+            "post": {
+                "description": "Reprocess a stored video with optional new extraction options, creating a new version of the file",
                 "consumes": [
                     "application/json"
                 ],
@@ -83,19 +648,116 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "info"
+                    "files"
+                ],
+                "summary": "Reprocess a file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "File id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reprocess options",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "extraction_options": {
+                                    "type": "object",
+                                    "properties": {
+                                        "fps": {
+                                            "type": "number"
+                                        },
+                                        "image_quality": {
+                                            "type": "integer"
+                                        }
+                                    }
+                                },
+                                "replace_previous": {
+                                    "type": "boolean"
+                                }
+                            }
+                        }
+                    }
                 ],
-                "summary": "Application info",
                 "responses": {
-                    "200": {
-                        "description": "info response",
+                    "202": {
+                        "description": "new file version",
                         "schema": {
                             "type": "object",
                             "properties": {
-                                "name": {
+                                "id": {
+                                    "type": "string"
+                                },
+                                "parent_file_id": {
                                     "type": "string"
                                 },
                                 "version": {
+                                    "type": "integer"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "file not found",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "file still in progress",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "410": {
+                        "description": "video expired",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "quota exceeded",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "string"
+                                },
+                                "error": {
+                                    "type": "string"
+                                },
+                                "message": {
                                     "type": "string"
                                 }
                             }
@@ -115,30 +777,71 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/download/{filename}": {
-            "get": {
-                "description": "Download zip file with screenshots of the video",
+        "/v1/jobs/{id}/cancel": {
+            "post": {
+                "description": "Cancel a queued or running video processing job",
                 "produces": [
-                    "application/zip"
+                    "application/json"
                 ],
                 "tags": [
-                    "download"
+                    "jobs"
                 ],
-                "summary": "Download zip file",
+                "summary": "Cancel a job",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Filename",
-                        "name": "filename",
+                        "description": "File id",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "ZIP file",
+                    "202": {
+                        "description": "cancelled job",
                         "schema": {
-                            "type": "file"
+                            "type": "object",
+                            "properties": {
+                                "id": {
+                                    "type": "string"
+                                },
+                                "status": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid id",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "file not found",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "job already finished",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
                         }
                     },
                     "500": {
@@ -182,6 +885,12 @@ const docTemplate = `{
                                             "filename": {
                                                 "type": "string"
                                             },
+                                            "id": {
+                                                "type": "string"
+                                            },
+                                            "parentFileId": {
+                                                "type": "string"
+                                            },
                                             "processingResult": {
                                                 "type": "object"
                                             },
@@ -190,6 +899,9 @@ const docTemplate = `{
                                             },
                                             "statusId": {
                                                 "type": "integer"
+                                            },
+                                            "version": {
+                                                "type": "integer"
                                             }
                                         }
                                     }
@@ -213,6 +925,126 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "/v1/usage": {
+            "get": {
+                "description": "Get the plan limits and the current usage of the user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "usage"
+                ],
+                "summary": "Quota usage",
+                "responses": {
+                    "200": {
+                        "description": "usage response",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "plan": {
+                                    "type": "object",
+                                    "properties": {
+                                        "max_bytes_per_month": {
+                                            "type": "integer"
+                                        },
+                                        "max_concurrent_jobs": {
+                                            "type": "integer"
+                                        },
+                                        "max_jobs_per_day": {
+                                            "type": "integer"
+                                        },
+                                        "max_stored_bytes": {
+                                            "type": "integer"
+                                        },
+                                        "plan_name": {
+                                            "type": "string"
+                                        },
+                                        "priority_class": {
+                                            "type": "string"
+                                        }
+                                    }
+                                },
+                                "usage": {
+                                    "type": "object",
+                                    "properties": {
+                                        "bytes_this_month": {
+                                            "type": "integer"
+                                        },
+                                        "concurrent_jobs": {
+                                            "type": "integer"
+                                        },
+                                        "jobs_today": {
+                                            "type": "integer"
+                                        },
+                                        "stored_bytes": {
+                                            "type": "integer"
+                                        }
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "generic error response",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.ComponentHealth": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.HealthReport": {
+            "type": "object",
+            "properties": {
+                "components": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/domain.ComponentHealth"
+                    }
+                },
+                "draining": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
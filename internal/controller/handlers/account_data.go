@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	portServices "github.com/backstagefood/video-processor-worker/internal/domain/interface/services"
+	"github.com/backstagefood/video-processor-worker/internal/repositories"
+	"github.com/backstagefood/video-processor-worker/internal/usecase"
+	"github.com/backstagefood/video-processor-worker/pkg/adapter/bucketconfig"
+	databaseconnection "github.com/backstagefood/video-processor-worker/pkg/adapter/postgres"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AccountDataHandler struct {
+	accountDataService portServices.AccountDataService
+}
+
+type accountDataRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+func NewAccountDataHandler(
+	dbClient *databaseconnection.ApplicationDatabase,
+	s3Conn *bucketconfig.ApplicationS3Bucket,
+	jobRegistry portServices.JobRegistry,
+	backgroundTasks portServices.BackgroundTasks,
+	exportLinkExpiration time.Duration,
+	cancellationPollInterval time.Duration,
+) *AccountDataHandler {
+	return &AccountDataHandler{
+		accountDataService: usecase.NewAccountDataService(
+			repositories.NewAccountRequestsRepository(dbClient),
+			repositories.NewUsersRepository(dbClient),
+			repositories.NewFilesRepository(dbClient),
+			repositories.NewBucketRepository(s3Conn),
+			jobRegistry,
+			backgroundTasks,
+			exportLinkExpiration,
+			cancellationPollInterval,
+		),
+	}
+}
+
+// @BasePath /admin/accounts/erasure
+// PingExample godoc
+// @Summary Erase account data
+// @Schemes
+// @Description Start an asynchronous job that deletes every object and file of the user and anonymises the account
+// @Tags admin
+// @Accept json
+// @Produce application/json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param request body object{email=string} true "User email"
+// @Success 202 {object} object{id=string,kind=string,status=string} "account request"
+// @Failure 400 {object} object{error=string} "invalid request"
+// @Failure 404 {object} object{error=string} "user not found"
+// @Failure 500 {object} object{error=string} "generic error response"
+// @Router /admin/accounts/erasure [post]
+func (h *AccountDataHandler) HandleErasure(c *gin.Context) {
+	h.handleRequest(c, h.accountDataService.RequestErasure)
+}
+
+// @BasePath /admin/accounts/export
+// PingExample godoc
+// @Summary Export account data
+// @Schemes
+// @Description Start an asynchronous job that builds a JSON bundle with the user data and links to the user objects
+// @Tags admin
+// @Accept json
+// @Produce application/json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param request body object{email=string} true "User email"
+// @Success 202 {object} object{id=string,kind=string,status=string} "account request"
+// @Failure 400 {object} object{error=string} "invalid request"
+// @Failure 404 {object} object{error=string} "user not found"
+// @Failure 500 {object} object{error=string} "generic error response"
+// @Router /admin/accounts/export [post]
+func (h *AccountDataHandler) HandleExport(c *gin.Context) {
+	h.handleRequest(c, h.accountDataService.RequestExport)
+}
+
+func (h *AccountDataHandler) handleRequest(c *gin.Context, start func(ctx context.Context, userEmail string) (*domain.AccountRequest, error)) {
+	var body accountDataRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, gin.H{"error": "O campo email é obrigatório"})
+		return
+	}
+
+	request, err := start(c, body.Email)
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		c.JSON(404, gin.H{"error": "Usuário não encontrado"})
+		return
+	case err != nil:
+		slog.Error("não foi possível iniciar a solicitação de dados da conta", "error", err)
+		c.JSON(500, gin.H{"error": "Erro ao iniciar a solicitação"})
+		return
+	}
+
+	c.JSON(202, gin.H{
+		"id":     request.ID,
+		"kind":   request.Kind,
+		"status": request.Status,
+	})
+}
+
+// @BasePath /admin/accounts/requests/:id
+// PingExample godoc
+// @Summary Account request progress
+// @Schemes
+// @Description Get the progress of an account export or erasure request
+// @Tags admin
+// @Produce application/json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param id path string true "Request id"
+// @Success 200 {object} object{id=string,user_email=string,kind=string,status=string,objects_processed=integer,rows_processed=integer,export_url=string,error_message=string} "account request"
+// @Failure 400 {object} object{error=string} "invalid id"
+// @Failure 404 {object} object{error=string} "request not found"
+// @Failure 500 {object} object{error=string} "generic error response"
+// @Router /admin/accounts/requests/{id} [get]
+func (h *AccountDataHandler) HandleGetRequest(c *gin.Context) {
+	requestId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Identificador inválido"})
+		return
+	}
+
+	request, err := h.accountDataService.GetRequest(c, requestId)
+	switch {
+	case errors.Is(err, domain.ErrAccountRequestNotFound):
+		c.JSON(404, gin.H{"error": "Solicitação não encontrada"})
+		return
+	case err != nil:
+		slog.Error("não foi possível obter a solicitação", "requestId", requestId, "error", err)
+		c.JSON(500, gin.H{"error": "Erro ao obter a solicitação"})
+		return
+	}
+
+	c.JSON(200, request)
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"log/slog"
+
+	"github.com/backstagefood/video-processor-worker/pkg/config"
+	"github.com/gin-gonic/gin"
+)
+
+type ConfigHandler struct {
+	runtime *config.Runtime
+}
+
+func NewConfigHandler(runtime *config.Runtime) *ConfigHandler {
+	return &ConfigHandler{runtime: runtime}
+}
+
+// @BasePath /admin/config/reload
+// PingExample godoc
+// @Summary Reload configuration
+// @Schemes
+// @Description Read the configuration file, environment and flags again and apply the reloadable settings. Changes to other settings are ignored until restart
+// @Tags admin
+// @Produce application/json
+// @Param X-Admin-Token header string true "Admin token"
+// @Success 200 {object} object{changes=[]object{key=string,previous=string,current=string}} "applied changes"
+// @Failure 400 {object} object{error=string} "invalid configuration, the current configuration is kept"
+// @Router /admin/config/reload [post]
+func (h *ConfigHandler) HandleReload(c *gin.Context) {
+	changes, err := h.runtime.Reload()
+	if err != nil {
+		slog.ErrorContext(c, "recarga da configuração descartada", "error", err)
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"changes": emptyIfNil(changes)})
+}
+
+// @BasePath /admin/config
+// PingExample godoc
+// @Summary Update reloadable settings
+// @Schemes
+// @Description Override reloadable settings by configuration key (e.g. worker.max_videos) until restart. A null or empty value removes the override
+// @Tags admin
+// @Accept json
+// @Produce application/json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param request body object true "Settings by key, e.g. {\"worker.max_videos\": 10, \"log.level\": \"debug\"}"
+// @Success 200 {object} object{changes=[]object{key=string,previous=string,current=string}} "applied changes"
+// @Failure 400 {object} object{error=string} "unknown, non reloadable or invalid settings"
+// @Router /admin/config [patch]
+func (h *ConfigHandler) HandleUpdate(c *gin.Context) {
+	var values map[string]any
+	if err := c.ShouldBindJSON(&values); err != nil || len(values) == 0 {
+		c.JSON(400, gin.H{"error": "Informe ao menos uma configuração"})
+		return
+	}
+	changes, err := h.runtime.Override(values)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"changes": emptyIfNil(changes)})
+}
+
+// emptyIfNil evita que a resposta sem alterações seja serializada como null
+func emptyIfNil(changes []config.Change) []config.Change {
+	if changes == nil {
+		return []config.Change{}
+	}
+	return changes
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"strconv"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	portServices "github.com/backstagefood/video-processor-worker/internal/domain/interface/services"
+	"github.com/backstagefood/video-processor-worker/internal/repositories"
+	"github.com/backstagefood/video-processor-worker/internal/usecase"
+	"github.com/backstagefood/video-processor-worker/pkg/adapter/bucketconfig"
+	databaseconnection "github.com/backstagefood/video-processor-worker/pkg/adapter/postgres"
+	"github.com/backstagefood/video-processor-worker/pkg/config"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type FilesHandler struct {
+	filesService portServices.FilesService
+}
+
+// NewFilesHandler cria o handler com a configuração em uso; as opções de extração padrão acompanham as
+// recargas da configuração
+func NewFilesHandler(dbClient *databaseconnection.ApplicationDatabase, s3Conn *bucketconfig.ApplicationS3Bucket, messageProducer adapters.MessageProducer, runtime *config.Runtime) *FilesHandler {
+	settings := runtime.Current()
+	filesRepository := repositories.NewFilesRepository(dbClient)
+	bucketRepository := repositories.NewBucketRepository(s3Conn)
+	quotaService := usecase.NewQuotaService(repositories.NewUserPlansRepository(dbClient), filesRepository, repositories.NewUsersRepository(dbClient), settings.Quota)
+	filesService := usecase.NewFilesService(filesRepository, bucketRepository, messageProducer, quotaService, settings.Extraction)
+	runtime.Subscribe(func(settings *config.Config) {
+		filesService.ApplySettings(settings.Extraction)
+	})
+	return &FilesHandler{filesService: filesService}
+}
+
+// @BasePath /v1/files/:id/reprocess
+// PingExample godoc
+// @Summary Reprocess a file
+// @Schemes
+// @Description Reprocess a stored video with optional new extraction options, creating a new version of the file
+// @Tags files
+// @Accept json
+// @Produce application/json
+// @Param id path string true "File id"
+// @Param request body object{extraction_options=object{fps=number,image_quality=integer},replace_previous=boolean} false "Reprocess options"
+// @Success 202 {object} object{id=string,parent_file_id=string,version=integer} "new file version"
+// @Failure 400 {object} object{error=string} "invalid request"
+// @Failure 404 {object} object{error=string} "file not found"
+// @Failure 409 {object} object{error=string} "file still in progress"
+// @Failure 410 {object} object{error=string} "video expired"
+// @Failure 429 {object} object{error=string,code=string,message=string} "quota exceeded"
+// @Failure 500 {object} object{error=string} "generic error response"
+// @Router /v1/files/{id}/reprocess [post]
+func (h *FilesHandler) HandleReprocess(c *gin.Context) {
+	userEmail := c.MustGet("user_email").(string)
+	slog.Info("obtem userEmail em handleReprocess", "userEmail", userEmail)
+
+	fileId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Identificador inválido"})
+		return
+	}
+
+	var request domain.ReprocessRequest
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(400, gin.H{"error": "Requisição inválida"})
+		return
+	}
+
+	file, err := h.filesService.ReprocessFile(c, userEmail, fileId, request)
+	var quotaExceeded *domain.QuotaExceeded
+	switch {
+	case errors.As(err, &quotaExceeded):
+		c.JSON(429, gin.H{"error": "Cota excedida", "code": quotaExceeded.Code, "message": quotaExceeded.Message})
+		return
+	case errors.Is(err, domain.ErrFileNotFound):
+		c.JSON(404, gin.H{"error": "Arquivo não encontrado"})
+		return
+	case errors.Is(err, domain.ErrFileInProgress):
+		c.JSON(409, gin.H{"error": "O arquivo ainda está em processamento"})
+		return
+	case errors.Is(err, domain.ErrInvalidExtractionOptions):
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, domain.ErrVideoExpired):
+		c.JSON(410, gin.H{"error": "O video do arquivo expirou e foi removido"})
+		return
+	case err != nil:
+		slog.Error("não foi possível reprocessar o arquivo", "fileId", fileId, "error", err)
+		c.JSON(500, gin.H{"error": "Erro ao reprocessar o arquivo"})
+		return
+	}
+
+	c.JSON(202, gin.H{
+		"id":             file.ID,
+		"parent_file_id": file.ParentFileID,
+		"version":        file.Version,
+	})
+}
+
+// @BasePath /v1/files/:id
+// PingExample godoc
+// @Summary Delete a file
+// @Schemes
+// @Description Delete the zip file and the source video from the bucket and remove the file from the listing
+// @Tags files
+// @Produce application/json
+// @Param id path string true "File id"
+// @Param keep_video query boolean false "Keep the source video in the bucket"
+// @Success 204 "file deleted"
+// @Failure 400 {object} object{error=string} "invalid request"
+// @Failure 404 {object} object{error=string} "file not found"
+// @Failure 409 {object} object{error=string} "file still in progress"
+// @Failure 500 {object} object{error=string} "generic error response"
+// @Router /v1/files/{id} [delete]
+func (h *FilesHandler) HandleDelete(c *gin.Context) {
+	userEmail := c.MustGet("user_email").(string)
+	slog.Info("obtem userEmail em handleDelete", "userEmail", userEmail)
+
+	fileId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Identificador inválido"})
+		return
+	}
+	keepVideo, err := strconv.ParseBool(c.DefaultQuery("keep_video", "false"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Parâmetro keep_video inválido"})
+		return
+	}
+
+	err = h.filesService.DeleteFile(c, userEmail, fileId, keepVideo)
+	switch {
+	case errors.Is(err, domain.ErrFileNotFound):
+		c.JSON(404, gin.H{"error": "Arquivo não encontrado"})
+		return
+	case errors.Is(err, domain.ErrFileInProgress):
+		c.JSON(409, gin.H{"error": "O arquivo ainda está em processamento"})
+		return
+	case err != nil:
+		slog.Error("não foi possível remover o arquivo", "fileId", fileId, "error", err)
+		c.JSON(500, gin.H{"error": "Erro ao remover o arquivo"})
+		return
+	}
+
+	c.Status(204)
+}
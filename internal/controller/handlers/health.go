@@ -1,23 +1,51 @@
 package handlers
 
 import (
+	"net/http"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	portServices "github.com/backstagefood/video-processor-worker/internal/domain/interface/services"
 	"github.com/gin-gonic/gin"
 )
 
-// @BasePath /health
+type HealthHandler struct {
+	healthService portServices.HealthService
+}
+
+func NewHealthHandler(healthService portServices.HealthService) *HealthHandler {
+	return &HealthHandler{healthService: healthService}
+}
+
+// @BasePath /health/live
+// PingExample godoc
+// @Summary Application liveness
+// @Schemes
+// @Description Check that the application process is responding, without checking its dependencies
+// @Tags health
+// @Accept json
+// @Produce json
+// @Success 200 {object} domain.HealthReport "liveness response"
+// @Router /health/live [get]
+func (h *HealthHandler) HandleLive(c *gin.Context) {
+	c.JSON(http.StatusOK, h.healthService.Liveness())
+}
+
+// @BasePath /health/ready
 // PingExample godoc
-// @Summary Application health
+// @Summary Application readiness
 // @Schemes
-// @Description Check the application health
+// @Description Check the application dependencies. Reports DOWN when a dependency is unavailable or during shutdown
 // @Tags health
 // @Accept json
 // @Produce json
-// @Success 200 {object} object{status=string} "health response"
-// @Failure 404 {object} object{error=string} "not found error response"
-// @Failure 500 {object} object{error=string} "generic error response"
-// @Router /health [get]
-func HandleHealth(c *gin.Context) {
-	c.JSON(200, gin.H{
-		"status": "UP",
-	})
+// @Success 200 {object} domain.HealthReport "application ready"
+// @Failure 503 {object} domain.HealthReport "application not ready"
+// @Router /health/ready [get]
+func (h *HealthHandler) HandleReady(c *gin.Context) {
+	report := h.healthService.Readiness(c)
+	status := http.StatusOK
+	if report.Status != domain.HealthUp {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
 }
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	portServices "github.com/backstagefood/video-processor-worker/internal/domain/interface/services"
+	"github.com/backstagefood/video-processor-worker/internal/repositories"
+	"github.com/backstagefood/video-processor-worker/internal/usecase"
+	databaseconnection "github.com/backstagefood/video-processor-worker/pkg/adapter/postgres"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type JobsHandler struct {
+	jobsService portServices.JobsService
+}
+
+func NewJobsHandler(dbClient *databaseconnection.ApplicationDatabase, jobRegistry portServices.JobRegistry) *JobsHandler {
+	filesRepository := repositories.NewFilesRepository(dbClient)
+	return &JobsHandler{
+		jobsService: usecase.NewJobsService(filesRepository, jobRegistry),
+	}
+}
+
+// @BasePath /v1/jobs/:id/cancel
+// PingExample godoc
+// @Summary Cancel a job
+// @Schemes
+// @Description Cancel a queued or running video processing job
+// @Tags jobs
+// @Produce application/json
+// @Param id path string true "File id"
+// @Success 202 {object} object{id=string,status=string} "cancelled job"
+// @Failure 400 {object} object{error=string} "invalid id"
+// @Failure 404 {object} object{error=string} "file not found"
+// @Failure 409 {object} object{error=string} "job already finished"
+// @Failure 500 {object} object{error=string} "generic error response"
+// @Router /v1/jobs/{id}/cancel [post]
+func (h *JobsHandler) HandleCancel(c *gin.Context) {
+	userEmail := c.MustGet("user_email").(string)
+	slog.Info("obtem userEmail em handleCancel", "userEmail", userEmail)
+
+	fileId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Identificador inválido"})
+		return
+	}
+
+	err = h.jobsService.CancelJob(c, userEmail, fileId)
+	switch {
+	case errors.Is(err, domain.ErrFileNotFound):
+		c.JSON(404, gin.H{"error": "Arquivo não encontrado"})
+		return
+	case errors.Is(err, domain.ErrJobNotCancellable):
+		c.JSON(409, gin.H{"error": "O processamento do arquivo já foi finalizado"})
+		return
+	case err != nil:
+		slog.Error("não foi possível cancelar o processamento", "fileId", fileId, "error", err)
+		c.JSON(500, gin.H{"error": "Erro ao cancelar o processamento"})
+		return
+	}
+
+	c.JSON(202, gin.H{
+		"id":     fileId,
+		"status": "cancelado",
+	})
+}
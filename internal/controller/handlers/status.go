@@ -20,7 +20,7 @@ type StatusHandler struct {
 // @Description List all files
 // @Tags status
 // @Produce application/json
-// @Success 200 {object} object{files=[]object{filename=string,size=number,statusId=integer,processingResult=object,created_at=string},total=integer} "success response"
+// @Success 200 {object} object{files=[]object{id=string,filename=string,size=number,version=integer,parentFileId=string,statusId=integer,processingResult=object,created_at=string},total=integer} "success response"
 // @Failure 500 {object} object{error=string} "generic error response"
 // @Router /v1/status [get]
 func NewStatusHandler(dbClient *databaseconnection.ApplicationDatabase) *StatusHandler {
@@ -34,7 +34,7 @@ func (f *StatusHandler) HandleStatus(c *gin.Context) {
 	userEmail := c.MustGet("user_email").(string)
 	slog.Info("obtem userEmail em handleStatus", "userEmail", userEmail)
 
-	files, err := f.filesStatusService.ListFilesByEmail(userEmail)
+	files, err := f.filesStatusService.ListFilesByEmail(c, userEmail)
 	if err != nil {
 		slog.Info("não foi possível obter a lista de arquivos", "error", err)
 		c.JSON(500, gin.H{"error": "Erro ao listar arquivos"})
@@ -43,8 +43,11 @@ func (f *StatusHandler) HandleStatus(c *gin.Context) {
 	var results []map[string]interface{}
 	for _, file := range files {
 		results = append(results, map[string]interface{}{
+			"id":               file.ID,
 			"filename":         file.GetZipFileName(),
 			"size":             file.ZipFileSize,
+			"version":          file.Version,
+			"parentFileId":     file.ParentFileID,
 			"statusId":         file.FileStatus.ID,
 			"status":           file.FileStatus.Status,
 			"processingResult": file.ProcessingResult,
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+
+	portServices "github.com/backstagefood/video-processor-worker/internal/domain/interface/services"
+	"github.com/backstagefood/video-processor-worker/internal/repositories"
+	"github.com/backstagefood/video-processor-worker/internal/usecase"
+	databaseconnection "github.com/backstagefood/video-processor-worker/pkg/adapter/postgres"
+	"github.com/backstagefood/video-processor-worker/pkg/config"
+	"github.com/gin-gonic/gin"
+)
+
+type UsageHandler struct {
+	quotaService portServices.QuotaService
+}
+
+func NewUsageHandler(dbClient *databaseconnection.ApplicationDatabase, quotaDefaults config.QuotaConfig) *UsageHandler {
+	return &UsageHandler{
+		quotaService: usecase.NewQuotaService(
+			repositories.NewUserPlansRepository(dbClient),
+			repositories.NewFilesRepository(dbClient),
+			repositories.NewUsersRepository(dbClient),
+			quotaDefaults,
+		),
+	}
+}
+
+// @BasePath /v1/usage
+// PingExample godoc
+// @Summary Quota usage
+// @Schemes
+// @Description Get the plan limits and the current usage of the user
+// @Tags usage
+// @Produce application/json
+// @Success 200 {object} object{plan=object{plan_name=string,max_concurrent_jobs=integer,max_jobs_per_day=integer,max_bytes_per_month=integer,max_stored_bytes=integer,priority_class=string},usage=object{concurrent_jobs=integer,jobs_today=integer,bytes_this_month=integer,stored_bytes=integer}} "usage response"
+// @Failure 404 {object} object{error=string} "user not found"
+// @Failure 500 {object} object{error=string} "generic error response"
+// @Router /v1/usage [get]
+func (h *UsageHandler) HandleUsage(c *gin.Context) {
+	userEmail := c.MustGet("user_email").(string)
+	slog.Info("obtem userEmail em handleUsage", "userEmail", userEmail)
+
+	plan, usage, err := h.quotaService.GetUsage(c, userEmail)
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		c.JSON(404, gin.H{"error": "Usuário não encontrado"})
+		return
+	case err != nil:
+		slog.Error("não foi possível obter o consumo do usuário", "error", err)
+		c.JSON(500, gin.H{"error": "Erro ao obter o consumo"})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"plan":  plan,
+		"usage": usage,
+	})
+}
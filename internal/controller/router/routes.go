@@ -1,29 +1,43 @@
 package routes
 
 import (
-	"context"
+	"crypto/subtle"
 	"fmt"
-	"log/slog"
 	"net/http"
+	"strings"
+	"time"
 
 	docs "github.com/backstagefood/video-processor-worker/docs/http"
 	"github.com/backstagefood/video-processor-worker/internal/controller/handlers"
-	"github.com/backstagefood/video-processor-worker/internal/usecase"
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	portServices "github.com/backstagefood/video-processor-worker/internal/domain/interface/services"
 	"github.com/backstagefood/video-processor-worker/pkg/adapter"
-	"github.com/backstagefood/video-processor-worker/utils"
+	"github.com/backstagefood/video-processor-worker/pkg/config"
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-func NewRouter(connectionManager adapter.ConnectionManager) *gin.Engine {
-	r := gin.Default()
-	initSwagger()
+// tracingServiceName identifica o servidor HTTP nos spans das requisições
+const tracingServiceName = "video-processor-worker"
+
+func NewRouter(
+	connectionManager adapter.ConnectionManager,
+	jobRegistry portServices.JobRegistry,
+	backgroundTasks portServices.BackgroundTasks,
+	metrics adapters.Metrics,
+	healthService portServices.HealthService,
+	runtime *config.Runtime,
+) *gin.Engine {
+	settings := runtime.Current()
+	r := newEngine(metrics)
+	initSwagger(settings.Server.SwaggerHost)
 
 	r.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS")
+		c.Header("Access-Control-Allow-Methods", "POST, GET, PATCH, DELETE, OPTIONS")
 		c.Header("Access-Control-Allow-Headers", "Content-Type")
 
 		if c.Request.Method == "OPTIONS" {
@@ -34,15 +48,6 @@ func NewRouter(connectionManager adapter.ConnectionManager) *gin.Engine {
 		c.Next()
 	})
 
-	videoConsumer := usecase.NewFileConsumer(connectionManager.GetBucketConn(), connectionManager.GetDBConn())
-
-	go func() {
-		err := connectionManager.GetMessageConsumer().ConsumeMessages(context.Background(), videoConsumer)
-		if err != nil {
-			slog.Error("error consuming kafka messages", slog.String("error", err.Error()))
-		}
-	}()
-
 	r.GET("/", func(c *gin.Context) {
 		c.Redirect(http.StatusMovedPermanently, "/swagger/index.html")
 	})
@@ -57,17 +62,43 @@ func NewRouter(connectionManager adapter.ConnectionManager) *gin.Engine {
 
 		statusHandler := handlers.NewStatusHandler(connectionManager.GetDBConn())
 		apiGroup.GET("/status", statusHandler.HandleStatus)
+
+		jobsHandler := handlers.NewJobsHandler(connectionManager.GetDBConn(), jobRegistry)
+		apiGroup.POST("/jobs/:id/cancel", jobsHandler.HandleCancel)
+
+		filesHandler := handlers.NewFilesHandler(connectionManager.GetDBConn(), connectionManager.GetBucketConn(), connectionManager.GetMessageProducer(), runtime)
+		apiGroup.POST("/files/:id/reprocess", filesHandler.HandleReprocess)
+		apiGroup.DELETE("/files/:id", filesHandler.HandleDelete)
+
+		usageHandler := handlers.NewUsageHandler(connectionManager.GetDBConn(), settings.Quota)
+		apiGroup.GET("/usage", usageHandler.HandleUsage)
+	}
+
+	// Grupo de rotas /admin, protegido pelo token administrativo
+	adminGroup := r.Group("/admin")
+	adminGroup.Use(adminAuthMiddleware(settings.Admin.Token))
+	{
+		accountDataHandler := handlers.NewAccountDataHandler(
+			connectionManager.GetDBConn(),
+			connectionManager.GetBucketConn(),
+			jobRegistry,
+			backgroundTasks,
+			settings.Admin.ExportLinkExpiration,
+			settings.Worker.CancellationPollInterval,
+		)
+		adminGroup.POST("/accounts/erasure", accountDataHandler.HandleErasure)
+		adminGroup.POST("/accounts/export", accountDataHandler.HandleExport)
+		adminGroup.GET("/accounts/requests/:id", accountDataHandler.HandleGetRequest)
+
+		configHandler := handlers.NewConfigHandler(runtime)
+		adminGroup.POST("/config/reload", configHandler.HandleReload)
+		adminGroup.PATCH("/config", configHandler.HandleUpdate)
 	}
 
 	// outros
-	r.GET("/info", handlers.HandleInfo)
-	r.GET("/health", handlers.HandleHealth)
+	registerOpsRoutes(r, healthService)
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 
-	r.GET("/metrics", func(c *gin.Context) {
-		promhttp.Handler().ServeHTTP(c.Writer, c.Request)
-	})
-
 	// logger
 	r.Use(gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
 		return fmt.Sprintf("%s %s %d | %10s | %15s | %-7s \"%s\"\n",
@@ -84,6 +115,51 @@ func NewRouter(connectionManager adapter.ConnectionManager) *gin.Engine {
 	return r
 }
 
+// NewOpsRouter atende apenas a saúde, as métricas e a versão, usado no modo run-worker, que não expõe a API
+func NewOpsRouter(metrics adapters.Metrics, healthService portServices.HealthService) *gin.Engine {
+	r := newEngine(metrics)
+	registerOpsRoutes(r, healthService)
+	return r
+}
+
+func newEngine(metrics adapters.Metrics) *gin.Engine {
+	r := gin.Default()
+	// os handlers repassam o gin.Context aos casos de uso, que assim recebem o span da requisição
+	r.ContextWithFallback = true
+	r.Use(otelgin.Middleware(tracingServiceName, otelgin.WithFilter(func(request *http.Request) bool {
+		return request.URL.Path != "/metrics" && !strings.HasPrefix(request.URL.Path, "/health")
+	})))
+	r.Use(requestMetricsMiddleware(metrics))
+	return r
+}
+
+func registerOpsRoutes(r *gin.Engine, healthService portServices.HealthService) {
+	r.GET("/info", handlers.HandleInfo)
+	healthHandler := handlers.NewHealthHandler(healthService)
+	// /health é mantido para as sondas existentes e equivale à vivacidade
+	r.GET("/health", healthHandler.HandleLive)
+	r.GET("/health/live", healthHandler.HandleLive)
+	r.GET("/health/ready", healthHandler.HandleReady)
+
+	r.GET("/metrics", func(c *gin.Context) {
+		promhttp.Handler().ServeHTTP(c.Writer, c.Request)
+	})
+}
+
+// requestMetricsMiddleware registra a latência das requisições pela rota, e não pelo caminho, mantendo
+// uma série por endpoint
+func requestMetricsMiddleware(metrics adapters.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.HTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
+
 // Middleware para rotas /api/*
 func apiAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -99,8 +175,27 @@ func apiAuthMiddleware() gin.HandlerFunc {
 	}
 }
 
-func initSwagger() {
+// Middleware para rotas /admin/*
+func adminAuthMiddleware(adminToken string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if adminToken == "" {
+			c.AbortWithStatusJSON(403, gin.H{
+				"error": "Rotas administrativas desabilitadas",
+			})
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader("X-Admin-Token")), []byte(adminToken)) != 1 {
+			c.AbortWithStatusJSON(401, gin.H{
+				"error": "Header X-Admin-Token inválido",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+func initSwagger(host string) {
 	docs.SwaggerInfo.BasePath = "/"
 	docs.SwaggerInfo.Version = handlers.Version
-	docs.SwaggerInfo.Host = utils.GetEnvVarOrDefault("SWAGGER_HOST", "localhost:8080")
+	docs.SwaggerInfo.Host = host
 }
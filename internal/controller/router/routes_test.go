@@ -0,0 +1,57 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	"github.com/backstagefood/video-processor-worker/internal/usecase"
+	"github.com/backstagefood/video-processor-worker/pkg/config"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeMetrics struct {
+	adapters.Metrics
+	routes []string
+}
+
+func (m *fakeMetrics) HTTPRequest(_ string, route string, _ int, _ time.Duration) {
+	m.routes = append(m.routes, route)
+}
+
+func serve(router *gin.Engine, method, path string) int {
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+	return recorder.Code
+}
+
+func TestOpsRouterServesOnlyHealthMetricsAndInfo(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	metrics := &fakeMetrics{}
+	router := NewOpsRouter(metrics, usecase.NewHealthService(nil, config.HealthConfig{CheckTimeout: time.Second}))
+
+	for _, path := range []string{"/health", "/health/live", "/health/ready", "/metrics", "/info"} {
+		if status := serve(router, http.MethodGet, path); status != http.StatusOK {
+			t.Errorf("Expected %s to return 200, got %d", path, status)
+		}
+	}
+
+	// o modo run-worker não expõe a API, a administração nem a documentação
+	notExposed := []struct{ method, path string }{
+		{http.MethodGet, "/v1/status"},
+		{http.MethodPost, "/v1/files/1/reprocess"},
+		{http.MethodPost, "/admin/config/reload"},
+		{http.MethodPatch, "/admin/config"},
+		{http.MethodGet, "/swagger/index.html"},
+	}
+	for _, route := range notExposed {
+		if status := serve(router, route.method, route.path); status != http.StatusNotFound {
+			t.Errorf("Expected %s %s to return 404, got %d", route.method, route.path, status)
+		}
+	}
+	if last := metrics.routes[len(metrics.routes)-1]; last != "unmatched" {
+		t.Errorf("Expected unknown routes to be recorded as unmatched, got %s", last)
+	}
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tipos de solicitação sobre os dados da conta do usuário
+const (
+	AccountRequestErasure = "ERASURE"
+	AccountRequestExport  = "EXPORT"
+)
+
+// Situações da solicitação sobre os dados da conta do usuário
+const (
+	AccountRequestPending = "PENDING"
+	AccountRequestRunning = "RUNNING"
+	AccountRequestDone    = "DONE"
+	AccountRequestFailed  = "FAILED"
+)
+
+// AccountRequest acompanha a execução de uma exportação ou remoção dos dados de um usuário
+type AccountRequest struct {
+	ID               uuid.UUID  `json:"id"`
+	UserEmail        string     `json:"user_email"`
+	Kind             string     `json:"kind"`
+	Status           string     `json:"status"`
+	ObjectsProcessed int64      `json:"objects_processed"`
+	RowsProcessed    int64      `json:"rows_processed"`
+	ExportURL        *string    `json:"export_url,omitempty"`
+	ErrorMessage     *string    `json:"error_message,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        *time.Time `json:"updated_at,omitempty"`
+	FinishedAt       *time.Time `json:"finished_at,omitempty"`
+}
+
+// AccountExport é o conteúdo do pacote de exportação dos dados do usuário
+type AccountExport struct {
+	User        *User            `json:"user"`
+	Files       []*File          `json:"files"`
+	Objects     []ExportedObject `json:"objects"`
+	GeneratedAt time.Time        `json:"generated_at"`
+}
+
+type ExportedObject struct {
+	Key string `json:"key"`
+	URL string `json:"url"`
+}
@@ -0,0 +1,18 @@
+package domain
+
+// CloudEventsSpecVersion é a versão da especificação CloudEvents aceita pelo worker
+const CloudEventsSpecVersion = "1.0"
+
+// CloudEvent contém os atributos de um evento CloudEvents 1.0, recebido no modo estruturado ou binário.
+// Data é o conteúdo do evento já decodificado do base64 quando necessário
+type CloudEvent struct {
+	SpecVersion     string
+	ID              string
+	Source          string
+	Type            string
+	Subject         string
+	Time            string
+	DataContentType string
+	DataSchema      string
+	Data            []byte
+}
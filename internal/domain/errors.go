@@ -0,0 +1,18 @@
+package domain
+
+import "errors"
+
+var (
+	ErrFileNotFound             = errors.New("arquivo não encontrado")
+	ErrJobNotCancellable        = errors.New("o processamento do arquivo já foi finalizado")
+	ErrFileInProgress           = errors.New("o arquivo ainda está em processamento")
+	ErrInvalidExtractionOptions = errors.New("opções de extração inválidas")
+	ErrAccountRequestNotFound   = errors.New("solicitação não encontrada")
+	ErrVideoExpired             = errors.New("o video do arquivo expirou e foi removido")
+	ErrUserPlanNotFound         = errors.New("plano do usuário não encontrado")
+	ErrUserNotFound             = errors.New("usuário não encontrado")
+	ErrDuplicateMessage         = errors.New("mensagem já recebida anteriormente")
+	ErrShuttingDown             = errors.New("o serviço está sendo desligado")
+	ErrUnknownJobType           = errors.New("tipo de processamento desconhecido")
+	ErrInvalidPayload           = errors.New("conteúdo da mensagem inválido")
+)
@@ -0,0 +1,10 @@
+package domain
+
+import "github.com/google/uuid"
+
+// ExpiredVideo agrupa os arquivos que compartilham um video cujo prazo de retenção terminou
+type ExpiredVideo struct {
+	VideoFilePath string
+	VideoFileSize int64
+	FileIDs       []uuid.UUID
+}
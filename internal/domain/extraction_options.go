@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// ExtractionOptions define os parâmetros usados na extração dos frames do video
+type ExtractionOptions struct {
+	FPS          float64 `json:"fps,omitempty"`
+	ImageQuality int     `json:"image_quality,omitempty"`
+}
+
+// Merge retorna as opções preenchendo os campos não informados com os valores padrão
+func (o *ExtractionOptions) Merge(defaults ExtractionOptions) ExtractionOptions {
+	merged := defaults
+	if o == nil {
+		return merged
+	}
+	if o.FPS > 0 {
+		merged.FPS = o.FPS
+	}
+	if o.ImageQuality > 0 {
+		merged.ImageQuality = o.ImageQuality
+	}
+	return merged
+}
+
+func (o ExtractionOptions) Validate() error {
+	if o.FPS <= 0 || o.FPS > 30 {
+		return fmt.Errorf("%w: fps deve estar entre 0 e 30", ErrInvalidExtractionOptions)
+	}
+	if o.ImageQuality < 1 || o.ImageQuality > 100 {
+		return fmt.Errorf("%w: image_quality deve estar entre 1 e 100", ErrInvalidExtractionOptions)
+	}
+	return nil
+}
+
+func (o *ExtractionOptions) Scan(value any) error {
+	if value == nil {
+		return nil
+	}
+	data, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("tipo inválido para extraction_options: %T", value)
+	}
+	return json.Unmarshal(data, o)
+}
+
+func (o *ExtractionOptions) Value() (driver.Value, error) {
+	if o == nil {
+		return nil, nil
+	}
+	return json.Marshal(o)
+}
@@ -8,16 +8,34 @@ import (
 )
 
 type File struct {
-	ID               uuid.UUID  `json:"id"`
-	UserID           uuid.UUID  `json:"user_id"`
-	VideoFilePath    string     `json:"video_file_path"`
-	VideoFileSize    int64      `json:"video_file_size,omitempty"`
-	ZipFilePath      *string    `json:"zip_file_path,omitempty"`
-	ZipFileSize      *int64     `json:"zip_file_size,omitempty"`
-	FileStatus       FileStatus `json:"file_status"`
-	ProcessingResult *string    `json:"processing_result,omitempty"`
-	CreatedAt        time.Time  `json:"created_at"`
-	UpdatedAt        *time.Time `json:"updated_at,omitempty"`
+	ID                uuid.UUID          `json:"id"`
+	UserID            uuid.UUID          `json:"user_id"`
+	VideoFilePath     string             `json:"video_file_path"`
+	VideoFileSize     int64              `json:"video_file_size,omitempty"`
+	ZipFilePath       *string            `json:"zip_file_path,omitempty"`
+	ZipFileSize       *int64             `json:"zip_file_size,omitempty"`
+	FileStatus        FileStatus         `json:"file_status"`
+	ProcessingResult  *string            `json:"processing_result,omitempty"`
+	ParentFileID      *uuid.UUID         `json:"parent_file_id,omitempty"`
+	Version           int                `json:"version"`
+	ExtractionOptions *ExtractionOptions `json:"extraction_options,omitempty"`
+	CreatedAt         time.Time          `json:"created_at"`
+	UpdatedAt         *time.Time         `json:"updated_at,omitempty"`
+	DeletedAt         *time.Time         `json:"deleted_at,omitempty"`
+	VideoExpiredAt    *time.Time         `json:"video_expired_at,omitempty"`
+	MessageID         *string            `json:"-"`
+}
+
+func (f *File) IsDeleted() bool {
+	return f.DeletedAt != nil
+}
+
+// RootID retorna o id do arquivo original, ao qual todas as versões reprocessadas ficam ligadas
+func (f *File) RootID() uuid.UUID {
+	if f.ParentFileID != nil {
+		return *f.ParentFileID
+	}
+	return f.ID
 }
 
 func (f *File) GetVideoFileName() string {
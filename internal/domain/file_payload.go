@@ -1,7 +1,14 @@
 package domain
 
+import "github.com/google/uuid"
+
 type FilePayload struct {
-	UserName string `json:"user_name"`
-	FilePath string `json:"file_path"`
-	FileSize int64  `json:"file_size"`
+	UserName          string             `json:"user_name"`
+	FilePath          string             `json:"file_path"`
+	FileSize          int64              `json:"file_size"`
+	FileID            *uuid.UUID         `json:"file_id,omitempty"`
+	Version           int                `json:"version,omitempty"`
+	ExtractionOptions *ExtractionOptions `json:"extraction_options,omitempty"`
+	ReplaceFileID     *uuid.UUID         `json:"replace_file_id,omitempty"`
+	Priority          string             `json:"priority,omitempty"`
 }
@@ -1,17 +1,44 @@
 package domain
 
+// Motivos das falhas de processamento. As rejeições na validação usam o código da rejeição
+const (
+	FailureReasonInvalidOptions = "invalid_options"
+	FailureReasonDownload       = "download"
+	FailureReasonExtraction     = "extraction"
+	FailureReasonZip            = "zip"
+	FailureReasonUpload         = "upload"
+	FailureReasonScheduling     = "scheduling"
+	FailureReasonUnknown        = "unknown"
+)
+
 type FileProcessingResult struct {
 	FilePath *string
 	FileSize *int64
-	Status   int
+	Status   int16
 	Message  string
+	// FailureReason classifica a falha para as métricas e não é gravado com o arquivo
+	FailureReason string
 }
 
 func NewFileProcessingResultWithError(message string) *FileProcessingResult {
 	return &FileProcessingResult{
 		FilePath: nil,
 		FileSize: nil,
-		Status:   4,
+		Status:   FileStatusError,
 		Message:  message,
 	}
 }
+
+func NewFileProcessingFailure(reason, message string) *FileProcessingResult {
+	result := NewFileProcessingResultWithError(message)
+	result.FailureReason = reason
+	return result
+}
+
+// Reason retorna o motivo da falha do processamento
+func (r *FileProcessingResult) Reason() string {
+	if r.FailureReason == "" {
+		return FailureReasonUnknown
+	}
+	return r.FailureReason
+}
@@ -1,5 +1,16 @@
 package domain
 
+// Identificadores da tabela file_status
+const (
+	FileStatusReceived   int16 = 1
+	FileStatusProcessing int16 = 2
+	FileStatusDone       int16 = 3
+	FileStatusError      int16 = 4
+	FileStatusCancelled  int16 = 5
+	FileStatusExpired    int16 = 6
+	FileStatusRejected   int16 = 7
+)
+
 type FileStatus struct {
 	ID     int16  `json:"id"`
 	Status string `json:"status"`
@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+const (
+	HealthUp   = "UP"
+	HealthDown = "DOWN"
+)
+
+// ComponentHealth é o resultado da última verificação de uma dependência
+type ComponentHealth struct {
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	LatencyMs int64     `json:"latency_ms"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// HealthReport é a situação da aplicação; Draining indica que o desligamento começou
+type HealthReport struct {
+	Status     string                     `json:"status"`
+	Draining   bool                       `json:"draining,omitempty"`
+	Components map[string]ComponentHealth `json:"components,omitempty"`
+}
@@ -0,0 +1,9 @@
+package adapters
+
+import (
+	"context"
+)
+
+type DistributedLock interface {
+	TryWithLock(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error)
+}
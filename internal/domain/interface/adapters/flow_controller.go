@@ -0,0 +1,7 @@
+package adapters
+
+// FlowController pausa e retoma a busca de novas mensagens quando os workers estão ocupados
+type FlowController interface {
+	PauseAll()
+	ResumeAll()
+}
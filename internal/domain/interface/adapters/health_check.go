@@ -0,0 +1,11 @@
+package adapters
+
+import (
+	"context"
+)
+
+// HealthCheck verifica a disponibilidade de uma dependência da aplicação, usada na prontidão
+type HealthCheck interface {
+	Name() string
+	Check(ctx context.Context) error
+}
@@ -0,0 +1,49 @@
+package adapters
+
+import (
+	"context"
+)
+
+// Message é uma mensagem recebida do broker, independente do transporte usado
+type Message struct {
+	// ID identifica a entrega de forma única no broker e é usado para descartar mensagens repetidas
+	ID    string
+	Key   string
+	Value []byte
+	// Topic é o tópico, fila ou subject de onde a mensagem foi recebida
+	Topic   string
+	Headers map[string]string
+}
+
+// Header retorna o valor do header informado, ou vazio quando a mensagem não o possui
+func (m *Message) Header(name string) string {
+	return m.Headers[name]
+}
+
+// Delivery é uma mensagem entregue por um JobSource. Ack confirma o tratamento e Nack devolve a
+// mensagem para ser entregue novamente
+type Delivery interface {
+	Message() *Message
+	Ack() error
+	Nack() error
+}
+
+// DeferredDelivery é uma entrega confirmada individualmente pelo broker, como no amqp e no nats. O
+// processador só a confirma quando o processamento termina ou volta para a fila, assim o prefetch limita os
+// processamentos aceitos. InProgress estende o prazo de confirmação do broker durante processamentos longos
+type DeferredDelivery interface {
+	Delivery
+	InProgress() error
+}
+
+// JobSource entrega os processamentos recebidos pelo broker
+type JobSource interface {
+	// Receive aguarda a próxima entrega até o contexto ser cancelado
+	Receive(ctx context.Context) (Delivery, error)
+	// Stop encerra o recebimento de novas mensagens e mantém a conexão, assim as entregas em andamento
+	// ainda podem ser confirmadas ou devolvidas
+	Stop() error
+	// Close encerra o recebimento e fecha a conexão; as entregas não confirmadas voltam para o broker
+	Close() error
+	FlowController
+}
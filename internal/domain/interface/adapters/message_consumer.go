@@ -1,10 +0,0 @@
-package adapters
-
-import (
-	"context"
-	"github.com/IBM/sarama"
-)
-
-type MessageConsumer interface {
-	ConsumeMessages(ctx context.Context, handler sarama.ConsumerGroupHandler) error
-}
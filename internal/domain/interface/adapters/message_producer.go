@@ -0,0 +1,13 @@
+package adapters
+
+import (
+	"context"
+)
+
+type MessageProducer interface {
+	PublishMessage(ctx context.Context, key string, value []byte) error
+	// PublishMessageWithHeaders publica a mensagem junto com os headers informados, entregues ao
+	// consumidor em Message.Headers
+	PublishMessageWithHeaders(ctx context.Context, key string, value []byte, headers map[string]string) error
+	Close() error
+}
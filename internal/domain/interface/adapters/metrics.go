@@ -0,0 +1,25 @@
+package adapters
+
+import "time"
+
+type Metrics interface {
+	ObjectsExpired(kind string, count int)
+	BytesExpired(kind string, size int64)
+	SchedulerQueueDepth(priorityClass string, depth int)
+	SchedulerWaitTime(priorityClass string, wait time.Duration)
+
+	// JobFinished conta os processamentos finalizados pelo resultado e, nas falhas, pelo motivo
+	JobFinished(outcome, reason string)
+	// StageDuration registra a duração das etapas do processamento: download, extraction, zip e upload
+	StageDuration(stage string, duration time.Duration)
+	// JobBytes registra o tamanho do video recebido (input) e do arquivo ZIP gerado (output)
+	JobBytes(direction string, size int64)
+	FramesExtracted(count int)
+	FFmpegExitCode(code int)
+	// JobsInFlight informa os processamentos aceitos que ainda não terminaram, aguardando ou em execução
+	JobsInFlight(count int)
+	// WorkerSlots informa quantos workers estão ocupados do total disponível
+	WorkerSlots(inUse, capacity int)
+	ConsumerLag(topic string, partition int32, lag int64)
+	HTTPRequest(method, route string, status int, duration time.Duration)
+}
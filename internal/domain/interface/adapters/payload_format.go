@@ -0,0 +1,19 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrUndecodablePayload indica um conteúdo que nunca poderá ser decodificado, como um schema
+// inexistente ou bytes corrompidos. Os demais erros do PayloadFormat são tratados como temporários
+var ErrUndecodablePayload = errors.New("conteúdo não pode ser decodificado")
+
+// PayloadFormat converte o conteúdo das mensagens de um formato de serialização, como Avro ou
+// Protobuf, no documento JSON equivalente, que é validado e convertido pelo PayloadDecoder
+type PayloadFormat interface {
+	// Accepts indica se o conteúdo, recebido com o content type informado, está neste formato
+	Accepts(contentType string, data []byte) bool
+	// Decode retorna o documento decodificado, composto por mapas, listas e valores escalares
+	Decode(ctx context.Context, data []byte) (any, error)
+}
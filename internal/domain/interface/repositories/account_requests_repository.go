@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	"github.com/google/uuid"
+)
+
+type AccountRequestsRepository interface {
+	CreateAccountRequest(ctx context.Context, request *domain.AccountRequest) (*uuid.UUID, error)
+	FindAccountRequestByID(ctx context.Context, id uuid.UUID) (*domain.AccountRequest, error)
+	UpdateAccountRequestProgress(ctx context.Context, request *domain.AccountRequest) error
+	// AnonymizeAccountRequests substitui o email do usuário removido em todas as suas solicitações
+	AnonymizeAccountRequests(ctx context.Context, userEmail string, userId uuid.UUID) error
+}
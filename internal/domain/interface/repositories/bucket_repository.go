@@ -3,9 +3,15 @@ package repositories
 import (
 	"context"
 	"mime/multipart"
+	"time"
 )
 
 type BucketRepository interface {
 	DownloadFile(ctx context.Context, fileWithPath string) ([]byte, map[string]string, error)
 	CreateFile(ctx context.Context, path string, filename string, file multipart.File) (string, error)
+	GetFileSize(ctx context.Context, fileWithPath string) (int64, error)
+	DeleteFile(ctx context.Context, fileWithPath string) error
+	DeleteFiles(ctx context.Context, filesWithPath []string) error
+	ListFiles(ctx context.Context, prefix string, handlePage func(filesWithPath []string) error) error
+	PresignDownloadURL(fileWithPath string, expiration time.Duration) (string, error)
 }
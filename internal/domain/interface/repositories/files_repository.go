@@ -1,12 +1,35 @@
 package repositories
 
 import (
+	"context"
+	"time"
+
 	"github.com/backstagefood/video-processor-worker/internal/domain"
 	"github.com/google/uuid"
 )
 
 type FilesRepository interface {
-	CreateFile(file *domain.File) (*uuid.UUID, error)
-	ListFilesByEmail(userEmail string) ([]*domain.File, error)
-	UpdateFileStatus(id *uuid.UUID, fileProcessingResult *domain.FileProcessingResult) error
+	CreateFile(ctx context.Context, file *domain.File, quotaCheck domain.QuotaCheck) (*uuid.UUID, *domain.QuotaExceeded, error)
+	ListFilesByEmail(ctx context.Context, userEmail string) ([]*domain.File, error)
+	UpdateFileStatus(ctx context.Context, id *uuid.UUID, fileProcessingResult *domain.FileProcessingResult) error
+	StartProcessing(ctx context.Context, id uuid.UUID) (bool, error)
+	RequeueFile(ctx context.Context, id uuid.UUID) error
+	Heartbeat(ctx context.Context, ids []uuid.UUID) error
+	ListStuckFiles(ctx context.Context, staleBefore time.Time, limit int) ([]*domain.StuckFile, error)
+	ReapStuckFile(ctx context.Context, id uuid.UUID, staleBefore time.Time, processingResult *domain.FileProcessingResult) (bool, error)
+	FindFileByID(ctx context.Context, id uuid.UUID, userEmail string) (*domain.File, error)
+	CancelFile(ctx context.Context, id uuid.UUID, userEmail string) (bool, error)
+	CancelFilesByUser(ctx context.Context, userId uuid.UUID) ([]uuid.UUID, error)
+	ListCancelledFiles(ctx context.Context, ids []uuid.UUID) ([]uuid.UUID, error)
+	CreateFileVersion(ctx context.Context, file *domain.File, quotaCheck domain.QuotaCheck) (*uuid.UUID, *domain.QuotaExceeded, error)
+	ReleaseZipFile(ctx context.Context, id uuid.UUID) (*string, error)
+	SoftDeleteFile(ctx context.Context, id uuid.UUID) error
+	CountActiveFilesByVideoPath(ctx context.Context, videoFilePath string, ignoredId uuid.UUID) (int, error)
+	ListAllFilesByUser(ctx context.Context, userId uuid.UUID) ([]*domain.File, error)
+	DeleteFilesByUser(ctx context.Context, userId uuid.UUID) (int64, error)
+	ListExpiredZipFiles(ctx context.Context, defaultRetentionDays int, limit int) ([]*domain.File, error)
+	MarkZipExpired(ctx context.Context, id uuid.UUID) error
+	ListExpiredVideos(ctx context.Context, defaultRetentionDays int, limit int) ([]*domain.ExpiredVideo, error)
+	MarkVideoExpired(ctx context.Context, ids []uuid.UUID) error
+	GetUserUsage(ctx context.Context, userId uuid.UUID) (*domain.QuotaUsage, error)
 }
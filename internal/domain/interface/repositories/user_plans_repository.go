@@ -0,0 +1,11 @@
+package repositories
+
+import (
+	"context"
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	"github.com/google/uuid"
+)
+
+type UserPlansRepository interface {
+	FindPlanByUserID(ctx context.Context, userId uuid.UUID) (*domain.UserPlan, error)
+}
@@ -1,9 +1,12 @@
 package repositories
 
 import (
+	"context"
 	"github.com/backstagefood/video-processor-worker/internal/domain"
+	"github.com/google/uuid"
 )
 
 type UsersRepository interface {
-	FindUserByEmail(email string) (*domain.User, error)
+	FindUserByEmail(ctx context.Context, email string) (*domain.User, error)
+	AnonymizeUser(ctx context.Context, id uuid.UUID) error
 }
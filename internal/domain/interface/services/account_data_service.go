@@ -0,0 +1,13 @@
+package services
+
+import (
+	"context"
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	"github.com/google/uuid"
+)
+
+type AccountDataService interface {
+	RequestErasure(ctx context.Context, userEmail string) (*domain.AccountRequest, error)
+	RequestExport(ctx context.Context, userEmail string) (*domain.AccountRequest, error)
+	GetRequest(ctx context.Context, id uuid.UUID) (*domain.AccountRequest, error)
+}
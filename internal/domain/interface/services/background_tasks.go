@@ -0,0 +1,12 @@
+package services
+
+import (
+	"context"
+)
+
+// BackgroundTasks executa as tarefas que continuam depois do fim da requisição que as iniciou, como as
+// solicitações sobre os dados da conta, permitindo interrompê-las e aguardá-las no desligamento
+type BackgroundTasks interface {
+	Go(run func(ctx context.Context))
+	Shutdown(ctx context.Context) error
+}
@@ -0,0 +1,16 @@
+package services
+
+import (
+	"context"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	"github.com/backstagefood/video-processor-worker/pkg/config"
+	"github.com/google/uuid"
+)
+
+type FilesService interface {
+	ReprocessFile(ctx context.Context, userEmail string, fileId uuid.UUID, request domain.ReprocessRequest) (*domain.File, error)
+	DeleteFile(ctx context.Context, userEmail string, fileId uuid.UUID, keepVideo bool) error
+	// ApplySettings aplica as opções de extração padrão recarregadas
+	ApplySettings(extraction config.ExtractionConfig)
+}
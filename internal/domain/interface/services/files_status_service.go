@@ -1,9 +1,10 @@
 package services
 
 import (
+	"context"
 	"github.com/backstagefood/video-processor-worker/internal/domain"
 )
 
 type FilesStatusService interface {
-	ListFilesByEmail(userEmail string) ([]*domain.File, error)
+	ListFilesByEmail(ctx context.Context, userEmail string) ([]*domain.File, error)
 }
@@ -0,0 +1,16 @@
+package services
+
+import (
+	"context"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+)
+
+type HealthService interface {
+	// Liveness indica apenas que o processo está respondendo, sem consultar as dependências
+	Liveness() *domain.HealthReport
+	// Readiness indica se a aplicação pode receber tráfego, a partir da verificação das dependências
+	Readiness(ctx context.Context) *domain.HealthReport
+	// StartDraining marca o início do desligamento; a partir daí a prontidão é reportada como DOWN
+	StartDraining()
+}
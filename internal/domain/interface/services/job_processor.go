@@ -0,0 +1,16 @@
+package services
+
+import (
+	"context"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	"github.com/backstagefood/video-processor-worker/pkg/config"
+)
+
+type JobProcessor interface {
+	Run(ctx context.Context, source adapters.JobSource) error
+	Shutdown(ctx context.Context) error
+	// ApplySettings aplica a configuração recarregada, redimensionando os workers sem interromper os
+	// processamentos em execução
+	ApplySettings(settings config.WorkerConfig, extraction config.ExtractionConfig, features config.FeatureFlags)
+}
@@ -0,0 +1,14 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type JobRegistry interface {
+	Register(fileId uuid.UUID, cancel context.CancelFunc)
+	Unregister(fileId uuid.UUID)
+	Cancel(fileId uuid.UUID) bool
+	RunningJobs() []uuid.UUID
+}
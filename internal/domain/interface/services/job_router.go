@@ -0,0 +1,19 @@
+package services
+
+import (
+	"context"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+)
+
+// JobHandler trata as mensagens de um tipo de processamento. O erro indica uma falha temporária,
+// em que a mensagem deve ser entregue novamente
+type JobHandler interface {
+	HandleMessage(ctx context.Context, message *adapters.Message) error
+}
+
+// JobRouter entrega cada mensagem ao handler registrado para o seu tipo
+type JobRouter interface {
+	Register(jobType string, handler JobHandler)
+	Route(ctx context.Context, message *adapters.Message) error
+}
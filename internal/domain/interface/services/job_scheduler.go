@@ -0,0 +1,17 @@
+package services
+
+import (
+	"context"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+)
+
+type JobScheduler interface {
+	Submit(ctx context.Context, job *domain.ScheduledJob) error
+	Next(ctx context.Context) (*domain.ScheduledJob, error)
+	Depth() int
+	Saturated() bool
+	WaitForCapacity(ctx context.Context) error
+	// Resize altera os pesos das classes e o limite da fila, mantendo os processamentos já agendados
+	Resize(weights map[string]int, maxQueued int)
+}
@@ -0,0 +1,10 @@
+package services
+
+import (
+	"context"
+	"github.com/google/uuid"
+)
+
+type JobsService interface {
+	CancelJob(ctx context.Context, userEmail string, fileId uuid.UUID) error
+}
@@ -0,0 +1,20 @@
+package services
+
+import (
+	"context"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+)
+
+// PayloadConverter converte o conteúdo de uma versão do payload para a versão seguinte
+type PayloadConverter func(data map[string]any) (map[string]any, error)
+
+// PayloadDecoder lê o conteúdo das mensagens de cada tipo de processamento, validando-o contra o
+// JSON Schema da sua versão e convertendo as versões antigas para a versão atual. O conteúdo é JSON,
+// a não ser que um dos formatos registrados o aceite
+type PayloadDecoder interface {
+	RegisterSchema(jobType string, version int, schema []byte) error
+	RegisterConverter(jobType string, fromVersion int, converter PayloadConverter)
+	RegisterFormat(format adapters.PayloadFormat)
+	Decode(ctx context.Context, jobType string, message *adapters.Message, target any) error
+}
@@ -0,0 +1,15 @@
+package services
+
+import (
+	"context"
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	"github.com/google/uuid"
+)
+
+type QuotaService interface {
+	// QuotaCheck retorna a verificação da cota do plano do usuário para um novo processamento de
+	// incomingBytes, aplicada pelo repositório na mesma transação da gravação do arquivo
+	QuotaCheck(ctx context.Context, userId uuid.UUID, incomingBytes int64) (domain.QuotaCheck, error)
+	GetPlan(ctx context.Context, userId uuid.UUID) (*domain.UserPlan, error)
+	GetUsage(ctx context.Context, userEmail string) (*domain.UserPlan, *domain.QuotaUsage, error)
+}
@@ -0,0 +1,9 @@
+package services
+
+import (
+	"context"
+)
+
+type RetentionJanitor interface {
+	Run(ctx context.Context)
+}
@@ -0,0 +1,13 @@
+package services
+
+import (
+	"context"
+
+	"github.com/backstagefood/video-processor-worker/pkg/config"
+)
+
+type StuckJobReaper interface {
+	Run(ctx context.Context)
+	// ApplySettings aplica a configuração recarregada; apenas o limite de tentativas é alterado
+	ApplySettings(settings config.ReaperConfig)
+}
@@ -0,0 +1,12 @@
+package services
+
+import (
+	"context"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+)
+
+type VideoValidator interface {
+	ValidateSize(declaredSize, actualSize int64) *domain.VideoRejection
+	ValidateContent(ctx context.Context, videoData []byte) *domain.VideoRejection
+}
@@ -0,0 +1,6 @@
+package domain
+
+const (
+	// JobTypeVideoFrames é o processamento de extração de frames do vídeo, assumido para as mensagens sem tipo
+	JobTypeVideoFrames = "video.frames"
+)
@@ -0,0 +1,6 @@
+package domain
+
+type ReprocessRequest struct {
+	ExtractionOptions *ExtractionOptions `json:"extraction_options,omitempty"`
+	ReplacePrevious   bool               `json:"replace_previous"`
+}
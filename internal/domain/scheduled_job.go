@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Classes de prioridade do agendador de processamentos
+const (
+	PriorityHigh   = "high"
+	PriorityNormal = "normal"
+	PriorityLow    = "low"
+)
+
+// ScheduledJob é um processamento aguardando um worker livre no agendador
+type ScheduledJob struct {
+	UserKey       string
+	PriorityClass string
+	EnqueuedAt    time.Time
+	Run           func(ctx context.Context)
+}
+
+// ResolvePriorityClass usa a prioridade informada no payload e, na ausência dela, a prioridade do plano do usuário
+func ResolvePriorityClass(requested, planClass string) string {
+	for _, class := range []string{requested, planClass} {
+		switch class {
+		case PriorityHigh, PriorityNormal, PriorityLow:
+			return class
+		}
+	}
+	return PriorityNormal
+}
@@ -0,0 +1,20 @@
+package domain
+
+// StuckFile é um arquivo aceito por um worker, aguardando ou em processamento, que deixou de enviar sinais de vida
+type StuckFile struct {
+	File      *File
+	UserEmail string
+	Attempts  int
+}
+
+// Payload recria a mensagem de processamento usada para devolver o arquivo à fila
+func (s *StuckFile) Payload() FilePayload {
+	return FilePayload{
+		UserName:          s.UserEmail,
+		FilePath:          s.File.VideoFilePath,
+		FileSize:          s.File.VideoFileSize,
+		FileID:            &s.File.ID,
+		Version:           s.File.Version,
+		ExtractionOptions: s.File.ExtractionOptions,
+	}
+}
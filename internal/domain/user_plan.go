@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"fmt"
+)
+
+// Códigos de rejeição por cota excedida
+const (
+	QuotaConcurrentJobs = "QUOTA_CONCURRENT_JOBS"
+	QuotaJobsPerDay     = "QUOTA_JOBS_PER_DAY"
+	QuotaBytesPerMonth  = "QUOTA_BYTES_PER_MONTH"
+	QuotaStoredBytes    = "QUOTA_STORED_BYTES"
+)
+
+// UserPlan define os limites de uso do usuário. Limites iguais a zero não são aplicados
+type UserPlan struct {
+	PlanName          string `json:"plan_name"`
+	MaxConcurrentJobs int    `json:"max_concurrent_jobs"`
+	MaxJobsPerDay     int    `json:"max_jobs_per_day"`
+	MaxBytesPerMonth  int64  `json:"max_bytes_per_month"`
+	MaxStoredBytes    int64  `json:"max_stored_bytes"`
+	PriorityClass     string `json:"priority_class"`
+}
+
+// QuotaUsage é o consumo atual do usuário em relação aos limites do plano
+type QuotaUsage struct {
+	ConcurrentJobs int   `json:"concurrent_jobs"`
+	JobsToday      int   `json:"jobs_today"`
+	BytesThisMonth int64 `json:"bytes_this_month"`
+	StoredBytes    int64 `json:"stored_bytes"`
+}
+
+type QuotaExceeded struct {
+	Code    string
+	Message string
+}
+
+func (q *QuotaExceeded) Error() string {
+	return fmt.Sprintf("[%s] %s", q.Code, q.Message)
+}
+
+// QuotaCheck verifica se um novo processamento cabe nos limites a partir do consumo atual do usuário,
+// retornando a cota excedida quando não cabe
+type QuotaCheck func(usage QuotaUsage) *QuotaExceeded
+
+// Check verifica se um novo processamento de incomingBytes cabe nos limites do plano
+func (p *UserPlan) Check(usage QuotaUsage, incomingBytes int64) *QuotaExceeded {
+	switch {
+	case p.MaxConcurrentJobs > 0 && usage.ConcurrentJobs >= p.MaxConcurrentJobs:
+		return &QuotaExceeded{Code: QuotaConcurrentJobs,
+			Message: fmt.Sprintf("limite de %d processamentos simultâneos atingido", p.MaxConcurrentJobs)}
+	case p.MaxJobsPerDay > 0 && usage.JobsToday >= p.MaxJobsPerDay:
+		return &QuotaExceeded{Code: QuotaJobsPerDay,
+			Message: fmt.Sprintf("limite de %d processamentos por dia atingido", p.MaxJobsPerDay)}
+	case p.MaxBytesPerMonth > 0 && usage.BytesThisMonth+incomingBytes > p.MaxBytesPerMonth:
+		return &QuotaExceeded{Code: QuotaBytesPerMonth,
+			Message: fmt.Sprintf("limite de %d bytes processados no mês atingido", p.MaxBytesPerMonth)}
+	case p.MaxStoredBytes > 0 && usage.StoredBytes+incomingBytes > p.MaxStoredBytes:
+		return &QuotaExceeded{Code: QuotaStoredBytes,
+			Message: fmt.Sprintf("limite de %d bytes armazenados atingido", p.MaxStoredBytes)}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package domain
+
+import "testing"
+
+func TestUserPlanCheck(t *testing.T) {
+	plan := &UserPlan{MaxConcurrentJobs: 2, MaxJobsPerDay: 10, MaxBytesPerMonth: 1000, MaxStoredBytes: 5000}
+
+	tests := map[string]struct {
+		usage    QuotaUsage
+		incoming int64
+		expected string
+	}{
+		"within limits":      {QuotaUsage{ConcurrentJobs: 1, JobsToday: 3, BytesThisMonth: 100, StoredBytes: 100}, 100, ""},
+		"concurrent jobs":    {QuotaUsage{ConcurrentJobs: 2}, 100, QuotaConcurrentJobs},
+		"jobs per day":       {QuotaUsage{JobsToday: 10}, 100, QuotaJobsPerDay},
+		"bytes per month":    {QuotaUsage{BytesThisMonth: 950}, 100, QuotaBytesPerMonth},
+		"stored bytes":       {QuotaUsage{StoredBytes: 4950}, 100, QuotaStoredBytes},
+		"exactly at monthly": {QuotaUsage{BytesThisMonth: 900}, 100, ""},
+	}
+
+	for name, tt := range tests {
+		result := plan.Check(tt.usage, tt.incoming)
+		switch {
+		case tt.expected == "" && result != nil:
+			t.Errorf("%s: expected no quota exceeded, got %s", name, result.Code)
+		case tt.expected != "" && (result == nil || result.Code != tt.expected):
+			t.Errorf("%s: expected %s, got %v", name, tt.expected, result)
+		}
+	}
+
+	unlimited := &UserPlan{}
+	if result := unlimited.Check(QuotaUsage{ConcurrentJobs: 100, JobsToday: 100, BytesThisMonth: 1 << 40}, 1<<40); result != nil {
+		t.Errorf("Expected unlimited plan to accept, got %s", result.Code)
+	}
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Códigos de rejeição da etapa de validação do video de entrada
+const (
+	RejectionEmptyFile           = "EMPTY_FILE"
+	RejectionFileTooLarge        = "FILE_TOO_LARGE"
+	RejectionSizeMismatch        = "SIZE_MISMATCH"
+	RejectionUnknownContainer    = "UNKNOWN_CONTAINER"
+	RejectionContainerNotAllowed = "CONTAINER_NOT_ALLOWED"
+	RejectionProbeFailed         = "PROBE_FAILED"
+	RejectionNoVideoStream       = "NO_VIDEO_STREAM"
+	RejectionCodecNotAllowed     = "CODEC_NOT_ALLOWED"
+	RejectionDurationExceeded    = "DURATION_EXCEEDED"
+)
+
+type VideoRejection struct {
+	Code    string
+	Message string
+}
+
+func NewVideoRejection(code, message string) *VideoRejection {
+	return &VideoRejection{Code: code, Message: message}
+}
+
+func (r *VideoRejection) Error() string {
+	return fmt.Sprintf("[%s] %s", r.Code, r.Message)
+}
+
+// FailureReason retorna o motivo usado nas métricas dos processamentos, como file_too_large
+func (r *VideoRejection) FailureReason() string {
+	return strings.ToLower(r.Code)
+}
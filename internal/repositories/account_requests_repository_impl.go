@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/repositories"
+	databaseconnection "github.com/backstagefood/video-processor-worker/pkg/adapter/postgres"
+	"github.com/google/uuid"
+)
+
+type accountRequestsRepositoryImpl struct {
+	dbClient *sql.DB
+}
+
+func NewAccountRequestsRepository(db *databaseconnection.ApplicationDatabase) repositories.AccountRequestsRepository {
+	return &accountRequestsRepositoryImpl{
+		dbClient: db.Client(),
+	}
+}
+
+func (a *accountRequestsRepositoryImpl) CreateAccountRequest(ctx context.Context, request *domain.AccountRequest) (*uuid.UUID, error) {
+	query := `
+        INSERT INTO account_requests
+        (user_email, kind, status)
+        VALUES ($1, $2, $3)
+        RETURNING id, created_at;
+    `
+	err := a.dbClient.QueryRowContext(ctx,
+		query,
+		request.UserEmail,
+		request.Kind,
+		request.Status,
+	).Scan(&request.ID, &request.CreatedAt)
+	if err != nil {
+		slog.Error("não foi possível criar a solicitação", "error", err)
+		return nil, err
+	}
+	return &request.ID, nil
+}
+
+func (a *accountRequestsRepositoryImpl) FindAccountRequestByID(ctx context.Context, id uuid.UUID) (*domain.AccountRequest, error) {
+	query := `
+        SELECT id, user_email, kind, status, objects_processed, rows_processed, export_url, error_message, created_at, updated_at, finished_at
+        FROM account_requests
+        WHERE id = $1;
+    `
+	var request domain.AccountRequest
+	err := a.dbClient.QueryRowContext(ctx, query, id).Scan(
+		&request.ID,
+		&request.UserEmail,
+		&request.Kind,
+		&request.Status,
+		&request.ObjectsProcessed,
+		&request.RowsProcessed,
+		&request.ExportURL,
+		&request.ErrorMessage,
+		&request.CreatedAt,
+		&request.UpdatedAt,
+		&request.FinishedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrAccountRequestNotFound
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+// UpdateAccountRequestProgress grava a situação e os contadores da solicitação,
+// preenchendo a data de término quando ela é finalizada
+func (a *accountRequestsRepositoryImpl) UpdateAccountRequestProgress(ctx context.Context, request *domain.AccountRequest) error {
+	query := `
+        UPDATE account_requests
+		SET status=$2, objects_processed=$3, rows_processed=$4, export_url=$5, error_message=$6, updated_at=now(),
+		    finished_at=CASE WHEN $2 IN ($7, $8) THEN now() ELSE NULL END
+		WHERE id=$1;
+    `
+	_, err := a.dbClient.ExecContext(ctx,
+		query,
+		request.ID,
+		request.Status,
+		request.ObjectsProcessed,
+		request.RowsProcessed,
+		request.ExportURL,
+		request.ErrorMessage,
+		domain.AccountRequestDone,
+		domain.AccountRequestFailed,
+	)
+	return err
+}
+
+// AnonymizeAccountRequests troca o email pelo mesmo endereço anônimo gravado no usuário por AnonymizeUser,
+// mantendo o histórico das solicitações sem o dado pessoal
+func (a *accountRequestsRepositoryImpl) AnonymizeAccountRequests(ctx context.Context, userEmail string, userId uuid.UUID) error {
+	query := `
+        UPDATE account_requests
+        SET user_email = 'removido-' || $2 || '@anonimo.invalid', updated_at = now()
+        WHERE user_email = $1
+    `
+	_, err := a.dbClient.ExecContext(ctx, query, userEmail, userId)
+	return err
+}
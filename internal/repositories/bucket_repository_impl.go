@@ -12,6 +12,7 @@ import (
 	"mime/multipart"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 type bucketRepository struct {
@@ -82,3 +83,89 @@ func (v *bucketRepository) DownloadFile(ctx context.Context, fileWithPath string
 
 	return data, metadata, nil
 }
+
+func (v *bucketRepository) GetFileSize(ctx context.Context, fileWithPath string) (int64, error) {
+	result, err := v.s3Conn.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(v.bucketName),
+		Key:    aws.String(fileWithPath),
+	})
+	if err != nil {
+		log.Println("failed to head object from S3: ", err)
+		return 0, fmt.Errorf("failed to head object from S3: %w", err)
+	}
+	return aws.Int64Value(result.ContentLength), nil
+}
+
+func (v *bucketRepository) DeleteFile(ctx context.Context, fileWithPath string) error {
+	log.Println("deleting file: ", fileWithPath)
+	_, err := v.s3Conn.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(v.bucketName),
+		Key:    aws.String(fileWithPath),
+	})
+	if err != nil {
+		log.Println("failed to delete object from S3: ", err)
+		return fmt.Errorf("failed to delete object from S3: %w", err)
+	}
+	return nil
+}
+
+// DeleteFiles remove os objetos em lotes, respeitando o limite de 1000 chaves por requisição do S3
+func (v *bucketRepository) DeleteFiles(ctx context.Context, filesWithPath []string) error {
+	for start := 0; start < len(filesWithPath); start += 1000 {
+		end := min(start+1000, len(filesWithPath))
+		objects := make([]*s3.ObjectIdentifier, 0, end-start)
+		for _, key := range filesWithPath[start:end] {
+			objects = append(objects, &s3.ObjectIdentifier{Key: aws.String(key)})
+		}
+		result, err := v.s3Conn.DeleteObjectsWithContext(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(v.bucketName),
+			Delete: &s3.Delete{Objects: objects, Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			log.Println("failed to delete objects from S3: ", err)
+			return fmt.Errorf("failed to delete objects from S3: %w", err)
+		}
+		if len(result.Errors) > 0 {
+			log.Println("failed to delete some objects from S3: ", result.Errors)
+			return fmt.Errorf("failed to delete %d objects from S3: %s", len(result.Errors), aws.StringValue(result.Errors[0].Message))
+		}
+	}
+	return nil
+}
+
+// ListFiles percorre as páginas do ListObjectsV2 chamando handlePage com as chaves de cada página
+func (v *bucketRepository) ListFiles(ctx context.Context, prefix string, handlePage func(filesWithPath []string) error) error {
+	var handleErr error
+	err := v.s3Conn.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(v.bucketName),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		keys := make([]string, 0, len(page.Contents))
+		for _, object := range page.Contents {
+			keys = append(keys, aws.StringValue(object.Key))
+		}
+		if len(keys) == 0 {
+			return !lastPage
+		}
+		handleErr = handlePage(keys)
+		return handleErr == nil
+	})
+	if err != nil {
+		log.Println("failed to list objects from S3: ", err)
+		return fmt.Errorf("failed to list objects from S3: %w", err)
+	}
+	return handleErr
+}
+
+func (v *bucketRepository) PresignDownloadURL(fileWithPath string, expiration time.Duration) (string, error) {
+	request, _ := v.s3Conn.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(v.bucketName),
+		Key:    aws.String(fileWithPath),
+	})
+	url, err := request.Presign(expiration)
+	if err != nil {
+		log.Println("failed to presign object url: ", err)
+		return "", fmt.Errorf("failed to presign object url: %w", err)
+	}
+	return url, nil
+}
@@ -1,15 +1,61 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"log/slog"
+	"time"
 
 	"github.com/backstagefood/video-processor-worker/internal/domain"
 	"github.com/backstagefood/video-processor-worker/internal/domain/interface/repositories"
 	databaseconnection "github.com/backstagefood/video-processor-worker/pkg/adapter/postgres"
 )
 
+// fileColumns lista as colunas lidas por scanFile, na mesma ordem
+const fileColumns = `f.id, f.user_id, f.video_file_path, f.video_file_size, f.zip_file_path, f.zip_file_size, s.id, s.status, f.processing_result, f.parent_file_id, f.version, f.extraction_options, f.created_at, f.updated_at, f.deleted_at, f.video_expired_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanFile lê as colunas de fileColumns seguidas das colunas adicionais em extra
+func scanFile(row rowScanner, extra ...any) (*domain.File, error) {
+	var file domain.File
+	var extractionOptions domain.ExtractionOptions
+	var rawExtractionOptions []byte
+	dest := []any{
+		&file.ID,
+		&file.UserID,
+		&file.VideoFilePath,
+		&file.VideoFileSize,
+		&file.ZipFilePath,
+		&file.ZipFileSize,
+		&file.FileStatus.ID,
+		&file.FileStatus.Status,
+		&file.ProcessingResult,
+		&file.ParentFileID,
+		&file.Version,
+		&rawExtractionOptions,
+		&file.CreatedAt,
+		&file.UpdatedAt,
+		&file.DeletedAt,
+		&file.VideoExpiredAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	if rawExtractionOptions != nil {
+		if err := extractionOptions.Scan(rawExtractionOptions); err != nil {
+			return nil, err
+		}
+		file.ExtractionOptions = &extractionOptions
+	}
+	return &file, nil
+}
+
 type filesRepositoryImpl struct {
 	dbClient *sql.DB
 }
@@ -20,93 +66,561 @@ func NewFilesRepository(db *databaseconnection.ApplicationDatabase) repositories
 	}
 }
 
-func (f *filesRepositoryImpl) CreateFile(file *domain.File) (*uuid.UUID, error) {
+// CreateFile grava o arquivo recebido. A cota é verificada na mesma transação da gravação, com o usuário
+// bloqueado, assim recebimentos simultâneos do mesmo usuário não passam juntos pela verificação; o arquivo
+// acima da cota é gravado como rejeitado e a cota excedida é retornada. Uma mensagem entregue novamente,
+// com o mesmo MessageID, não gera um novo registro e retorna domain.ErrDuplicateMessage
+func (f *filesRepositoryImpl) CreateFile(ctx context.Context, file *domain.File, quotaCheck domain.QuotaCheck) (*uuid.UUID, *domain.QuotaExceeded, error) {
+	tx, err := f.dbClient.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	usage, err := lockUserUsage(ctx, tx, file.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	quotaExceeded := quotaCheck(*usage)
+	if quotaExceeded != nil {
+		file.FileStatus.ID = domain.FileStatusRejected
+	}
+
+	// o sinal de vida marca o arquivo como aceito pela réplica, que ainda vai confirmar a mensagem
 	query := `
         INSERT INTO files
-        (user_id, video_file_path, video_file_size, status_id)
-        VALUES ($1, $2, $3, $4)
-        RETURNING id;
+        (user_id, video_file_path, video_file_size, status_id, extraction_options, message_id, heartbeat_at)
+        VALUES ($1, $2, $3, $4, $5, $6, now())
+        ON CONFLICT (message_id) DO NOTHING
+        RETURNING id, version;
     `
-	err := f.dbClient.QueryRow(
+	err = tx.QueryRowContext(ctx,
 		query,
 		file.UserID,
 		file.VideoFilePath,
 		file.VideoFileSize,
 		file.FileStatus.ID,
-	).Scan(&file.ID)
+		file.ExtractionOptions,
+		file.MessageID,
+	).Scan(&file.ID, &file.Version)
 
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, domain.ErrDuplicateMessage
+	}
 	if err != nil {
 		slog.Error("não foi possível criar o arquivo", "error", err)
-		return nil, err
+		return nil, nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+
+	return &file.ID, quotaExceeded, nil
+
+}
+
+// StartProcessing muda o arquivo de aguardando para em processamento, retornando false quando
+// ele já foi iniciado, finalizado ou cancelado
+func (f *filesRepositoryImpl) StartProcessing(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `
+        UPDATE files
+		SET status_id=$2, processing_result='em processamento', heartbeat_at=now(), attempts=attempts + 1, updated_at=now()
+		WHERE id=$1 AND status_id=$3;
+    `
+	result, err := f.dbClient.ExecContext(ctx, query, id, domain.FileStatusProcessing, domain.FileStatusReceived)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// RequeueFile devolve o arquivo interrompido ou ainda na fila desta réplica para aguardando, permitindo que
+// ele seja processado novamente. O sinal de vida é removido, pois a mensagem volta a ficar no broker
+func (f *filesRepositoryImpl) RequeueFile(ctx context.Context, id uuid.UUID) error {
+	query := `
+        UPDATE files
+		SET status_id=$2, processing_result='aguardando novo processamento', heartbeat_at=NULL, updated_at=now()
+		WHERE id=$1 AND status_id IN ($2, $3);
+    `
+	_, err := f.dbClient.ExecContext(ctx, query, id, domain.FileStatusReceived, domain.FileStatusProcessing)
+	return err
+}
+
+// Heartbeat registra o sinal de vida dos arquivos aceitos por esta réplica, aguardando ou em processamento
+func (f *filesRepositoryImpl) Heartbeat(ctx context.Context, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	values := make([]string, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, id.String())
 	}
+	_, err := f.dbClient.ExecContext(ctx, `UPDATE files SET heartbeat_at=now() WHERE id = ANY($1::uuid[]) AND status_id IN ($2, $3);`,
+		pq.Array(values), domain.FileStatusReceived, domain.FileStatusProcessing)
+	return err
+}
 
-	return &file.ID, nil
+// stuckFileCondition seleciona os arquivos sem sinal de vida desde $2: os em processamento e os aguardando
+// que já foram aceitos por uma réplica, cuja mensagem já foi confirmada. Os aguardando sem sinal de vida
+// ainda têm a mensagem no broker e não são recuperados
+const stuckFileCondition = `
+		  (f.status_id = $3 OR (f.status_id = $4 AND f.heartbeat_at IS NOT NULL))
+		  AND COALESCE(f.heartbeat_at, f.updated_at, f.created_at) < $2`
 
+// ListStuckFiles lista os arquivos sem sinal de vida desde staleBefore
+func (f *filesRepositoryImpl) ListStuckFiles(ctx context.Context, staleBefore time.Time, limit int) ([]*domain.StuckFile, error) {
+	query := `
+       SELECT ` + fileColumns + `, u.email, f.attempts
+		FROM files f
+		JOIN file_status s ON f.status_id = s.id
+		JOIN users u ON f.user_id = u.id
+		WHERE ` + stuckFileCondition + `
+		ORDER BY f.created_at
+		LIMIT $1;
+	`
+	rows, err := f.dbClient.QueryContext(ctx, query, limit, staleBefore, domain.FileStatusProcessing, domain.FileStatusReceived)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	files := make([]*domain.StuckFile, 0)
+	for rows.Next() {
+		var stuckFile domain.StuckFile
+		stuckFile.File, err = scanFile(rows, &stuckFile.UserEmail, &stuckFile.Attempts)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &stuckFile)
+	}
+	return files, rows.Err()
 }
 
-func (f *filesRepositoryImpl) UpdateFileStatus(id *uuid.UUID, fileProcessingResult *domain.FileProcessingResult) error {
+// ReapStuckFile muda o status de um arquivo sem sinal de vida desde staleBefore, retornando false
+// quando o processamento voltou a responder ou foi finalizado nesse meio tempo. O sinal de vida é
+// removido, pois o arquivo devolvido para aguardando volta a ter a mensagem no broker
+func (f *filesRepositoryImpl) ReapStuckFile(ctx context.Context, id uuid.UUID, staleBefore time.Time, processingResult *domain.FileProcessingResult) (bool, error) {
+	query := `
+        UPDATE files f
+		SET status_id=$5, processing_result=$6, heartbeat_at=NULL, updated_at=now()
+		WHERE f.id=$1
+		  AND ` + stuckFileCondition + `;
+    `
+	result, err := f.dbClient.ExecContext(ctx, query, id, staleBefore, domain.FileStatusProcessing, domain.FileStatusReceived, processingResult.Status, processingResult.Message)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (f *filesRepositoryImpl) UpdateFileStatus(ctx context.Context, id *uuid.UUID, fileProcessingResult *domain.FileProcessingResult) error {
 	slog.Info("atualiza status de processamento do arquivo", "fileProcessingResult", fileProcessingResult)
 	query := `
         UPDATE files
 		SET status_id=$2, zip_file_path=$3, zip_file_size=$4, processing_result=$5, updated_at=now()
-		WHERE id=$1;
+		WHERE id=$1 AND status_id <> $6;
     `
 
 	// Validade UUID fields
-	stmt, err := f.dbClient.Prepare(query)
+	stmt, err := f.dbClient.PrepareContext(ctx, query)
 	defer stmt.Close()
 	if err != nil {
 		return err
 	}
-	_, err = stmt.Exec(
+	_, err = stmt.ExecContext(ctx,
 		id,
 		fileProcessingResult.Status,
 		fileProcessingResult.FilePath,
 		fileProcessingResult.FileSize,
-		fileProcessingResult.Message)
+		fileProcessingResult.Message,
+		domain.FileStatusCancelled)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (f *filesRepositoryImpl) ListFilesByEmail(userEmail string) ([]*domain.File, error) {
+func (f *filesRepositoryImpl) ListFilesByEmail(ctx context.Context, userEmail string) ([]*domain.File, error) {
 	query := `
-       SELECT f.id, f.user_id, f.video_file_path, f.video_file_size, f.zip_file_path, f.zip_file_size, s.id, s.status, f.processing_result, f.created_at, f.updated_at
+       SELECT ` + fileColumns + `
 		FROM files f, users u, file_status s
 		WHERE f.user_id = u.id
 		  AND f.status_id = s.id
+		  AND f.deleted_at IS NULL
 		  AND u.email = $1;
 	`
-	stmt, err := f.dbClient.Prepare(query)
+	stmt, err := f.dbClient.PrepareContext(ctx, query)
 	defer stmt.Close()
 	if err != nil {
 		return nil, err
 	}
-	rows, err := stmt.Query(userEmail)
+	rows, err := stmt.QueryContext(ctx, userEmail)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	files := make([]*domain.File, 0)
 	for rows.Next() {
-		var file domain.File
-		if err := rows.Scan(
-			&file.ID,
-			&file.UserID,
-			&file.VideoFilePath,
-			&file.VideoFileSize,
-			&file.ZipFilePath,
-			&file.ZipFileSize,
-			&file.FileStatus.ID,
-			&file.FileStatus.Status,
-			&file.ProcessingResult,
-			&file.CreatedAt,
-			&file.UpdatedAt,
-		); err != nil {
+		file, err := scanFile(rows)
+		if err != nil {
 			return nil, err
 		}
-		files = append(files, &file)
+		files = append(files, file)
 	}
 	return files, nil
 }
+
+func (f *filesRepositoryImpl) FindFileByID(ctx context.Context, id uuid.UUID, userEmail string) (*domain.File, error) {
+	query := `
+       SELECT ` + fileColumns + `
+		FROM files f, users u, file_status s
+		WHERE f.user_id = u.id
+		  AND f.status_id = s.id
+		  AND f.id = $1
+		  AND u.email = $2;
+	`
+	file, err := scanFile(f.dbClient.QueryRowContext(ctx, query, id, userEmail))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrFileNotFound
+		}
+		return nil, err
+	}
+	return file, nil
+}
+
+// CreateFileVersion grava uma nova versão do arquivo ligada ao arquivo original, com o número da versão
+// calculado a partir das versões existentes. Como em CreateFile, a cota é verificada com o usuário
+// bloqueado; a versão acima da cota não é gravada e a cota excedida é retornada
+func (f *filesRepositoryImpl) CreateFileVersion(ctx context.Context, file *domain.File, quotaCheck domain.QuotaCheck) (*uuid.UUID, *domain.QuotaExceeded, error) {
+	tx, err := f.dbClient.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	usage, err := lockUserUsage(ctx, tx, file.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if quotaExceeded := quotaCheck(*usage); quotaExceeded != nil {
+		return nil, quotaExceeded, nil
+	}
+
+	// o lock no arquivo original serializa os reprocessamentos simultâneos, que calculariam a mesma versão
+	var parentFileId uuid.UUID
+	err = tx.QueryRowContext(ctx, `SELECT id FROM files WHERE id = $1 FOR UPDATE;`, file.ParentFileID).Scan(&parentFileId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, domain.ErrFileNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query := `
+        INSERT INTO files
+        (user_id, video_file_path, video_file_size, status_id, extraction_options, parent_file_id, version)
+        VALUES ($1, $2, $3, $4, $5, $6,
+                (SELECT COALESCE(MAX(version), 1) + 1 FROM files WHERE id = $6 OR parent_file_id = $6))
+        RETURNING id, version;
+    `
+	err = tx.QueryRowContext(ctx,
+		query,
+		file.UserID,
+		file.VideoFilePath,
+		file.VideoFileSize,
+		file.FileStatus.ID,
+		file.ExtractionOptions,
+		parentFileId,
+	).Scan(&file.ID, &file.Version)
+	if err != nil {
+		slog.Error("não foi possível criar a nova versão do arquivo", "error", err)
+		return nil, nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return &file.ID, nil, nil
+}
+
+// ReleaseZipFile desassocia o arquivo ZIP do registro e retorna o caminho que estava gravado
+func (f *filesRepositoryImpl) ReleaseZipFile(ctx context.Context, id uuid.UUID) (*string, error) {
+	query := `
+        WITH previous AS (
+            SELECT id, zip_file_path FROM files WHERE id = $1 FOR UPDATE
+        )
+        UPDATE files f
+		SET zip_file_path=NULL, zip_file_size=NULL, updated_at=now()
+		FROM previous
+		WHERE f.id = previous.id
+		RETURNING previous.zip_file_path;
+    `
+	var zipFilePath *string
+	if err := f.dbClient.QueryRowContext(ctx, query, id).Scan(&zipFilePath); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrFileNotFound
+		}
+		return nil, err
+	}
+	return zipFilePath, nil
+}
+
+// CancelFile marca o arquivo como cancelado se ele ainda estiver aguardando ou em processamento
+func (f *filesRepositoryImpl) CancelFile(ctx context.Context, id uuid.UUID, userEmail string) (bool, error) {
+	query := `
+        UPDATE files f
+		SET status_id=$3, processing_result='cancelado pelo usuário', updated_at=now()
+		FROM users u
+		WHERE f.user_id = u.id
+		  AND f.id = $1
+		  AND u.email = $2
+		  AND f.deleted_at IS NULL
+		  AND f.status_id IN ($4, $5);
+    `
+	result, err := f.dbClient.ExecContext(ctx, query, id, userEmail, domain.FileStatusCancelled, domain.FileStatusReceived, domain.FileStatusProcessing)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// CancelFilesByUser cancela os arquivos do usuário que estão aguardando ou em processamento e retorna os seus ids
+func (f *filesRepositoryImpl) CancelFilesByUser(ctx context.Context, userId uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+        UPDATE files
+		SET status_id=$2, processing_result='cancelado pela remoção da conta', updated_at=now()
+		WHERE user_id=$1
+		  AND status_id IN ($3, $4)
+		RETURNING id;
+    `
+	rows, err := f.dbClient.QueryContext(ctx, query, userId, domain.FileStatusCancelled, domain.FileStatusReceived, domain.FileStatusProcessing)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	cancelled := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		cancelled = append(cancelled, id)
+	}
+	return cancelled, rows.Err()
+}
+
+// ListCancelledFiles retorna, dentre os ids informados, os arquivos que foram cancelados
+func (f *filesRepositoryImpl) ListCancelledFiles(ctx context.Context, ids []uuid.UUID) ([]uuid.UUID, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	values := make([]string, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, id.String())
+	}
+	rows, err := f.dbClient.QueryContext(ctx, `SELECT id FROM files WHERE id = ANY($1::uuid[]) AND status_id = $2;`, pq.Array(values), domain.FileStatusCancelled)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	cancelled := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		cancelled = append(cancelled, id)
+	}
+	return cancelled, rows.Err()
+}
+
+// SoftDeleteFile marca o arquivo como removido, mantendo a data da primeira remoção
+func (f *filesRepositoryImpl) SoftDeleteFile(ctx context.Context, id uuid.UUID) error {
+	_, err := f.dbClient.ExecContext(ctx, `UPDATE files SET deleted_at=COALESCE(deleted_at, now()), updated_at=now() WHERE id=$1;`, id)
+	return err
+}
+
+// CountActiveFilesByVideoPath conta os arquivos não removidos que usam o mesmo video, ignorando o id informado
+func (f *filesRepositoryImpl) CountActiveFilesByVideoPath(ctx context.Context, videoFilePath string, ignoredId uuid.UUID) (int, error) {
+	var count int
+	err := f.dbClient.QueryRowContext(ctx,
+		`SELECT count(*) FROM files WHERE video_file_path=$1 AND id <> $2 AND deleted_at IS NULL;`,
+		videoFilePath,
+		ignoredId,
+	).Scan(&count)
+	return count, err
+}
+
+// ListAllFilesByUser lista todos os arquivos do usuário, incluindo os removidos
+func (f *filesRepositoryImpl) ListAllFilesByUser(ctx context.Context, userId uuid.UUID) ([]*domain.File, error) {
+	query := `
+       SELECT ` + fileColumns + `
+		FROM files f, file_status s
+		WHERE f.status_id = s.id
+		  AND f.user_id = $1
+		ORDER BY f.created_at;
+	`
+	rows, err := f.dbClient.QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	files := make([]*domain.File, 0)
+	for rows.Next() {
+		file, err := scanFile(rows)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, rows.Err()
+}
+
+func (f *filesRepositoryImpl) DeleteFilesByUser(ctx context.Context, userId uuid.UUID) (int64, error) {
+	result, err := f.dbClient.ExecContext(ctx, `DELETE FROM files WHERE user_id=$1;`, userId)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ListExpiredZipFiles lista os arquivos cujo ZIP passou do prazo de retenção do usuário ou do prazo padrão
+func (f *filesRepositoryImpl) ListExpiredZipFiles(ctx context.Context, defaultRetentionDays int, limit int) ([]*domain.File, error) {
+	query := `
+       SELECT ` + fileColumns + `
+		FROM files f
+		JOIN file_status s ON f.status_id = s.id
+		LEFT JOIN user_retention_policies p ON p.user_id = f.user_id
+		WHERE f.zip_file_path IS NOT NULL
+		  AND f.deleted_at IS NULL
+		  AND f.status_id = $3
+		  AND COALESCE(p.zip_retention_days, $1) > 0
+		  AND COALESCE(f.updated_at, f.created_at) < now() - make_interval(days => COALESCE(p.zip_retention_days, $1)::int)
+		ORDER BY f.created_at
+		LIMIT $2;
+	`
+	rows, err := f.dbClient.QueryContext(ctx, query, defaultRetentionDays, limit, domain.FileStatusDone)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	files := make([]*domain.File, 0)
+	for rows.Next() {
+		file, err := scanFile(rows)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, rows.Err()
+}
+
+func (f *filesRepositoryImpl) MarkZipExpired(ctx context.Context, id uuid.UUID) error {
+	query := `
+        UPDATE files
+		SET status_id=$2, zip_file_path=NULL, zip_file_size=NULL, zip_expired_at=now(), updated_at=now(),
+		    processing_result='arquivo zip expirado pela política de retenção'
+		WHERE id=$1;
+    `
+	_, err := f.dbClient.ExecContext(ctx, query, id, domain.FileStatusExpired)
+	return err
+}
+
+// ListExpiredVideos agrupa por video os arquivos cuja versão mais recente passou do prazo de retenção,
+// ignorando videos que ainda possuem versões aguardando ou em processamento
+func (f *filesRepositoryImpl) ListExpiredVideos(ctx context.Context, defaultRetentionDays int, limit int) ([]*domain.ExpiredVideo, error) {
+	query := `
+       SELECT f.video_file_path, max(f.video_file_size), array_agg(f.id::text)
+		FROM files f
+		LEFT JOIN user_retention_policies p ON p.user_id = f.user_id
+		WHERE f.video_expired_at IS NULL
+		GROUP BY f.video_file_path, p.video_retention_days
+		HAVING COALESCE(p.video_retention_days, $1) > 0
+		   AND max(f.created_at) < now() - make_interval(days => COALESCE(p.video_retention_days, $1)::int)
+		   AND bool_and(f.status_id NOT IN ($3, $4))
+		LIMIT $2;
+	`
+	rows, err := f.dbClient.QueryContext(ctx, query, defaultRetentionDays, limit, domain.FileStatusReceived, domain.FileStatusProcessing)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	videos := make([]*domain.ExpiredVideo, 0)
+	for rows.Next() {
+		var video domain.ExpiredVideo
+		var ids []string
+		if err := rows.Scan(&video.VideoFilePath, &video.VideoFileSize, pq.Array(&ids)); err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			video.FileIDs = append(video.FileIDs, uuid.MustParse(id))
+		}
+		videos = append(videos, &video)
+	}
+	return videos, rows.Err()
+}
+
+func (f *filesRepositoryImpl) MarkVideoExpired(ctx context.Context, ids []uuid.UUID) error {
+	values := make([]string, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, id.String())
+	}
+	_, err := f.dbClient.ExecContext(ctx, `UPDATE files SET video_expired_at=now(), updated_at=now() WHERE id = ANY($1::uuid[]);`, pq.Array(values))
+	return err
+}
+
+// userUsageQuery calcula o consumo do usuário, desconsiderando os arquivos rejeitados. O armazenamento
+// soma os arquivos zip e cada video uma única vez, já que ele é compartilhado entre as versões
+const userUsageQuery = `
+       SELECT
+		  (SELECT count(*) FROM files WHERE user_id = $1 AND status_id IN ($2, $3)),
+		  (SELECT count(*) FROM files WHERE user_id = $1 AND status_id <> $4 AND created_at >= date_trunc('day', now())),
+		  (SELECT COALESCE(sum(video_file_size), 0) FROM files
+		    WHERE user_id = $1 AND status_id <> $4 AND created_at >= date_trunc('month', now())),
+		  (SELECT COALESCE(sum(zip_file_size), 0) FROM files
+		    WHERE user_id = $1 AND deleted_at IS NULL AND zip_file_path IS NOT NULL)
+		  + (SELECT COALESCE(sum(video_size), 0) FROM (
+		       SELECT max(video_file_size) AS video_size FROM files
+		        WHERE user_id = $1 AND status_id <> $4 AND video_expired_at IS NULL
+		        GROUP BY video_file_path
+		       HAVING bool_or(deleted_at IS NULL)) videos);
+	`
+
+// GetUserUsage calcula o consumo atual do usuário
+func (f *filesRepositoryImpl) GetUserUsage(ctx context.Context, userId uuid.UUID) (*domain.QuotaUsage, error) {
+	return scanUserUsage(f.dbClient.QueryRowContext(ctx, userUsageQuery, userId, domain.FileStatusReceived, domain.FileStatusProcessing, domain.FileStatusRejected))
+}
+
+// lockUserUsage bloqueia o usuário até o fim da transação e calcula o seu consumo, que não muda por
+// outra gravação de arquivo do mesmo usuário enquanto o bloqueio for mantido
+func lockUserUsage(ctx context.Context, tx *sql.Tx, userId uuid.UUID) (*domain.QuotaUsage, error) {
+	if _, err := tx.ExecContext(ctx, `SELECT id FROM users WHERE id = $1 FOR UPDATE;`, userId); err != nil {
+		return nil, err
+	}
+	return scanUserUsage(tx.QueryRowContext(ctx, userUsageQuery, userId, domain.FileStatusReceived, domain.FileStatusProcessing, domain.FileStatusRejected))
+}
+
+func scanUserUsage(row rowScanner) (*domain.QuotaUsage, error) {
+	var usage domain.QuotaUsage
+	err := row.Scan(
+		&usage.ConcurrentJobs,
+		&usage.JobsToday,
+		&usage.BytesThisMonth,
+		&usage.StoredBytes,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/repositories"
+	databaseconnection "github.com/backstagefood/video-processor-worker/pkg/adapter/postgres"
+	"github.com/google/uuid"
+)
+
+type userPlansRepositoryImpl struct {
+	dbClient *sql.DB
+}
+
+func NewUserPlansRepository(db *databaseconnection.ApplicationDatabase) repositories.UserPlansRepository {
+	return &userPlansRepositoryImpl{
+		dbClient: db.Client(),
+	}
+}
+
+func (u *userPlansRepositoryImpl) FindPlanByUserID(ctx context.Context, userId uuid.UUID) (*domain.UserPlan, error) {
+	query := `
+        SELECT plan_name, max_concurrent_jobs, max_jobs_per_day, max_bytes_per_month, max_stored_bytes, priority_class
+        FROM user_plans
+        WHERE user_id = $1
+    `
+	var plan domain.UserPlan
+	err := u.dbClient.QueryRowContext(ctx, query, userId).Scan(
+		&plan.PlanName,
+		&plan.MaxConcurrentJobs,
+		&plan.MaxJobsPerDay,
+		&plan.MaxBytesPerMonth,
+		&plan.MaxStoredBytes,
+		&plan.PriorityClass,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrUserPlanNotFound
+		}
+		return nil, err
+	}
+	return &plan, nil
+}
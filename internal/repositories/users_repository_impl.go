@@ -1,8 +1,11 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"github.com/google/uuid"
 	"log/slog"
 
 	"github.com/backstagefood/video-processor-worker/internal/domain"
@@ -20,14 +23,14 @@ func NewUsersRepository(db *databaseconnection.ApplicationDatabase) repositories
 	}
 }
 
-func (v *usersRepositoryImpl) FindUserByEmail(email string) (*domain.User, error) {
+func (v *usersRepositoryImpl) FindUserByEmail(ctx context.Context, email string) (*domain.User, error) {
 	query := `
         select id, name, email, created_at, updated_at 
         from users 
         where email = $1
     `
 	var user domain.User
-	err := v.dbClient.QueryRow(
+	err := v.dbClient.QueryRowContext(ctx,
 		query,
 		email,
 	).Scan(
@@ -41,7 +44,7 @@ func (v *usersRepositoryImpl) FindUserByEmail(email string) (*domain.User, error
 	if err != nil {
 		slog.Error("usuário não localizado", "email", email, "error", err)
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errors.New("usuário não localizado para o email " + email)
+			return nil, fmt.Errorf("%w para o email %s", domain.ErrUserNotFound, email)
 		}
 		return nil, err
 	}
@@ -49,3 +52,14 @@ func (v *usersRepositoryImpl) FindUserByEmail(email string) (*domain.User, error
 	return &user, nil
 
 }
+
+// AnonymizeUser substitui os dados pessoais do usuário, mantendo o registro para as referências existentes
+func (v *usersRepositoryImpl) AnonymizeUser(ctx context.Context, id uuid.UUID) error {
+	query := `
+        UPDATE users
+        SET name = 'usuário removido', email = 'removido-' || id || '@anonimo.invalid', updated_at = now()
+        WHERE id = $1
+    `
+	_, err := v.dbClient.ExecContext(ctx, query, id)
+	return err
+}
@@ -0,0 +1,236 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	portRepositories "github.com/backstagefood/video-processor-worker/internal/domain/interface/repositories"
+	portServices "github.com/backstagefood/video-processor-worker/internal/domain/interface/services"
+	"github.com/backstagefood/video-processor-worker/utils"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type accountDataService struct {
+	accountRequestsRepository portRepositories.AccountRequestsRepository
+	usersRepository           portRepositories.UsersRepository
+	filesRepository           portRepositories.FilesRepository
+	bucketRepository          portRepositories.BucketRepository
+	jobRegistry               portServices.JobRegistry
+	backgroundTasks           portServices.BackgroundTasks
+	exportLinkExpiration      time.Duration
+	cancellationPollInterval  time.Duration
+}
+
+func NewAccountDataService(
+	accountRequestsRepository portRepositories.AccountRequestsRepository,
+	usersRepository portRepositories.UsersRepository,
+	filesRepository portRepositories.FilesRepository,
+	bucketRepository portRepositories.BucketRepository,
+	jobRegistry portServices.JobRegistry,
+	backgroundTasks portServices.BackgroundTasks,
+	exportLinkExpiration time.Duration,
+	cancellationPollInterval time.Duration,
+) portServices.AccountDataService {
+	return &accountDataService{
+		accountRequestsRepository: accountRequestsRepository,
+		usersRepository:           usersRepository,
+		filesRepository:           filesRepository,
+		bucketRepository:          bucketRepository,
+		jobRegistry:               jobRegistry,
+		backgroundTasks:           backgroundTasks,
+		exportLinkExpiration:      exportLinkExpiration,
+		cancellationPollInterval:  cancellationPollInterval,
+	}
+}
+
+func (a *accountDataService) RequestErasure(ctx context.Context, userEmail string) (*domain.AccountRequest, error) {
+	return a.startRequest(ctx, userEmail, domain.AccountRequestErasure, a.eraseAccount)
+}
+
+func (a *accountDataService) RequestExport(ctx context.Context, userEmail string) (*domain.AccountRequest, error) {
+	return a.startRequest(ctx, userEmail, domain.AccountRequestExport, a.exportAccount)
+}
+
+func (a *accountDataService) GetRequest(ctx context.Context, id uuid.UUID) (*domain.AccountRequest, error) {
+	return a.accountRequestsRepository.FindAccountRequestByID(ctx, id)
+}
+
+// startRequest grava a solicitação e a executa em segundo plano, registrando o progresso na tabela account_requests
+func (a *accountDataService) startRequest(ctx context.Context, userEmail, kind string, run func(ctx context.Context, request *domain.AccountRequest) error) (*domain.AccountRequest, error) {
+	if _, err := a.usersRepository.FindUserByEmail(ctx, userEmail); err != nil {
+		return nil, err
+	}
+	request := &domain.AccountRequest{UserEmail: userEmail, Kind: kind, Status: domain.AccountRequestPending}
+	if _, err := a.accountRequestsRepository.CreateAccountRequest(ctx, request); err != nil {
+		return nil, err
+	}
+
+	// a solicitação continua depois do fim da requisição, mantendo apenas o trace de origem, e é
+	// interrompida quando o desligamento não a aguarda até o fim
+	requestSpan := trace.SpanContextFromContext(ctx)
+	accepted := *request
+	a.backgroundTasks.Go(func(taskCtx context.Context) {
+		runCtx := trace.ContextWithSpanContext(taskCtx, requestSpan)
+		// o progresso final é gravado mesmo depois da interrupção
+		statusCtx := context.WithoutCancel(runCtx)
+		request := accepted
+		slog.Info("iniciando solicitação de dados da conta", "requestId", request.ID, "kind", request.Kind)
+		request.Status = domain.AccountRequestRunning
+		a.updateProgress(statusCtx, &request)
+
+		if err := run(runCtx, &request); err != nil {
+			slog.Error("não foi possível concluir a solicitação de dados da conta", "requestId", request.ID, "error", err)
+			message := err.Error()
+			if cause := context.Cause(runCtx); cause != nil {
+				message = cause.Error()
+			}
+			request.Status = domain.AccountRequestFailed
+			request.ErrorMessage = &message
+		} else {
+			request.Status = domain.AccountRequestDone
+		}
+		a.updateProgress(statusCtx, &request)
+		slog.Info("solicitação de dados da conta finalizada", "requestId", request.ID, "status", request.Status,
+			"objectsProcessed", request.ObjectsProcessed, "rowsProcessed", request.RowsProcessed)
+	})
+
+	return request, nil
+}
+
+func (a *accountDataService) updateProgress(ctx context.Context, request *domain.AccountRequest) {
+	if err := a.accountRequestsRepository.UpdateAccountRequestProgress(ctx, request); err != nil {
+		slog.Error("não foi possível atualizar o progresso da solicitação", "requestId", request.ID, "error", err)
+	}
+}
+
+// eraseAccount remove todos os objetos sob o prefixo do usuário, os registros de arquivos e anonimiza o usuário
+// e as suas solicitações
+func (a *accountDataService) eraseAccount(ctx context.Context, request *domain.AccountRequest) error {
+	user, err := a.usersRepository.FindUserByEmail(ctx, request.UserEmail)
+	if err != nil {
+		return err
+	}
+
+	if err := a.cancelJobs(ctx, user.ID); err != nil {
+		return err
+	}
+
+	// os objetos são removidos antes dos registros para que uma nova solicitação conclua a remoção
+	err = a.bucketRepository.ListFiles(ctx, userPrefix(request.UserEmail), func(filesWithPath []string) error {
+		if err := a.bucketRepository.DeleteFiles(ctx, filesWithPath); err != nil {
+			return err
+		}
+		request.ObjectsProcessed += int64(len(filesWithPath))
+		a.updateProgress(ctx, request)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	deletedRows, err := a.filesRepository.DeleteFilesByUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	// as solicitações são anonimizadas antes do usuário: depois dele uma nova solicitação não encontraria
+	// o email, e uma falha deixaria o dado pessoal nas solicitações
+	if err := a.accountRequestsRepository.AnonymizeAccountRequests(ctx, request.UserEmail, user.ID); err != nil {
+		return err
+	}
+	if err := a.usersRepository.AnonymizeUser(ctx, user.ID); err != nil {
+		return err
+	}
+	request.RowsProcessed = deletedRows + 1
+	return nil
+}
+
+// cancelJobs cancela os processamentos do usuário que aguardam ou estão em execução, para que nenhum
+// processamento grave um novo arquivo depois da remoção. Os processamentos de outras réplicas são
+// interrompidos pelo watcher de cancelamentos, aguardado por um intervalo da sua consulta
+func (a *accountDataService) cancelJobs(ctx context.Context, userId uuid.UUID) error {
+	cancelledIds, err := a.filesRepository.CancelFilesByUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+	remote := 0
+	for _, id := range cancelledIds {
+		if !a.jobRegistry.Cancel(id) {
+			remote++
+		}
+	}
+	if len(cancelledIds) == 0 {
+		return nil
+	}
+	slog.Info("processamentos do usuário cancelados para a remoção da conta", "cancelled", len(cancelledIds), "otherReplicas", remote)
+	if remote == 0 {
+		return nil
+	}
+	select {
+	case <-time.After(a.cancellationPollInterval):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// exportAccount gera um pacote JSON com os dados do usuário e links temporários para os seus objetos
+func (a *accountDataService) exportAccount(ctx context.Context, request *domain.AccountRequest) error {
+	user, err := a.usersRepository.FindUserByEmail(ctx, request.UserEmail)
+	if err != nil {
+		return err
+	}
+	files, err := a.filesRepository.ListAllFilesByUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	request.RowsProcessed = int64(len(files)) + 1
+
+	linkExpiration := a.exportLinkExpiration
+	exportDir := filepath.ToSlash(filepath.Join(userPrefix(request.UserEmail), "exports"))
+	objects := make([]domain.ExportedObject, 0)
+	err = a.bucketRepository.ListFiles(ctx, userPrefix(request.UserEmail), func(filesWithPath []string) error {
+		for _, fileWithPath := range filesWithPath {
+			// exportações anteriores não fazem parte dos dados do usuário
+			if filepath.Dir(fileWithPath) == exportDir {
+				continue
+			}
+			url, err := a.bucketRepository.PresignDownloadURL(fileWithPath, linkExpiration)
+			if err != nil {
+				return err
+			}
+			objects = append(objects, domain.ExportedObject{Key: fileWithPath, URL: url})
+		}
+		request.ObjectsProcessed += int64(len(filesWithPath))
+		a.updateProgress(ctx, request)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	bundle, err := json.MarshalIndent(domain.AccountExport{User: user, Files: files, Objects: objects, GeneratedAt: time.Now()}, "", "  ")
+	if err != nil {
+		return err
+	}
+	exportFileName := fmt.Sprintf("export_%s.json", request.ID)
+	exportFilePath, err := a.bucketRepository.CreateFile(ctx, exportDir, exportFileName, utils.NewInMemoryFile(bundle))
+	if err != nil {
+		return err
+	}
+	exportURL, err := a.bucketRepository.PresignDownloadURL(exportFilePath, linkExpiration)
+	if err != nil {
+		return err
+	}
+	request.ExportURL = &exportURL
+	return nil
+}
+
+func userPrefix(userEmail string) string {
+	return utils.SanitizeEmailForPath(userEmail) + "/"
+}
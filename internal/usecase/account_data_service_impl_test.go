@@ -0,0 +1,197 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	portRepositories "github.com/backstagefood/video-processor-worker/internal/domain/interface/repositories"
+	"github.com/google/uuid"
+)
+
+// accountEvents registra a ordem das operações da remoção da conta
+type accountEvents struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (e *accountEvents) add(event string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, event)
+}
+
+func (e *accountEvents) list() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.events...)
+}
+
+type accountUsersRepository struct {
+	portRepositories.UsersRepository
+	user   *domain.User
+	events *accountEvents
+}
+
+func (r accountUsersRepository) FindUserByEmail(_ context.Context, email string) (*domain.User, error) {
+	if r.user == nil || r.user.Email != email {
+		return nil, fmt.Errorf("%w para o email %s", domain.ErrUserNotFound, email)
+	}
+	return r.user, nil
+}
+
+func (r accountUsersRepository) AnonymizeUser(context.Context, uuid.UUID) error {
+	r.events.add("anonymize")
+	return nil
+}
+
+type accountFilesRepository struct {
+	portRepositories.FilesRepository
+	running []uuid.UUID
+	events  *accountEvents
+}
+
+func (r accountFilesRepository) CancelFilesByUser(context.Context, uuid.UUID) ([]uuid.UUID, error) {
+	r.events.add("cancel")
+	return r.running, nil
+}
+
+func (r accountFilesRepository) ListAllFilesByUser(context.Context, uuid.UUID) ([]*domain.File, error) {
+	return nil, nil
+}
+
+func (r accountFilesRepository) DeleteFilesByUser(context.Context, uuid.UUID) (int64, error) {
+	r.events.add("delete")
+	return int64(len(r.running)), nil
+}
+
+type accountBucketRepository struct {
+	portRepositories.BucketRepository
+	// listing bloqueia a listagem dos objetos até o contexto ser cancelado
+	listing bool
+}
+
+func (r accountBucketRepository) ListFiles(ctx context.Context, _ string, _ func([]string) error) error {
+	if r.listing {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return nil
+}
+
+type accountRequestsRepository struct {
+	portRepositories.AccountRequestsRepository
+	updates chan domain.AccountRequest
+	events  *accountEvents
+}
+
+func (r accountRequestsRepository) CreateAccountRequest(_ context.Context, request *domain.AccountRequest) (*uuid.UUID, error) {
+	request.ID = uuid.New()
+	return &request.ID, nil
+}
+
+func (r accountRequestsRepository) UpdateAccountRequestProgress(_ context.Context, request *domain.AccountRequest) error {
+	r.updates <- *request
+	return nil
+}
+
+func (r accountRequestsRepository) AnonymizeAccountRequests(_ context.Context, userEmail string, _ uuid.UUID) error {
+	r.events.add("anonymize-requests:" + userEmail)
+	return nil
+}
+
+func waitAccountRequest(t *testing.T, updates <-chan domain.AccountRequest) domain.AccountRequest {
+	t.Helper()
+	for {
+		select {
+		case request := <-updates:
+			if request.Status == domain.AccountRequestDone || request.Status == domain.AccountRequestFailed {
+				return request
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected the account request to finish")
+		}
+	}
+}
+
+func TestAccountDataServiceRejectsUnknownUser(t *testing.T) {
+	service := NewAccountDataService(
+		accountRequestsRepository{},
+		accountUsersRepository{},
+		accountFilesRepository{},
+		accountBucketRepository{},
+		NewJobRegistry(),
+		NewBackgroundTasks(),
+		time.Hour,
+		time.Millisecond,
+	)
+
+	if _, err := service.RequestErasure(context.Background(), "unknown@test.com"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("Expected user not found error, got %v", err)
+	}
+}
+
+func TestAccountDataServiceCancelsJobsBeforeErasure(t *testing.T) {
+	events := &accountEvents{}
+	local, remote := uuid.New(), uuid.New()
+	jobRegistry := NewJobRegistry()
+	jobCtx, cancelJob := context.WithCancel(context.Background())
+	jobRegistry.Register(local, cancelJob)
+	updates := make(chan domain.AccountRequest, 10)
+	service := NewAccountDataService(
+		accountRequestsRepository{updates: updates, events: events},
+		accountUsersRepository{user: &domain.User{ID: uuid.New(), Email: "user@test.com"}, events: events},
+		accountFilesRepository{running: []uuid.UUID{local, remote}, events: events},
+		accountBucketRepository{},
+		jobRegistry,
+		NewBackgroundTasks(),
+		time.Hour,
+		time.Millisecond,
+	)
+
+	if _, err := service.RequestErasure(context.Background(), "user@test.com"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if request := waitAccountRequest(t, updates); request.Status != domain.AccountRequestDone {
+		t.Fatalf("Expected erasure to finish, got %+v", request)
+	}
+	if jobCtx.Err() == nil {
+		t.Error("Expected the local job to be cancelled")
+	}
+	// o email também sai das solicitações, inclusive da própria remoção
+	if got := events.list(); fmt.Sprint(got) != "[cancel delete anonymize-requests:user@test.com anonymize]" {
+		t.Errorf("Expected jobs to be cancelled before the erasure and the requests to be anonymized, got %v", got)
+	}
+}
+
+func TestAccountDataServiceInterruptsRequestsOnShutdown(t *testing.T) {
+	updates := make(chan domain.AccountRequest, 10)
+	backgroundTasks := NewBackgroundTasks()
+	service := NewAccountDataService(
+		accountRequestsRepository{updates: updates},
+		accountUsersRepository{user: &domain.User{ID: uuid.New(), Email: "user@test.com"}},
+		accountFilesRepository{},
+		accountBucketRepository{listing: true},
+		NewJobRegistry(),
+		backgroundTasks,
+		time.Hour,
+		time.Millisecond,
+	)
+
+	if _, err := service.RequestExport(context.Background(), "user@test.com"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := backgroundTasks.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected shutdown to stop waiting at the deadline, got %v", err)
+	}
+	request := waitAccountRequest(t, updates)
+	if request.Status != domain.AccountRequestFailed || request.ErrorMessage == nil || *request.ErrorMessage != domain.ErrShuttingDown.Error() {
+		t.Errorf("Expected request to be interrupted by the shutdown, got %+v", request)
+	}
+}
@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	portServices "github.com/backstagefood/video-processor-worker/internal/domain/interface/services"
+)
+
+// backgroundTasks acompanha as tarefas em segundo plano, que recebem um contexto cancelado no desligamento
+type backgroundTasks struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	tasks  sync.WaitGroup
+}
+
+func NewBackgroundTasks() portServices.BackgroundTasks {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	return &backgroundTasks{ctx: ctx, cancel: cancel}
+}
+
+func (b *backgroundTasks) Go(run func(ctx context.Context)) {
+	b.tasks.Add(1)
+	go func() {
+		defer b.tasks.Done()
+		run(b.ctx)
+	}()
+}
+
+// Shutdown aguarda as tarefas em execução até o fim do prazo do contexto e então as interrompe
+func (b *backgroundTasks) Shutdown(ctx context.Context) error {
+	finished := make(chan struct{})
+	go func() {
+		b.tasks.Wait()
+		close(finished)
+	}()
+
+	var err error
+	select {
+	case <-finished:
+	case <-ctx.Done():
+		slog.Warn("prazo de desligamento esgotado, interrompendo as tarefas em segundo plano")
+		b.cancel(domain.ErrShuttingDown)
+		<-finished
+		err = ctx.Err()
+	}
+	b.cancel(domain.ErrShuttingDown)
+	return err
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+)
+
+const (
+	// cloudEventsHeaderPrefix é o prefixo dos atributos no modo binário do binding kafka
+	cloudEventsHeaderPrefix = "ce_"
+	contentTypeHeader       = "content-type"
+	cloudEventsContentType  = "application/cloudevents+json"
+)
+
+// structuredCloudEvent é o formato JSON do modo estruturado
+type structuredCloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject"`
+	Time            string          `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	DataSchema      string          `json:"dataschema"`
+	Data            json.RawMessage `json:"data"`
+	DataBase64      string          `json:"data_base64"`
+}
+
+// parseCloudEvent lê a mensagem como um CloudEvent no modo binário, quando ela possui o header
+// ce_specversion, ou no modo estruturado, pelo content-type ou pelo atributo specversion no corpo.
+// Retorna nil para as mensagens que não são CloudEvents
+func parseCloudEvent(message *adapters.Message) (*domain.CloudEvent, error) {
+	if specVersion := message.Header(cloudEventsHeaderPrefix + "specversion"); specVersion != "" {
+		event := &domain.CloudEvent{
+			SpecVersion:     specVersion,
+			ID:              message.Header(cloudEventsHeaderPrefix + "id"),
+			Source:          message.Header(cloudEventsHeaderPrefix + "source"),
+			Type:            message.Header(cloudEventsHeaderPrefix + "type"),
+			Subject:         message.Header(cloudEventsHeaderPrefix + "subject"),
+			Time:            message.Header(cloudEventsHeaderPrefix + "time"),
+			DataContentType: message.Header(contentTypeHeader),
+			DataSchema:      message.Header(cloudEventsHeaderPrefix + "dataschema"),
+			Data:            message.Value,
+		}
+		return event, validateCloudEvent(event)
+	}
+
+	if !isStructuredCloudEvent(message) {
+		return nil, nil
+	}
+	var structured structuredCloudEvent
+	if err := json.Unmarshal(message.Value, &structured); err != nil {
+		return nil, fmt.Errorf("%w: cloudevent estruturado inválido: %v", domain.ErrInvalidPayload, err)
+	}
+	event := &domain.CloudEvent{
+		SpecVersion:     structured.SpecVersion,
+		ID:              structured.ID,
+		Source:          structured.Source,
+		Type:            structured.Type,
+		Subject:         structured.Subject,
+		Time:            structured.Time,
+		DataContentType: structured.DataContentType,
+		DataSchema:      structured.DataSchema,
+		Data:            structured.Data,
+	}
+	if structured.DataBase64 != "" {
+		if len(structured.Data) > 0 {
+			return nil, fmt.Errorf("%w: cloudevent com data e data_base64", domain.ErrInvalidPayload)
+		}
+		data, err := base64.StdEncoding.DecodeString(structured.DataBase64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: data_base64 inválido: %v", domain.ErrInvalidPayload, err)
+		}
+		event.Data = data
+	}
+	return event, validateCloudEvent(event)
+}
+
+func isStructuredCloudEvent(message *adapters.Message) bool {
+	if strings.HasPrefix(message.Header(contentTypeHeader), cloudEventsContentType) {
+		return true
+	}
+	if !bytes.HasPrefix(bytes.TrimSpace(message.Value), []byte("{")) {
+		return false
+	}
+	var attributes struct {
+		SpecVersion *string `json:"specversion"`
+	}
+	return json.Unmarshal(message.Value, &attributes) == nil && attributes.SpecVersion != nil
+}
+
+// validateCloudEvent confere os atributos obrigatórios da especificação
+func validateCloudEvent(event *domain.CloudEvent) error {
+	if event.SpecVersion != domain.CloudEventsSpecVersion {
+		return fmt.Errorf("%w: specversion %q não suportada", domain.ErrInvalidPayload, event.SpecVersion)
+	}
+	var missing []string
+	for _, attribute := range []struct{ name, value string }{{"id", event.ID}, {"source", event.Source}, {"type", event.Type}} {
+		if attribute.value == "" {
+			missing = append(missing, attribute.name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: cloudevent sem os atributos obrigatórios %s", domain.ErrInvalidPayload, strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package usecase
+
+import (
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	"github.com/backstagefood/video-processor-worker/pkg/config"
+)
+
+func defaultExtractionOptions(settings config.ExtractionConfig) domain.ExtractionOptions {
+	return domain.ExtractionOptions{
+		FPS:          settings.FPS,
+		ImageQuality: settings.ImageQuality,
+	}
+}
@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	portRepositories "github.com/backstagefood/video-processor-worker/internal/domain/interface/repositories"
+	portServices "github.com/backstagefood/video-processor-worker/internal/domain/interface/services"
+	"github.com/backstagefood/video-processor-worker/pkg/config"
+	"github.com/google/uuid"
+)
+
+type filesService struct {
+	filesRepository  portRepositories.FilesRepository
+	bucketRepository portRepositories.BucketRepository
+	messageProducer  adapters.MessageProducer
+	quotaService     portServices.QuotaService
+	extraction       atomic.Pointer[config.ExtractionConfig]
+}
+
+func NewFilesService(
+	filesRepository portRepositories.FilesRepository,
+	bucketRepository portRepositories.BucketRepository,
+	messageProducer adapters.MessageProducer,
+	quotaService portServices.QuotaService,
+	extraction config.ExtractionConfig,
+) portServices.FilesService {
+	service := &filesService{
+		filesRepository:  filesRepository,
+		bucketRepository: bucketRepository,
+		messageProducer:  messageProducer,
+		quotaService:     quotaService,
+	}
+	service.ApplySettings(extraction)
+	return service
+}
+
+func (f *filesService) ApplySettings(extraction config.ExtractionConfig) {
+	f.extraction.Store(&extraction)
+}
+
+// ReprocessFile cria uma nova versão do arquivo a partir do video já armazenado e a envia para processamento
+func (f *filesService) ReprocessFile(ctx context.Context, userEmail string, fileId uuid.UUID, request domain.ReprocessRequest) (*domain.File, error) {
+	file, err := f.filesRepository.FindFileByID(ctx, fileId, userEmail)
+	if err != nil {
+		return nil, err
+	}
+	if file.IsDeleted() {
+		return nil, domain.ErrFileNotFound
+	}
+	if file.VideoExpiredAt != nil {
+		return nil, domain.ErrVideoExpired
+	}
+	if file.FileStatus.ID == domain.FileStatusReceived || file.FileStatus.ID == domain.FileStatusProcessing {
+		return nil, domain.ErrFileInProgress
+	}
+
+	options := request.ExtractionOptions.Merge(defaultExtractionOptions(*f.extraction.Load()))
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	quotaCheck, err := f.quotaService.QuotaCheck(ctx, file.UserID, file.VideoFileSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// todas as versões ficam ligadas ao arquivo original
+	parentFileId := file.RootID()
+	newFile := &domain.File{
+		UserID:            file.UserID,
+		VideoFilePath:     file.VideoFilePath,
+		VideoFileSize:     file.VideoFileSize,
+		FileStatus:        domain.FileStatus{ID: domain.FileStatusReceived},
+		ParentFileID:      &parentFileId,
+		ExtractionOptions: &options,
+	}
+	newFileId, quotaExceeded, err := f.filesRepository.CreateFileVersion(ctx, newFile, quotaCheck)
+	if err != nil {
+		return nil, err
+	}
+	if quotaExceeded != nil {
+		return nil, quotaExceeded
+	}
+
+	payload := domain.FilePayload{
+		UserName:          userEmail,
+		FilePath:          file.VideoFilePath,
+		FileSize:          file.VideoFileSize,
+		FileID:            newFileId,
+		Version:           newFile.Version,
+		ExtractionOptions: &options,
+	}
+	if request.ReplacePrevious {
+		payload.ReplaceFileID = &file.ID
+	}
+	message, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.messageProducer.PublishMessage(ctx, userEmail, message); err != nil {
+		slog.Error("não foi possível enviar o arquivo para reprocessamento", "fileId", newFileId, "error", err)
+		if updateErr := f.filesRepository.UpdateFileStatus(ctx, newFileId, domain.NewFileProcessingResultWithError("não foi possível enviar o arquivo para reprocessamento")); updateErr != nil {
+			slog.Error("não foi possível atualizar o status do arquivo", "fileId", newFileId, "error", updateErr)
+		}
+		return nil, err
+	}
+
+	slog.Info("arquivo enviado para reprocessamento", "fileId", newFileId, "parentFileId", parentFileId, "version", newFile.Version)
+	return newFile, nil
+}
+
+// DeleteFile remove o arquivo ZIP e, opcionalmente, o video do bucket e marca o registro como removido.
+// Repetir a remoção de um arquivo já removido não gera erro
+func (f *filesService) DeleteFile(ctx context.Context, userEmail string, fileId uuid.UUID, keepVideo bool) error {
+	file, err := f.filesRepository.FindFileByID(ctx, fileId, userEmail)
+	if err != nil {
+		return err
+	}
+	if file.FileStatus.ID == domain.FileStatusReceived || file.FileStatus.ID == domain.FileStatusProcessing {
+		return domain.ErrFileInProgress
+	}
+
+	// os objetos são removidos antes do registro para que uma nova tentativa conclua a remoção
+	if file.ZipFilePath != nil {
+		if err := f.bucketRepository.DeleteFile(ctx, *file.ZipFilePath); err != nil {
+			return err
+		}
+	}
+	if !keepVideo {
+		// o video é compartilhado entre as versões reprocessadas do arquivo
+		activeFiles, err := f.filesRepository.CountActiveFilesByVideoPath(ctx, file.VideoFilePath, file.ID)
+		if err != nil {
+			return err
+		}
+		if activeFiles == 0 {
+			if err := f.bucketRepository.DeleteFile(ctx, file.VideoFilePath); err != nil {
+				return err
+			}
+		} else {
+			slog.Info("video mantido pois é usado por outras versões do arquivo", "fileId", file.ID, "activeFiles", activeFiles)
+		}
+	}
+
+	if err := f.filesRepository.SoftDeleteFile(ctx, file.ID); err != nil {
+		return err
+	}
+	slog.Info("arquivo removido", "fileId", file.ID, "keepVideo", keepVideo)
+	return nil
+}
@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"context"
 	"github.com/backstagefood/video-processor-worker/internal/domain"
 	portRepositories "github.com/backstagefood/video-processor-worker/internal/domain/interface/repositories"
 	portServices "github.com/backstagefood/video-processor-worker/internal/domain/interface/services"
@@ -16,6 +17,6 @@ func NewFilesStatusService(filesRepository portRepositories.FilesRepository) por
 	}
 }
 
-func (f fileStatusService) ListFilesByEmail(userEmail string) ([]*domain.File, error) {
-	return f.filesRepository.ListFilesByEmail(userEmail)
+func (f fileStatusService) ListFilesByEmail(ctx context.Context, userEmail string) ([]*domain.File, error) {
+	return f.filesRepository.ListFilesByEmail(ctx, userEmail)
 }
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	portServices "github.com/backstagefood/video-processor-worker/internal/domain/interface/services"
+	"github.com/backstagefood/video-processor-worker/pkg/config"
+)
+
+type componentResult struct {
+	name   string
+	health domain.ComponentHealth
+}
+
+type healthService struct {
+	checks   []adapters.HealthCheck
+	timeout  time.Duration
+	cacheTTL time.Duration
+	draining atomic.Bool
+
+	// mu serializa as verificações: requisições simultâneas aguardam a verificação em andamento
+	mu        sync.Mutex
+	cached    map[string]domain.ComponentHealth
+	checkedAt time.Time
+}
+
+// NewHealthService verifica as dependências em paralelo, cada uma limitada por HEALTH_CHECK_TIMEOUT_MS.
+// O resultado é reaproveitado por HEALTH_CHECK_CACHE_MS, evitando que as sondas sobrecarreguem as dependências
+func NewHealthService(checks []adapters.HealthCheck, settings config.HealthConfig) portServices.HealthService {
+	return &healthService{
+		checks:   checks,
+		timeout:  settings.CheckTimeout,
+		cacheTTL: settings.CacheTTL,
+	}
+}
+
+func (h *healthService) Liveness() *domain.HealthReport {
+	return &domain.HealthReport{Status: domain.HealthUp}
+}
+
+func (h *healthService) Readiness(ctx context.Context) *domain.HealthReport {
+	if h.draining.Load() {
+		return &domain.HealthReport{Status: domain.HealthDown, Draining: true}
+	}
+
+	components := h.checkComponents(ctx)
+	report := &domain.HealthReport{Status: domain.HealthUp, Components: components}
+	for _, component := range components {
+		if component.Status != domain.HealthUp {
+			report.Status = domain.HealthDown
+		}
+	}
+	return report
+}
+
+func (h *healthService) StartDraining() {
+	if h.draining.CompareAndSwap(false, true) {
+		slog.Info("desligamento iniciado, prontidão reportada como DOWN")
+	}
+}
+
+func (h *healthService) checkComponents(ctx context.Context) map[string]domain.ComponentHealth {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.cached != nil && time.Since(h.checkedAt) < h.cacheTTL {
+		return h.cached
+	}
+
+	results := make(chan componentResult, len(h.checks))
+	for _, check := range h.checks {
+		go func(check adapters.HealthCheck) {
+			results <- componentResult{name: check.Name(), health: h.runCheck(ctx, check)}
+		}(check)
+	}
+
+	components := make(map[string]domain.ComponentHealth, len(h.checks))
+	for range h.checks {
+		result := <-results
+		components[result.name] = result.health
+	}
+	h.cached, h.checkedAt = components, time.Now()
+	return components
+}
+
+// runCheck executa a verificação até o timeout; uma verificação que não responde no prazo é reportada
+// como DOWN, mesmo que continue executando em segundo plano
+func (h *healthService) runCheck(ctx context.Context, check adapters.HealthCheck) domain.ComponentHealth {
+	// a verificação não é interrompida quando a sonda que a disparou desiste, pois o resultado fica em cache
+	checkCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- check.Check(checkCtx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-checkCtx.Done():
+		err = checkCtx.Err()
+	}
+	health := domain.ComponentHealth{Status: domain.HealthUp, LatencyMs: time.Since(start).Milliseconds(), CheckedAt: start}
+	if err != nil {
+		slog.WarnContext(ctx, "dependência indisponível", "component", check.Name(), "error", err)
+		health.Status = domain.HealthDown
+		health.Error = err.Error()
+	}
+	return health
+}
@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	"github.com/backstagefood/video-processor-worker/pkg/config"
+)
+
+// fakeHealthCheck conta as verificações e responde após delay com err
+type fakeHealthCheck struct {
+	name  string
+	delay time.Duration
+	err   error
+	calls atomic.Int32
+}
+
+func (c *fakeHealthCheck) Name() string { return c.name }
+
+func (c *fakeHealthCheck) Check(ctx context.Context) error {
+	c.calls.Add(1)
+	select {
+	case <-time.After(c.delay):
+		return c.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func healthConfig(timeout, cacheTTL time.Duration) config.HealthConfig {
+	return config.HealthConfig{CheckTimeout: timeout, CacheTTL: cacheTTL}
+}
+
+func TestHealthServiceReportsComponents(t *testing.T) {
+	database := &fakeHealthCheck{name: "database"}
+	bucket := &fakeHealthCheck{name: "bucket", err: errors.New("bucket indisponível")}
+	service := NewHealthService([]adapters.HealthCheck{database, bucket}, healthConfig(100*time.Millisecond, 5*time.Second))
+
+	report := service.Readiness(context.Background())
+	if report.Status != domain.HealthDown {
+		t.Errorf("Expected status %s, got %s", domain.HealthDown, report.Status)
+	}
+	if report.Components["database"].Status != domain.HealthUp {
+		t.Errorf("Expected database to be up, got %+v", report.Components["database"])
+	}
+	if report.Components["bucket"].Status != domain.HealthDown || report.Components["bucket"].Error != "bucket indisponível" {
+		t.Errorf("Expected bucket to be down, got %+v", report.Components["bucket"])
+	}
+	if service.Liveness().Status != domain.HealthUp {
+		t.Errorf("Expected liveness to ignore the dependencies")
+	}
+}
+
+func TestHealthServiceRunsChecksInParallelWithTimeout(t *testing.T) {
+	checks := []adapters.HealthCheck{
+		&fakeHealthCheck{name: "database", delay: 50 * time.Millisecond},
+		&fakeHealthCheck{name: "bucket", delay: 50 * time.Millisecond},
+		&fakeHealthCheck{name: "kafka", delay: time.Second},
+	}
+	service := NewHealthService(checks, healthConfig(100*time.Millisecond, 5*time.Second))
+
+	start := time.Now()
+	report := service.Readiness(context.Background())
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected the checks to run in parallel, took %s", elapsed)
+	}
+	if report.Components["database"].Status != domain.HealthUp || report.Components["bucket"].Status != domain.HealthUp {
+		t.Errorf("Expected database and bucket to be up, got %+v", report.Components)
+	}
+	if report.Components["kafka"].Status != domain.HealthDown {
+		t.Errorf("Expected the slow check to time out, got %+v", report.Components["kafka"])
+	}
+}
+
+func TestHealthServiceCachesResults(t *testing.T) {
+	database := &fakeHealthCheck{name: "database"}
+	service := NewHealthService([]adapters.HealthCheck{database}, healthConfig(2*time.Second, time.Minute))
+
+	for i := 0; i < 3; i++ {
+		if report := service.Readiness(context.Background()); report.Status != domain.HealthUp {
+			t.Fatalf("Expected status %s, got %s", domain.HealthUp, report.Status)
+		}
+	}
+	if calls := database.calls.Load(); calls != 1 {
+		t.Errorf("Expected 1 check within the cache period, got %d", calls)
+	}
+}
+
+func TestHealthServiceReportsDownWhileDraining(t *testing.T) {
+	database := &fakeHealthCheck{name: "database"}
+	service := NewHealthService([]adapters.HealthCheck{database}, config.Default().Health)
+	service.StartDraining()
+
+	report := service.Readiness(context.Background())
+	if report.Status != domain.HealthDown || !report.Draining {
+		t.Errorf("Expected readiness to be down while draining, got %+v", report)
+	}
+	if service.Liveness().Status != domain.HealthUp {
+		t.Errorf("Expected liveness to stay up while draining")
+	}
+}
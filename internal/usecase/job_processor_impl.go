@@ -0,0 +1,832 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	portRepositories "github.com/backstagefood/video-processor-worker/internal/domain/interface/repositories"
+	portServices "github.com/backstagefood/video-processor-worker/internal/domain/interface/services"
+	"github.com/backstagefood/video-processor-worker/pkg/config"
+	"github.com/backstagefood/video-processor-worker/utils"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Resultados dos processamentos nas métricas. As rejeições na validação do video são falhas com o código
+// da rejeição como motivo; rejected identifica os arquivos recusados por cota no aceite
+const (
+	jobOutcomeDone        = "done"
+	jobOutcomeFailed      = "failed"
+	jobOutcomeRejected    = "rejected"
+	jobOutcomeCancelled   = "cancelled"
+	jobOutcomeInterrupted = "interrupted"
+)
+
+// Etapas do processamento medidas nas métricas
+const (
+	jobStageDownload   = "download"
+	jobStageExtraction = "extraction"
+	jobStageZip        = "zip"
+	jobStageUpload     = "upload"
+)
+
+func NewJobProcessor(
+	usersRepository portRepositories.UsersRepository,
+	filesRepository portRepositories.FilesRepository,
+	bucketRepository portRepositories.BucketRepository,
+	videoValidator portServices.VideoValidator,
+	quotaService portServices.QuotaService,
+	jobRegistry portServices.JobRegistry,
+	messageProducer adapters.MessageProducer,
+	jobRouter portServices.JobRouter,
+	payloadDecoder portServices.PayloadDecoder,
+	metrics adapters.Metrics,
+	settings config.WorkerConfig,
+	extraction config.ExtractionConfig,
+	email config.EmailConfig,
+	features config.FeatureFlags,
+) portServices.JobProcessor {
+	maxVideos := settings.MaxVideos
+	maxQueued := settings.MaxQueued
+	classWeights := settings.ClassWeights
+	slog.Info("job processor", "maxVideos", maxVideos, "maxQueued", maxQueued, "classWeights", classWeights)
+
+	processor := &jobProcessor{
+		usersRepository:  usersRepository,
+		filesRepository:  filesRepository,
+		bucketRepository: bucketRepository,
+		videoValidator:   videoValidator,
+		jobRegistry:      jobRegistry,
+		quotaService:     quotaService,
+		scheduler:        NewJobScheduler(classWeights, maxQueued, metrics),
+		messageProducer:  messageProducer,
+		jobRouter:        jobRouter,
+		payloadDecoder:   payloadDecoder,
+		metrics:          metrics,
+		email:            email,
+		pending:          make(map[uuid.UUID]pendingJob),
+	}
+	processor.settings.Store(&settings)
+	processor.extraction.Store(&extraction)
+	processor.features.Store(&features)
+	processor.pickupCtx, processor.stopPickup = context.WithCancel(context.Background())
+	processor.runCtx, processor.stopRuns = context.WithCancelCause(context.Background())
+	// o processador trata a extração de frames; os demais tipos registram os seus handlers no mesmo roteador
+	if err := registerVideoFramesPayload(payloadDecoder); err != nil {
+		panic(fmt.Sprintf("não foi possível registrar os schemas do payload: %v", err))
+	}
+	jobRouter.Register(domain.JobTypeVideoFrames, processor)
+
+	// os workers são compartilhados por todas as entregas e executam os processamentos na ordem do agendador
+	processor.resizeWorkers(maxVideos)
+	go processor.sendHeartbeats(processor.runCtx)
+	go processor.watchCancellations(processor.runCtx)
+	return processor
+}
+
+// jobProcessor recebe os processamentos de um JobSource, independente do transporte das mensagens
+type jobProcessor struct {
+	usersRepository  portRepositories.UsersRepository
+	filesRepository  portRepositories.FilesRepository
+	bucketRepository portRepositories.BucketRepository
+	videoValidator   portServices.VideoValidator
+	jobRegistry      portServices.JobRegistry
+	quotaService     portServices.QuotaService
+	scheduler        portServices.JobScheduler
+	messageProducer  adapters.MessageProducer
+	jobRouter        portServices.JobRouter
+	payloadDecoder   portServices.PayloadDecoder
+	metrics          adapters.Metrics
+	email            config.EmailConfig
+	paused           atomic.Bool
+	busyWorkers      atomic.Int32
+
+	// configuração recarregável, substituída por ApplySettings
+	settings   atomic.Pointer[config.WorkerConfig]
+	extraction atomic.Pointer[config.ExtractionConfig]
+	features   atomic.Pointer[config.FeatureFlags]
+
+	// poolMu protege o redimensionamento dos workers; cada worker tem o seu stop em workerStops e
+	// retiringWorkers conta os removidos que ainda não saíram, pois terminam o processamento em execução
+	poolMu          sync.Mutex
+	workerStops     []context.CancelFunc
+	retiringWorkers int
+	workerCapacity  atomic.Int32
+
+	// pickupCtx interrompe a retirada de processamentos do agendador e runCtx os processamentos em execução
+	pickupCtx  context.Context
+	stopPickup context.CancelFunc
+	runCtx     context.Context
+	stopRuns   context.CancelCauseFunc
+	workers    sync.WaitGroup
+
+	// processamentos aceitos que ainda não terminaram, devolvidos para a fila no desligamento
+	pendingMu sync.Mutex
+	pending   map[uuid.UUID]pendingJob
+}
+
+// pendingJob é um processamento aceito e ainda não terminado. Nos brokers com confirmação individual a
+// entrega só é confirmada quando ele termina ou volta para a fila
+type pendingJob struct {
+	payload  domain.FilePayload
+	delivery adapters.DeferredDelivery
+}
+
+// deferredAck guarda no contexto da mensagem a entrega cuja confirmação pode ser adiada para o fim do processamento
+type deferredAck struct {
+	delivery adapters.DeferredDelivery
+	taken    bool
+}
+
+type deferredAckKey struct{}
+
+// takeDeferredDelivery retorna a entrega da mensagem quando o broker confirma cada mensagem individualmente,
+// e indica ao handleDelivery que a confirmação fica a cargo do processamento
+func takeDeferredDelivery(ctx context.Context) adapters.DeferredDelivery {
+	deferred, ok := ctx.Value(deferredAckKey{}).(*deferredAck)
+	if !ok {
+		return nil
+	}
+	deferred.taken = true
+	return deferred.delivery
+}
+
+// Run recebe as entregas do source até o contexto ser cancelado e as entrega ao handler do seu tipo.
+// Cada entrega é confirmada depois de tratada, ou devolvida ao source quando o handler falhou
+func (p *jobProcessor) Run(ctx context.Context, source adapters.JobSource) error {
+	for {
+		delivery, err := source.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		p.handleDelivery(ctx, delivery)
+		p.applyBackpressure(source)
+	}
+}
+
+// handleDelivery trata a entrega no span da mensagem, continuando o trace recebido nos headers do produtor.
+// As entregas que o broker confirma individualmente e que geraram um processamento são confirmadas
+// apenas quando ele termina
+func (p *jobProcessor) handleDelivery(ctx context.Context, delivery adapters.Delivery) {
+	message := delivery.Message()
+	ctx, span := startMessageSpan(ctx, message)
+	deferred := &deferredAck{}
+	if deferredDelivery, ok := delivery.(adapters.DeferredDelivery); ok {
+		deferred.delivery = deferredDelivery
+		ctx = context.WithValue(ctx, deferredAckKey{}, deferred)
+	}
+	err := p.jobRouter.Route(ctx, message)
+	switch {
+	case err != nil:
+		slog.ErrorContext(ctx, "erro ao tratar a mensagem, devolvendo para a fila", "messageId", message.ID, "error", err)
+		err = delivery.Nack()
+	case deferred.taken:
+		slog.DebugContext(ctx, "confirmação da mensagem adiada até o fim do processamento", "messageId", message.ID)
+	default:
+		err = delivery.Ack()
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "não foi possível confirmar a mensagem", "messageId", message.ID, "error", err)
+	}
+	endSpan(span, err)
+}
+
+// HandleMessage grava o arquivo recebido e agenda o seu processamento. O erro indica uma falha
+// temporária, em que a mensagem deve ser entregue novamente
+func (p *jobProcessor) HandleMessage(ctx context.Context, message *adapters.Message) error {
+	var filePayload domain.FilePayload
+	if err := p.payloadDecoder.Decode(ctx, domain.JobTypeVideoFrames, message, &filePayload); err != nil {
+		// mensagens inválidas são enviadas ao dead letter pelo JobRouter
+		slog.ErrorContext(ctx, "não foi possível ler a mensagem recebida", slog.String("error", err.Error()))
+		return err
+	}
+	slog.InfoContext(ctx, "video recebido com sucesso", "filePayload", filePayload)
+
+	// o arquivo é gravado antes de confirmar a mensagem, assim uma queda entre os dois passos
+	// gera uma nova entrega, que é descartada pelo message id, e não a perda do processamento
+	fileId, priorityClass, err := p.insertFile(ctx, &filePayload, message.ID)
+	if err != nil || fileId == nil {
+		return err
+	}
+
+	payload := filePayload
+	p.trackPending(*fileId, pendingJob{payload: payload, delivery: takeDeferredDelivery(ctx)})
+	// o processamento é executado depois da confirmação da mensagem e continua o trace da mensagem
+	messageSpan := trace.SpanContextFromContext(ctx)
+	job := &domain.ScheduledJob{
+		UserKey:       payload.UserName,
+		PriorityClass: priorityClass,
+		Run: func(ctx context.Context) {
+			p.runJob(trace.ContextWithSpanContext(ctx, messageSpan), fileId, payload)
+		},
+	}
+	if err := p.scheduler.Submit(context.Background(), job); err != nil {
+		slog.ErrorContext(ctx, "não foi possível agendar o processamento", "fileId", fileId, "error", err)
+		p.atualizaStatus(ctx, fileId, domain.NewFileProcessingFailure(domain.FailureReasonScheduling, "não foi possível agendar o processamento"))
+		p.metrics.JobFinished(jobOutcomeFailed, domain.FailureReasonScheduling)
+		p.untrackPending(*fileId)
+	}
+	return nil
+}
+
+// applyBackpressure pausa a busca de mensagens do source enquanto o agendador estiver saturado,
+// retomando quando a fila baixar
+func (p *jobProcessor) applyBackpressure(flowController adapters.FlowController) {
+	if !p.scheduler.Saturated() {
+		return
+	}
+	// a pausa é reaplicada a cada mensagem pois as partições recebidas após um rebalance do kafka começam ativas
+	flowController.PauseAll()
+	if !p.paused.CompareAndSwap(false, true) {
+		return
+	}
+	slog.Info("workers ocupados, pausando o consumo das partições", "queued", p.scheduler.Depth())
+
+	go func() {
+		if err := p.scheduler.WaitForCapacity(context.Background()); err != nil {
+			slog.Error("erro ao aguardar workers livres", "error", err)
+		}
+		p.paused.Store(false)
+		flowController.ResumeAll()
+		slog.Info("workers livres, retomando o consumo das partições", "queued", p.scheduler.Depth())
+	}()
+}
+
+// ApplySettings aplica a configuração recarregada. Os novos processamentos usam as novas opções de
+// extração e os que já estão em execução terminam com as opções com que começaram
+func (p *jobProcessor) ApplySettings(settings config.WorkerConfig, extraction config.ExtractionConfig, features config.FeatureFlags) {
+	p.settings.Store(&settings)
+	p.extraction.Store(&extraction)
+	p.features.Store(&features)
+	p.scheduler.Resize(settings.ClassWeights, settings.MaxQueued)
+	p.resizeWorkers(settings.MaxVideos)
+}
+
+// resizeWorkers ajusta a quantidade de workers. Os workers removidos terminam o processamento em execução
+// antes de sair, assim a redução não interrompe nenhum processamento. Enquanto eles não saem, ocupam vagas
+// da nova capacidade e os novos workers só iniciam quando elas são liberadas
+func (p *jobProcessor) resizeWorkers(size int) {
+	p.poolMu.Lock()
+	defer p.poolMu.Unlock()
+	if p.pickupCtx.Err() != nil {
+		return
+	}
+	previous := len(p.workerStops)
+	for len(p.workerStops) > size {
+		last := len(p.workerStops) - 1
+		p.workerStops[last]()
+		p.workerStops = p.workerStops[:last]
+		p.retiringWorkers++
+	}
+	p.workerCapacity.Store(int32(size))
+	p.spawnWorkers()
+	p.metrics.WorkerSlots(int(p.busyWorkers.Load()), size)
+	if previous > 0 && previous != size {
+		slog.Info("workers redimensionados", "previous", previous, "current", size, "busy", p.busyWorkers.Load(), "retiring", p.retiringWorkers)
+	}
+}
+
+// spawnWorkers inicia os workers que faltam para a capacidade, contando os removidos que ainda não saíram.
+// Deve ser chamado com o poolMu
+func (p *jobProcessor) spawnWorkers() {
+	for len(p.workerStops)+p.retiringWorkers < int(p.workerCapacity.Load()) {
+		workerCtx, stop := context.WithCancel(p.pickupCtx)
+		p.workerStops = append(p.workerStops, stop)
+		p.workers.Add(1)
+		go p.runWorker(workerCtx)
+	}
+}
+
+// retireWorker libera a vaga do worker removido no redimensionamento, iniciando o worker que aguardava por ela
+func (p *jobProcessor) retireWorker() {
+	p.poolMu.Lock()
+	defer p.poolMu.Unlock()
+	if p.pickupCtx.Err() != nil {
+		return
+	}
+	p.retiringWorkers--
+	p.spawnWorkers()
+}
+
+// runWorker executa os processamentos entregues pelo agendador até o desligamento ou até o worker ser
+// removido no redimensionamento
+func (p *jobProcessor) runWorker(ctx context.Context) {
+	defer p.workers.Done()
+	for {
+		job, err := p.scheduler.Next(ctx)
+		if err != nil {
+			p.retireWorker()
+			return
+		}
+		p.metrics.WorkerSlots(int(p.busyWorkers.Add(1)), int(p.workerCapacity.Load()))
+		job.Run(p.runCtx)
+		p.metrics.WorkerSlots(int(p.busyWorkers.Add(-1)), int(p.workerCapacity.Load()))
+	}
+}
+
+// Shutdown para de iniciar novos processamentos e aguarda os que estão em execução até o fim do
+// prazo do contexto. Os processamentos interrompidos e os que ainda aguardavam na fila voltam a
+// ficar aguardando e são publicados novamente no tópico para serem processados por outra réplica
+func (p *jobProcessor) Shutdown(ctx context.Context) error {
+	// o lock impede que um redimensionamento crie workers durante a espera
+	p.poolMu.Lock()
+	p.stopPickup()
+	p.poolMu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		p.workers.Wait()
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+		slog.Info("processamentos em execução finalizados")
+	case <-ctx.Done():
+		slog.Warn("prazo de desligamento esgotado, interrompendo os processamentos em execução")
+		p.stopRuns(domain.ErrShuttingDown)
+		<-drained
+		err = ctx.Err()
+	}
+	p.stopRuns(domain.ErrShuttingDown)
+
+	return errors.Join(err, p.requeuePending())
+}
+
+func (p *jobProcessor) requeuePending() error {
+	p.pendingMu.Lock()
+	defer p.pendingMu.Unlock()
+
+	// o prazo do desligamento pode ter terminado, mas os processamentos ainda precisam voltar para a fila
+	ctx := context.Background()
+	var errs []error
+	for id, pending := range p.pending {
+		payload := pending.payload
+		if err := p.filesRepository.RequeueFile(ctx, id); err != nil {
+			slog.Error("não foi possível devolver o arquivo para a fila", "fileId", id, "error", err)
+			errs = append(errs, err)
+			continue
+		}
+		fileId := id
+		payload.FileID = &fileId
+		message, err := json.Marshal(payload)
+		if err == nil {
+			err = p.messageProducer.PublishMessage(ctx, payload.UserName, message)
+		}
+		if err != nil {
+			slog.Error("não foi possível publicar novamente o processamento", "fileId", id, "error", err)
+			errs = append(errs, err)
+			continue
+		}
+		slog.Info("processamento devolvido para a fila", "fileId", id)
+		// a entrega original é confirmada após a nova publicação; se o source já foi fechado ela é entregue
+		// novamente e descartada pelo message id
+		p.settle(pending.delivery)
+		delete(p.pending, id)
+	}
+	p.metrics.JobsInFlight(len(p.pending))
+	return errors.Join(errs...)
+}
+
+func (p *jobProcessor) trackPending(id uuid.UUID, job pendingJob) {
+	p.pendingMu.Lock()
+	defer p.pendingMu.Unlock()
+	p.pending[id] = job
+	p.metrics.JobsInFlight(len(p.pending))
+}
+
+// pendingJobs retorna os ids dos processamentos pendentes e as entregas ainda não confirmadas
+func (p *jobProcessor) pendingJobs() ([]uuid.UUID, []adapters.DeferredDelivery) {
+	p.pendingMu.Lock()
+	defer p.pendingMu.Unlock()
+	ids := make([]uuid.UUID, 0, len(p.pending))
+	var deliveries []adapters.DeferredDelivery
+	for id, job := range p.pending {
+		ids = append(ids, id)
+		if job.delivery != nil {
+			deliveries = append(deliveries, job.delivery)
+		}
+	}
+	return ids, deliveries
+}
+
+// untrackPending remove o processamento que terminou e confirma a sua entrega, quando adiada
+func (p *jobProcessor) untrackPending(id uuid.UUID) {
+	p.pendingMu.Lock()
+	job := p.pending[id]
+	delete(p.pending, id)
+	p.metrics.JobsInFlight(len(p.pending))
+	p.pendingMu.Unlock()
+	p.settle(job.delivery)
+}
+
+func (p *jobProcessor) settle(delivery adapters.DeferredDelivery) {
+	if delivery == nil {
+		return
+	}
+	if err := delivery.Ack(); err != nil {
+		slog.Error("não foi possível confirmar a mensagem", "messageId", delivery.Message().ID, "error", err)
+	}
+}
+
+func (p *jobProcessor) runJob(ctx context.Context, id *uuid.UUID, payload domain.FilePayload) {
+	ctx, span := tracer.Start(ctx, "job.process", trace.WithAttributes(
+		attribute.String("file.id", id.String()),
+		attribute.Int64("file.size", payload.FileSize),
+	))
+	defer span.End()
+	// o status final é gravado mesmo depois do cancelamento do processamento
+	statusCtx := context.WithoutCancel(ctx)
+
+	// o cancelamento via API interrompe o ffmpeg e o upload através deste contexto
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	p.jobRegistry.Register(*id, cancel)
+	defer p.jobRegistry.Unregister(*id)
+	defer func() {
+		// processamentos interrompidos pelo desligamento continuam pendentes para serem devolvidos à fila
+		if errors.Is(context.Cause(jobCtx), domain.ErrShuttingDown) {
+			slog.InfoContext(ctx, "processamento interrompido pelo desligamento", "fileId", id)
+			p.metrics.JobFinished(jobOutcomeInterrupted, "")
+			return
+		}
+		p.untrackPending(*id)
+	}()
+
+	// apenas um worker inicia o processamento, mesmo que a mensagem tenha sido entregue mais de uma vez
+	started, err := p.filesRepository.StartProcessing(ctx, *id)
+	if err != nil {
+		slog.ErrorContext(ctx, "não foi possível iniciar o processamento", "fileId", id, "error", err)
+		return
+	}
+	if !started {
+		slog.InfoContext(ctx, "processamento já iniciado, finalizado ou cancelado, ignorando", "fileId", id)
+		return
+	}
+
+	// Sleep para simular processamento demorado definido no parametro PROCESSING_DELAY
+	select {
+	case <-time.After(p.settings.Load().ProcessingDelay):
+	case <-jobCtx.Done():
+	}
+	if jobCtx.Err() != nil {
+		slog.InfoContext(ctx, "processamento cancelado antes de iniciar", "fileId", id)
+		p.recordCancellation(jobCtx)
+		return
+	}
+
+	processingResult := p.processFile(jobCtx, payload)
+	if errors.Is(context.Cause(jobCtx), domain.ErrShuttingDown) {
+		p.removePartialZip(id, processingResult)
+		return
+	}
+	if jobCtx.Err() != nil {
+		p.cleanupCancelledJob(id, processingResult)
+		p.metrics.JobFinished(jobOutcomeCancelled, "")
+		return
+	}
+	span.SetAttributes(attribute.Int("job.status", int(processingResult.Status)))
+	p.atualizaStatus(statusCtx, id, processingResult)
+	if processingResult.Status == domain.FileStatusDone {
+		p.metrics.JobFinished(jobOutcomeDone, "")
+	} else {
+		p.metrics.JobFinished(jobOutcomeFailed, processingResult.Reason())
+	}
+}
+
+// recordCancellation conta o processamento cancelado antes de iniciar; os interrompidos pelo
+// desligamento são contados ao final do runJob
+func (p *jobProcessor) recordCancellation(jobCtx context.Context) {
+	if !errors.Is(context.Cause(jobCtx), domain.ErrShuttingDown) {
+		p.metrics.JobFinished(jobOutcomeCancelled, "")
+	}
+}
+
+func (p *jobProcessor) atualizaStatus(ctx context.Context, fileId *uuid.UUID, processingResult *domain.FileProcessingResult) {
+	err := p.filesRepository.UpdateFileStatus(ctx, fileId, processingResult)
+	if err != nil {
+		slog.ErrorContext(ctx, "não foi possível atualizar o status do arquivo", "error", err, "processingResult", processingResult)
+	}
+}
+
+// insertFile grava o arquivo recebido e retorna o seu id e a classe de prioridade do processamento,
+// ou nil quando o arquivo não deve ser processado
+func (p *jobProcessor) insertFile(ctx context.Context, payload *domain.FilePayload, messageId string) (*uuid.UUID, string, error) {
+	user, err := p.usersRepository.FindUserByEmail(ctx, payload.UserName)
+	if err != nil {
+		slog.ErrorContext(ctx, "não foi possível obter o usuário", "error", err)
+		// o usuário inexistente não aparece em uma nova entrega, então a mensagem vai para o dead letter pelo
+		// JobRouter; as demais falhas devolvem a mensagem para a fila
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, "", fmt.Errorf("%w: %w", domain.ErrInvalidPayload, err)
+		}
+		return nil, "", err
+	}
+	slog.InfoContext(ctx, "usuário encontrado", "user", user)
+
+	priorityClass := payload.Priority
+	if plan, err := p.quotaService.GetPlan(ctx, user.ID); err != nil {
+		slog.ErrorContext(ctx, "não foi possível obter o plano do usuário", "error", err)
+	} else {
+		priorityClass = domain.ResolvePriorityClass(payload.Priority, plan.PriorityClass)
+	}
+
+	// reprocessamentos chegam com o registro já criado pela API
+	if payload.FileID != nil {
+		if err := p.verifyReprocessFiles(ctx, payload); err != nil {
+			if errors.Is(err, domain.ErrFileNotFound) {
+				slog.WarnContext(ctx, "reprocessamento ignorado, arquivo não pertence ao usuário da mensagem", "fileId", payload.FileID, "replaceFileId", payload.ReplaceFileID)
+				return nil, "", nil
+			}
+			return nil, "", err
+		}
+		// o sinal de vida marca o arquivo como aceito por esta réplica antes da confirmação da mensagem; se a
+		// réplica cair antes de processá-lo, o arquivo é devolvido para a fila pelo StuckJobReaper
+		if err := p.filesRepository.Heartbeat(ctx, []uuid.UUID{*payload.FileID}); err != nil {
+			slog.ErrorContext(ctx, "não foi possível registrar o aceite do arquivo", "fileId", payload.FileID, "error", err)
+			return nil, "", err
+		}
+		slog.InfoContext(ctx, "reprocessamento de arquivo existente", "fileId", payload.FileID, "version", payload.Version)
+		return payload.FileID, priorityClass, nil
+	}
+	options := payload.ExtractionOptions.Merge(defaultExtractionOptions(*p.extraction.Load()))
+	fileEntity := &domain.File{UserID: user.ID, VideoFilePath: payload.FilePath, VideoFileSize: payload.FileSize, FileStatus: domain.FileStatus{ID: domain.FileStatusReceived, Status: ""}, ExtractionOptions: &options}
+	// brokers sem um identificador estável da mensagem não são verificados contra entregas repetidas
+	if messageId != "" {
+		fileEntity.MessageID = &messageId
+	}
+
+	// a cota é verificada no aceite do processamento; arquivos acima da cota são gravados como rejeitados
+	quotaCheck, err := p.quotaService.QuotaCheck(ctx, user.ID, payload.FileSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "não foi possível obter a cota do usuário", "error", err)
+		return nil, "", err
+	}
+
+	fileId, quotaExceeded, err := p.filesRepository.CreateFile(ctx, fileEntity, quotaCheck)
+	if errors.Is(err, domain.ErrDuplicateMessage) {
+		slog.InfoContext(ctx, "mensagem já recebida anteriormente, ignorando", "messageId", messageId)
+		return nil, "", nil
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "não foi possível gravar o arquivo na base de dados", "error", err)
+		return nil, "", err
+	}
+	slog.InfoContext(ctx, "id do novo arquivo na base", "fileId", fileId)
+
+	if quotaExceeded != nil {
+		slog.WarnContext(ctx, "arquivo rejeitado por cota excedida", "fileId", fileId, "code", quotaExceeded.Code)
+		p.atualizaStatus(ctx, fileId, &domain.FileProcessingResult{Status: domain.FileStatusRejected, Message: "cota excedida - " + quotaExceeded.Error()})
+		p.metrics.JobFinished(jobOutcomeRejected, strings.ToLower(quotaExceeded.Code))
+		body := "Seu arquivo de vídeo não foi processado pois a cota do seu plano foi excedida. \r\n" + quotaExceeded.Error()
+		p.sendEmail(payload.UserName, "cota de processamento excedida", body)
+		return nil, "", nil
+	}
+	return fileId, priorityClass, nil
+
+}
+
+// verifyReprocessFiles confere que o arquivo reprocessado e o arquivo substituído pertencem ao usuário da
+// mensagem e à mesma cadeia de versões, retornando domain.ErrFileNotFound quando não pertencem. O video
+// processado passa a ser o gravado no registro, e não o informado na mensagem
+func (p *jobProcessor) verifyReprocessFiles(ctx context.Context, payload *domain.FilePayload) error {
+	file, err := p.filesRepository.FindFileByID(ctx, *payload.FileID, payload.UserName)
+	if err != nil {
+		return err
+	}
+	payload.FilePath = file.VideoFilePath
+	if payload.ReplaceFileID == nil {
+		return nil
+	}
+	previous, err := p.filesRepository.FindFileByID(ctx, *payload.ReplaceFileID, payload.UserName)
+	if err != nil {
+		return err
+	}
+	if file.ParentFileID == nil || previous.RootID() != *file.ParentFileID {
+		return domain.ErrFileNotFound
+	}
+	return nil
+}
+
+func (p *jobProcessor) processFile(ctx context.Context, payload domain.FilePayload) *domain.FileProcessingResult {
+	fileFullPath, userEmail := payload.FilePath, payload.UserName
+	options := payload.ExtractionOptions.Merge(defaultExtractionOptions(*p.extraction.Load()))
+	if err := options.Validate(); err != nil {
+		return domain.NewFileProcessingFailure(domain.FailureReasonInvalidOptions, err.Error())
+	}
+
+	// valida o tamanho do objeto antes de baixar o video
+	downloadCtx, downloadSpan := tracer.Start(ctx, "video.download", trace.WithAttributes(attribute.String("video.path", fileFullPath)))
+	downloadStart := time.Now()
+	videoSize, err := p.bucketRepository.GetFileSize(downloadCtx, fileFullPath)
+	if err != nil {
+		endSpan(downloadSpan, err)
+		return domain.NewFileProcessingFailure(domain.FailureReasonDownload, "não foi possível obter o tamanho do arquivo de video - "+err.Error())
+	}
+	if rejection := p.videoValidator.ValidateSize(payload.FileSize, videoSize); rejection != nil {
+		endSpan(downloadSpan, rejection)
+		return p.rejectVideo(ctx, userEmail, rejection)
+	}
+	videoData, _, err := p.bucketRepository.DownloadFile(downloadCtx, fileFullPath)
+	downloadSpan.SetAttributes(attribute.Int("video.bytes", len(videoData)))
+	endSpan(downloadSpan, err)
+	if err != nil {
+		return domain.NewFileProcessingFailure(domain.FailureReasonDownload, "não foi possível baixar o arquivo de video - "+err.Error())
+	}
+	p.metrics.StageDuration(jobStageDownload, time.Since(downloadStart))
+	p.metrics.JobBytes("input", int64(len(videoData)))
+
+	probeCtx, probeSpan := tracer.Start(ctx, "video.probe")
+	if rejection := p.videoValidator.ValidateContent(probeCtx, videoData); rejection != nil {
+		endSpan(probeSpan, rejection)
+		// o ffprobe interrompido pelo cancelamento falha como um video inválido, sem motivo para avisar o usuário
+		if ctx.Err() != nil {
+			return domain.NewFileProcessingResultWithError("processamento cancelado")
+		}
+		return p.rejectVideo(ctx, userEmail, rejection)
+	}
+	probeSpan.End()
+
+	extractCtx, extractSpan := tracer.Start(ctx, "video.extract_frames", trace.WithAttributes(attribute.Float64("extraction.fps", options.FPS)))
+	startTime := time.Now()
+	frames, exitCode, err := utils.ExtractFrames(extractCtx, videoData, options.FPS)
+	duration := time.Since(startTime)
+	extractSpan.SetAttributes(attribute.Int("extraction.frames", len(frames)), attribute.Int("ffmpeg.exit_code", exitCode))
+	endSpan(extractSpan, err)
+	slog.InfoContext(ctx, "extração de frames concluída", "tempo total", duration)
+	p.metrics.StageDuration(jobStageExtraction, duration)
+	p.metrics.FFmpegExitCode(exitCode)
+	p.metrics.FramesExtracted(len(frames))
+
+	if ctx.Err() != nil {
+		return domain.NewFileProcessingResultWithError("processamento cancelado")
+	}
+	if err != nil || len(frames) == 0 {
+		body := "Infelizmente não foi possível processar seu arquivo de vídeo. \r\n" + err.Error()
+		p.sendEmail(userEmail, "não foi possível processar seu arquivo de video", body)
+		return domain.NewFileProcessingFailure(domain.FailureReasonExtraction, "não foi possível processar o arquivo de video - "+err.Error())
+	}
+	slog.InfoContext(ctx, fmt.Sprintf("📸 extraídos %d frames\n", len(frames)))
+	fileName := utils.GetBaseFilename(fileFullPath)
+	zipFilename := fmt.Sprintf("frames_%s.zip", fileName)
+	if payload.Version > 1 {
+		zipFilename = fmt.Sprintf("frames_%s_v%d.zip", fileName, payload.Version)
+	}
+
+	// cria arquivo na memoria para guardar no bucket
+	_, zipSpan := tracer.Start(ctx, "video.zip", trace.WithAttributes(attribute.Int("zip.image_quality", options.ImageQuality)))
+	zipStart := time.Now()
+	arquivoZip, err := utils.CreateImageZipInMemory(frames, options.ImageQuality)
+	endSpan(zipSpan, err)
+	if err != nil {
+		return domain.NewFileProcessingFailure(domain.FailureReasonZip, "não foi possível criar o arquivo ZIP em memória - "+err.Error())
+	}
+	p.metrics.StageDuration(jobStageZip, time.Since(zipStart))
+	// gravar no bucket
+	uploadCtx, uploadSpan := tracer.Start(ctx, "video.upload", trace.WithAttributes(attribute.String("zip.name", zipFilename)))
+	uploadStart := time.Now()
+	zipFileSize, zipFilePath, err := p.createFile(uploadCtx, arquivoZip, zipFilename, userEmail)
+	uploadSpan.SetAttributes(attribute.Int64("zip.bytes", zipFileSize))
+	endSpan(uploadSpan, err)
+	if err != nil {
+		slog.ErrorContext(ctx, "não foi possível gravar o arquivo zip no bucket", "fileName", fileName, "error", err)
+		return domain.NewFileProcessingFailure(domain.FailureReasonUpload, "não foi possível criar o arquivo ZIP no bucket - "+err.Error())
+	}
+	p.metrics.StageDuration(jobStageUpload, time.Since(uploadStart))
+	p.metrics.JobBytes("output", zipFileSize)
+	if payload.ReplaceFileID != nil {
+		p.replacePreviousZip(ctx, *payload.ReplaceFileID, zipFilePath)
+	}
+	return &domain.FileProcessingResult{FilePath: &zipFilePath, FileSize: &zipFileSize, Status: domain.FileStatusDone, Message: fmt.Sprintf("%d frames extraídos", len(frames))}
+}
+
+// replacePreviousZip remove o arquivo ZIP da versão anterior quando o reprocessamento pede a substituição
+func (p *jobProcessor) replacePreviousZip(ctx context.Context, previousFileId uuid.UUID, newZipFilePath string) {
+	previousZipFilePath, err := p.filesRepository.ReleaseZipFile(ctx, previousFileId)
+	if err != nil {
+		slog.ErrorContext(ctx, "não foi possível desassociar o arquivo zip da versão anterior", "fileId", previousFileId, "error", err)
+		return
+	}
+	if previousZipFilePath == nil || *previousZipFilePath == newZipFilePath {
+		return
+	}
+	if err := p.bucketRepository.DeleteFile(ctx, *previousZipFilePath); err != nil {
+		slog.ErrorContext(ctx, "não foi possível remover o arquivo zip da versão anterior", "fileId", previousFileId, "error", err)
+		return
+	}
+	slog.InfoContext(ctx, "arquivo zip da versão anterior substituído", "fileId", previousFileId, "zipFilePath", *previousZipFilePath)
+}
+
+// cleanupCancelledJob remove o arquivo ZIP gravado antes do cancelamento ser percebido
+func (p *jobProcessor) cleanupCancelledJob(fileId *uuid.UUID, processingResult *domain.FileProcessingResult) {
+	slog.Info("processamento cancelado", "fileId", fileId)
+	p.removePartialZip(fileId, processingResult)
+}
+
+func (p *jobProcessor) removePartialZip(fileId *uuid.UUID, processingResult *domain.FileProcessingResult) {
+	if processingResult == nil || processingResult.FilePath == nil {
+		return
+	}
+	if err := p.bucketRepository.DeleteFile(context.Background(), *processingResult.FilePath); err != nil {
+		slog.Error("não foi possível remover o arquivo zip do processamento cancelado", "fileId", fileId, "error", err)
+	}
+}
+
+// sendHeartbeats registra periodicamente o sinal de vida dos processamentos aceitos por esta réplica, em
+// execução ou aguardando no agendador, usado para identificar os processamentos perdidos quando a réplica cai.
+// As entregas ainda não confirmadas têm o prazo de confirmação do broker estendido no mesmo intervalo
+func (p *jobProcessor) sendHeartbeats(ctx context.Context) {
+	ticker := time.NewTicker(p.settings.Load().HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ids, deliveries := p.pendingJobs()
+			if err := p.filesRepository.Heartbeat(ctx, ids); err != nil {
+				slog.Error("não foi possível registrar o sinal de vida dos processamentos", "error", err)
+			}
+			for _, delivery := range deliveries {
+				if err := delivery.InProgress(); err != nil {
+					slog.Warn("não foi possível estender o prazo de confirmação da mensagem", "messageId", delivery.Message().ID, "error", err)
+				}
+			}
+		}
+	}
+}
+
+// watchCancellations consulta periodicamente a base para interromper os processamentos desta réplica
+// que foram cancelados através de outra réplica
+func (p *jobProcessor) watchCancellations(ctx context.Context) {
+	ticker := time.NewTicker(p.settings.Load().CancellationPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cancelledIds, err := p.filesRepository.ListCancelledFiles(ctx, p.jobRegistry.RunningJobs())
+			if err != nil {
+				slog.Error("não foi possível consultar os processamentos cancelados", "error", err)
+				continue
+			}
+			for _, id := range cancelledIds {
+				if p.jobRegistry.Cancel(id) {
+					slog.Info("cancelamento recebido para o processamento", "fileId", id)
+				}
+			}
+		}
+	}
+}
+
+func (p *jobProcessor) rejectVideo(ctx context.Context, userEmail string, rejection *domain.VideoRejection) *domain.FileProcessingResult {
+	slog.WarnContext(ctx, "arquivo de video rejeitado na validação", "code", rejection.Code, "message", rejection.Message)
+	body := "Seu arquivo de vídeo foi rejeitado. \r\n" + rejection.Error()
+	p.sendEmail(userEmail, "seu arquivo de video foi rejeitado", body)
+	return domain.NewFileProcessingFailure(rejection.FailureReason(), "arquivo de video rejeitado - "+rejection.Error())
+}
+
+// sendEmail envia o email ao usuário quando a feature flag email_notifications está ligada
+func (p *jobProcessor) sendEmail(emailAddress, title, body string) {
+	if !p.features.Load().Enabled(config.FeatureEmailNotifications) {
+		slog.Debug("notificações por email desligadas, email não enviado", "title", title)
+		return
+	}
+	utils.SendEmail(p.email, emailAddress, title, body)
+}
+
+func (p *jobProcessor) createFile(ctx context.Context, file multipart.File, fileName, userEmail string) (int64, string, error) {
+	slog.InfoContext(ctx, "jobProcessor - create file", "userEmail", userEmail, "fileName", fileName)
+	// junta nome do usuario com caminho
+	path := filepath.Join(utils.SanitizeEmailForPath(userEmail), "zip_files")
+
+	// grava no bucket
+	fileFullPath, err := p.bucketRepository.CreateFile(ctx, path, fileName, file)
+	if err != nil {
+		return 0, "", err
+	}
+
+	fileSize, err := utils.GetFileSize(file)
+	if err != nil {
+		return 0, "", err
+	}
+	slog.InfoContext(ctx, "arquivo gravado com sucesso", "fileName", fileName, "filesize", fileSize, "fileFullPath", fileFullPath)
+	return fileSize, fileFullPath, nil
+}
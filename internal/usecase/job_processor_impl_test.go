@@ -0,0 +1,850 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	portRepositories "github.com/backstagefood/video-processor-worker/internal/domain/interface/repositories"
+	portServices "github.com/backstagefood/video-processor-worker/internal/domain/interface/services"
+	"github.com/backstagefood/video-processor-worker/pkg/config"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// memoryJobSource entrega as mensagens publicadas em memória e registra as confirmações
+type memoryJobSource struct {
+	deliveries chan adapters.Delivery
+	mu         sync.Mutex
+	acked      []string
+	nacked     []string
+}
+
+func newMemoryJobSource(messages ...adapters.Message) *memoryJobSource {
+	source := &memoryJobSource{deliveries: make(chan adapters.Delivery, len(messages))}
+	for _, message := range messages {
+		source.deliveries <- &memoryDelivery{source: source, message: message}
+	}
+	return source
+}
+
+func (s *memoryJobSource) Receive(ctx context.Context) (adapters.Delivery, error) {
+	select {
+	case delivery := <-s.deliveries:
+		return delivery, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *memoryJobSource) Stop() error  { return nil }
+func (s *memoryJobSource) Close() error { return nil }
+func (s *memoryJobSource) PauseAll()    {}
+func (s *memoryJobSource) ResumeAll()   {}
+
+func (s *memoryJobSource) results() ([]string, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.acked...), append([]string(nil), s.nacked...)
+}
+
+type memoryDelivery struct {
+	source  *memoryJobSource
+	message adapters.Message
+}
+
+func (d *memoryDelivery) Message() *adapters.Message { return &d.message }
+
+func (d *memoryDelivery) Ack() error {
+	d.source.mu.Lock()
+	defer d.source.mu.Unlock()
+	d.source.acked = append(d.source.acked, d.message.ID)
+	return nil
+}
+
+func (d *memoryDelivery) Nack() error {
+	d.source.mu.Lock()
+	defer d.source.mu.Unlock()
+	d.source.nacked = append(d.source.nacked, d.message.ID)
+	return nil
+}
+
+// deferredMemoryDelivery é uma entrega confirmada individualmente, como as do amqp e do nats
+type deferredMemoryDelivery struct {
+	memoryDelivery
+	mu         sync.Mutex
+	inProgress int
+}
+
+func (d *deferredMemoryDelivery) InProgress() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.inProgress++
+	return nil
+}
+
+func (d *deferredMemoryDelivery) extensions() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.inProgress
+}
+
+type processorUsersRepository struct {
+	portRepositories.UsersRepository
+	err error
+}
+
+func (r processorUsersRepository) FindUserByEmail(_ context.Context, email string) (*domain.User, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &domain.User{ID: uuid.New(), Email: email}, nil
+}
+
+type processorFilesRepository struct {
+	portRepositories.FilesRepository
+	mu         sync.Mutex
+	createErr  error
+	messageIds map[string]bool
+	finished   chan *domain.FileProcessingResult
+	// userFiles indexa os arquivos existentes pelo email do dono e o id
+	userFiles    map[string]*domain.File
+	heartbeats   []uuid.UUID
+	heartbeatErr error
+}
+
+func (r *processorFilesRepository) FindFileByID(_ context.Context, id uuid.UUID, userEmail string) (*domain.File, error) {
+	file, exists := r.userFiles[userEmail+"/"+id.String()]
+	if !exists {
+		return nil, domain.ErrFileNotFound
+	}
+	return file, nil
+}
+
+func (r *processorFilesRepository) CreateFile(_ context.Context, file *domain.File, quotaCheck domain.QuotaCheck) (*uuid.UUID, *domain.QuotaExceeded, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.createErr != nil {
+		return nil, nil, r.createErr
+	}
+	if r.messageIds[*file.MessageID] {
+		return nil, nil, domain.ErrDuplicateMessage
+	}
+	r.messageIds[*file.MessageID] = true
+	// cada arquivo gravado conta como um processamento simultâneo do usuário
+	quotaExceeded := quotaCheck(domain.QuotaUsage{ConcurrentJobs: len(r.messageIds) - 1})
+	id := uuid.New()
+	return &id, quotaExceeded, nil
+}
+
+func (r *processorFilesRepository) Heartbeat(_ context.Context, ids []uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.heartbeats = append(r.heartbeats, ids...)
+	return r.heartbeatErr
+}
+
+func (r *processorFilesRepository) StartProcessing(context.Context, uuid.UUID) (bool, error) {
+	return true, nil
+}
+
+func (r *processorFilesRepository) UpdateFileStatus(_ context.Context, _ *uuid.UUID, processingResult *domain.FileProcessingResult) error {
+	r.finished <- processingResult
+	return nil
+}
+
+type processorBucketRepository struct {
+	portRepositories.BucketRepository
+}
+
+func (processorBucketRepository) GetFileSize(context.Context, string) (int64, error) {
+	return 0, errors.New("bucket indisponível")
+}
+
+type processorQuotaService struct {
+	portServices.QuotaService
+	plan domain.UserPlan
+}
+
+func (processorQuotaService) GetPlan(context.Context, uuid.UUID) (*domain.UserPlan, error) {
+	return nil, domain.ErrUserPlanNotFound
+}
+
+func (s processorQuotaService) QuotaCheck(_ context.Context, _ uuid.UUID, incomingBytes int64) (domain.QuotaCheck, error) {
+	return func(usage domain.QuotaUsage) *domain.QuotaExceeded {
+		return s.plan.Check(usage, incomingBytes)
+	}, nil
+}
+
+// recordingMetrics registra os processamentos finalizados e os processamentos em andamento
+type recordingMetrics struct {
+	noopMetrics
+	mu       sync.Mutex
+	finished []string
+	inFlight int
+}
+
+func (m *recordingMetrics) JobFinished(outcome, reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.finished = append(m.finished, outcome+"/"+reason)
+}
+
+func (m *recordingMetrics) JobsInFlight(count int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight = count
+}
+
+func newTestJobProcessor(filesRepository *processorFilesRepository) portServices.JobProcessor {
+	return newTestJobProcessorWithMetrics(filesRepository, noopMetrics{})
+}
+
+func newTestJobProcessorWithMetrics(filesRepository *processorFilesRepository, metrics adapters.Metrics) portServices.JobProcessor {
+	return newTestJobProcessorWithUsers(processorUsersRepository{}, filesRepository, &recordingProducer{}, metrics)
+}
+
+func newTestJobProcessorWithUsers(
+	usersRepository processorUsersRepository,
+	filesRepository *processorFilesRepository,
+	deadLetterProducer adapters.MessageProducer,
+	metrics adapters.Metrics,
+) portServices.JobProcessor {
+	settings := config.Default()
+	return NewJobProcessor(
+		usersRepository,
+		filesRepository,
+		processorBucketRepository{},
+		NewVideoValidator(settings.Video),
+		processorQuotaService{},
+		NewJobRegistry(),
+		&recordingProducer{},
+		NewJobRouter(deadLetterProducer, settings.Worker.DefaultJobType),
+		NewPayloadDecoder(),
+		metrics,
+		settings.Worker,
+		settings.Extraction,
+		settings.Email,
+		settings.Features,
+	)
+}
+
+func runJobProcessor(t *testing.T, processor portServices.JobProcessor, source adapters.JobSource) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- processor.Run(ctx, source)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		if err := processor.Shutdown(context.Background()); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+}
+
+func waitForAcks(t *testing.T, source *memoryJobSource, total int) ([]string, []string) {
+	deadline := time.After(2 * time.Second)
+	for {
+		acked, nacked := source.results()
+		if len(acked)+len(nacked) >= total {
+			return acked, nacked
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("Expected %d confirmed deliveries, got acked=%v nacked=%v", total, acked, nacked)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestJobProcessorAcksAndProcessesDeliveries(t *testing.T) {
+	payload := []byte(`{"user_name":"user@test.com","file_path":"user/video.mp4","file_size":10}`)
+	source := newMemoryJobSource(
+		adapters.Message{ID: "invalid", Value: []byte("{")},
+		adapters.Message{ID: "video", Value: payload},
+		adapters.Message{ID: "video", Value: payload},
+	)
+	filesRepository := &processorFilesRepository{
+		messageIds: make(map[string]bool),
+		finished:   make(chan *domain.FileProcessingResult, 1),
+	}
+	runJobProcessor(t, newTestJobProcessor(filesRepository), source)
+
+	acked, nacked := waitForAcks(t, source, 3)
+	if len(acked) != 3 || len(nacked) != 0 {
+		t.Errorf("Expected all deliveries to be acked, got acked=%v nacked=%v", acked, nacked)
+	}
+
+	select {
+	case result := <-filesRepository.finished:
+		if result.Status != domain.FileStatusError {
+			t.Errorf("Expected status %d, got %d", domain.FileStatusError, result.Status)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the accepted file to be processed")
+	}
+	select {
+	case result := <-filesRepository.finished:
+		t.Errorf("Expected the duplicated message to be skipped, got %+v", result)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestJobProcessorAcksDeferredDeliveryWhenJobFinishes(t *testing.T) {
+	source := &memoryJobSource{deliveries: make(chan adapters.Delivery, 1)}
+	delivery := &deferredMemoryDelivery{memoryDelivery: memoryDelivery{source: source, message: adapters.Message{
+		ID:    "video",
+		Value: []byte(`{"user_name":"user@test.com","file_path":"user/video.mp4","file_size":10}`),
+	}}}
+	source.deliveries <- delivery
+	// o processamento fica bloqueado na gravação do status final até o teste ler o resultado
+	filesRepository := &processorFilesRepository{
+		messageIds: make(map[string]bool),
+		finished:   make(chan *domain.FileProcessingResult),
+	}
+	settings := config.Default()
+	settings.Worker.HeartbeatInterval = 10 * time.Millisecond
+	processor := NewJobProcessor(
+		processorUsersRepository{},
+		filesRepository,
+		processorBucketRepository{},
+		NewVideoValidator(settings.Video),
+		processorQuotaService{},
+		NewJobRegistry(),
+		&recordingProducer{},
+		NewJobRouter(&recordingProducer{}, settings.Worker.DefaultJobType),
+		NewPayloadDecoder(),
+		noopMetrics{},
+		settings.Worker,
+		settings.Extraction,
+		settings.Email,
+		settings.Features,
+	)
+	runJobProcessor(t, processor, source)
+
+	deadline := time.After(2 * time.Second)
+	for delivery.extensions() == 0 {
+		select {
+		case <-deadline:
+			t.Fatal("Expected the ack deadline to be extended while the job runs")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+	if acked, nacked := source.results(); len(acked) != 0 || len(nacked) != 0 {
+		t.Fatalf("Expected the delivery to stay unconfirmed while the job runs, got acked=%v nacked=%v", acked, nacked)
+	}
+
+	select {
+	case <-filesRepository.finished:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the accepted file to be processed")
+	}
+	if acked, _ := waitForAcks(t, source, 1); !slices.Equal(acked, []string{"video"}) {
+		t.Errorf("Expected the delivery to be acked after the job finished, got %v", acked)
+	}
+}
+
+func TestJobProcessorNacksWhenFileCannotBeStored(t *testing.T) {
+	source := newMemoryJobSource(adapters.Message{ID: "video", Value: []byte(`{"user_name":"user@test.com","file_path":"user/video.mp4","file_size":10}`)})
+	filesRepository := &processorFilesRepository{
+		createErr:  errors.New("base indisponível"),
+		messageIds: make(map[string]bool),
+		finished:   make(chan *domain.FileProcessingResult, 1),
+	}
+	runJobProcessor(t, newTestJobProcessor(filesRepository), source)
+
+	acked, nacked := waitForAcks(t, source, 1)
+	if len(acked) != 0 || len(nacked) != 1 {
+		t.Errorf("Expected the delivery to be nacked, got acked=%v nacked=%v", acked, nacked)
+	}
+}
+
+func TestJobProcessorNacksWhenUserLookupFails(t *testing.T) {
+	source := newMemoryJobSource(adapters.Message{ID: "video", Value: []byte(`{"user_name":"user@test.com","file_path":"user/video.mp4","file_size":10}`)})
+	filesRepository := &processorFilesRepository{messageIds: make(map[string]bool), finished: make(chan *domain.FileProcessingResult, 1)}
+	deadLetterProducer := &recordingProducer{}
+	usersRepository := processorUsersRepository{err: errors.New("conexão com a base interrompida")}
+	runJobProcessor(t, newTestJobProcessorWithUsers(usersRepository, filesRepository, deadLetterProducer, noopMetrics{}), source)
+
+	acked, nacked := waitForAcks(t, source, 1)
+	if len(acked) != 0 || !slices.Equal(nacked, []string{"video"}) {
+		t.Errorf("Expected the delivery to be nacked for a retry, got acked=%v nacked=%v", acked, nacked)
+	}
+	if len(deadLetterProducer.keys) != 0 {
+		t.Errorf("Expected no dead letter for a temporary failure, got %v", deadLetterProducer.keys)
+	}
+}
+
+func TestJobProcessorSendsUnknownUsersToDeadLetter(t *testing.T) {
+	source := newMemoryJobSource(adapters.Message{ID: "video", Key: "ghost@test.com", Value: []byte(`{"user_name":"ghost@test.com","file_path":"user/video.mp4","file_size":10}`)})
+	filesRepository := &processorFilesRepository{messageIds: make(map[string]bool), finished: make(chan *domain.FileProcessingResult, 1)}
+	deadLetterProducer := &recordingProducer{}
+	usersRepository := processorUsersRepository{err: domain.ErrUserNotFound}
+	runJobProcessor(t, newTestJobProcessorWithUsers(usersRepository, filesRepository, deadLetterProducer, noopMetrics{}), source)
+
+	acked, nacked := waitForAcks(t, source, 1)
+	if !slices.Equal(acked, []string{"video"}) || len(nacked) != 0 {
+		t.Errorf("Expected the delivery to be acked after the dead letter, got acked=%v nacked=%v", acked, nacked)
+	}
+	if !slices.Equal(deadLetterProducer.keys, []string{"ghost@test.com"}) {
+		t.Fatalf("Expected the message in the dead letter, got %v", deadLetterProducer.keys)
+	}
+	if reason := deadLetterProducer.headers[0][deadLetterReasonHeader]; !strings.Contains(reason, domain.ErrUserNotFound.Error()) {
+		t.Errorf("Expected the unknown user as the dead letter reason, got %q", reason)
+	}
+}
+
+func TestJobProcessorContinuesTraceFromMessageHeaders(t *testing.T) {
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	source := newMemoryJobSource(adapters.Message{
+		ID:      "video",
+		Value:   []byte(`{"user_name":"user@test.com","file_path":"user/video.mp4","file_size":10}`),
+		Headers: map[string]string{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+	})
+	filesRepository := &processorFilesRepository{
+		messageIds: make(map[string]bool),
+		finished:   make(chan *domain.FileProcessingResult, 1),
+	}
+	runJobProcessor(t, newTestJobProcessor(filesRepository), source)
+
+	select {
+	case <-filesRepository.finished:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the file to be processed")
+	}
+
+	deadline := time.After(2 * time.Second)
+	for {
+		spans := make(map[string]sdktrace.ReadOnlySpan)
+		for _, span := range recorder.Ended() {
+			spans[span.Name()] = span
+		}
+		if spans["job.receive"] != nil && spans["job.process"] != nil {
+			for _, name := range []string{"job.receive", "job.process", "video.download"} {
+				if spans[name] == nil || spans[name].SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+					t.Errorf("Expected span %s to continue the message trace, got %v", name, spans[name])
+				}
+			}
+			return
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("Expected job spans to be recorded, got %v", spans)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestJobProcessorRecordsJobOutcome(t *testing.T) {
+	source := newMemoryJobSource(adapters.Message{ID: "video", Value: []byte(`{"user_name":"user@test.com","file_path":"user/video.mp4","file_size":10}`)})
+	filesRepository := &processorFilesRepository{
+		messageIds: make(map[string]bool),
+		finished:   make(chan *domain.FileProcessingResult, 1),
+	}
+	metrics := &recordingMetrics{}
+	runJobProcessor(t, newTestJobProcessorWithMetrics(filesRepository, metrics), source)
+
+	select {
+	case <-filesRepository.finished:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the file to be processed")
+	}
+
+	deadline := time.After(2 * time.Second)
+	for {
+		metrics.mu.Lock()
+		finished, inFlight := metrics.finished, metrics.inFlight
+		metrics.mu.Unlock()
+		if len(finished) > 0 && inFlight == 0 {
+			if len(finished) != 1 || finished[0] != jobOutcomeFailed+"/"+domain.FailureReasonDownload {
+				t.Errorf("Unexpected job outcomes: %v", finished)
+			}
+			return
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("Expected the job outcome to be recorded, got finished=%v inFlight=%d", finished, inFlight)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestJobProcessorResizesWorkersWithoutInterruptingJobs(t *testing.T) {
+	settings := config.Default()
+	settings.Worker.MaxVideos = 2
+	processor := NewJobProcessor(
+		processorUsersRepository{},
+		&processorFilesRepository{messageIds: make(map[string]bool)},
+		processorBucketRepository{},
+		NewVideoValidator(settings.Video),
+		processorQuotaService{},
+		NewJobRegistry(),
+		&recordingProducer{},
+		NewJobRouter(&recordingProducer{}, settings.Worker.DefaultJobType),
+		NewPayloadDecoder(),
+		noopMetrics{},
+		settings.Worker,
+		settings.Extraction,
+		settings.Email,
+		settings.Features,
+	).(*jobProcessor)
+	t.Cleanup(func() {
+		if err := processor.Shutdown(context.Background()); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	var mu sync.Mutex
+	running := 0
+	submit := func(release <-chan struct{}, started chan<- error) {
+		job := &domain.ScheduledJob{UserKey: "user", Run: func(ctx context.Context) {
+			mu.Lock()
+			running++
+			mu.Unlock()
+			<-release
+			started <- ctx.Err()
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}}
+		if err := processor.scheduler.Submit(context.Background(), job); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	waitRunning := func(expected int) {
+		deadline := time.After(2 * time.Second)
+		for {
+			mu.Lock()
+			current := running
+			mu.Unlock()
+			if current == expected {
+				return
+			}
+			select {
+			case <-deadline:
+				t.Fatalf("Expected %d running jobs, got %d", expected, current)
+			case <-time.After(5 * time.Millisecond):
+			}
+		}
+	}
+
+	// a redução com os dois workers ocupados não interrompe os processamentos em execução
+	release := make(chan struct{})
+	finished := make(chan error, 10)
+	submit(release, finished)
+	submit(release, finished)
+	waitRunning(2)
+	shrunk := settings.Worker
+	shrunk.MaxVideos = 1
+	processor.ApplySettings(shrunk, settings.Extraction, settings.Features)
+	close(release)
+	for i := 0; i < 2; i++ {
+		if err := <-finished; err != nil {
+			t.Errorf("Expected running job to finish normally, got %v", err)
+		}
+	}
+
+	blocked := make(chan struct{})
+	for i := 0; i < 3; i++ {
+		submit(blocked, finished)
+	}
+	waitRunning(1)
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	if running != 1 {
+		t.Errorf("Expected a single worker after shrinking, got %d running jobs", running)
+	}
+	mu.Unlock()
+
+	// o aumento inicia os novos workers, que retiram os processamentos que aguardavam na fila
+	grown := settings.Worker
+	grown.MaxVideos = 3
+	processor.ApplySettings(grown, settings.Extraction, settings.Features)
+	waitRunning(3)
+	close(blocked)
+	for i := 0; i < 3; i++ {
+		<-finished
+	}
+	if capacity := processor.workerCapacity.Load(); capacity != 3 {
+		t.Errorf("Expected capacity 3, got %d", capacity)
+	}
+}
+
+func TestJobProcessorCountsRetiringWorkersAgainstCapacity(t *testing.T) {
+	settings := config.Default()
+	settings.Worker.MaxVideos = 2
+	processor := NewJobProcessor(
+		processorUsersRepository{},
+		&processorFilesRepository{messageIds: make(map[string]bool)},
+		processorBucketRepository{},
+		NewVideoValidator(settings.Video),
+		processorQuotaService{},
+		NewJobRegistry(),
+		&recordingProducer{},
+		NewJobRouter(&recordingProducer{}, settings.Worker.DefaultJobType),
+		NewPayloadDecoder(),
+		noopMetrics{},
+		settings.Worker,
+		settings.Extraction,
+		settings.Email,
+		settings.Features,
+	).(*jobProcessor)
+	t.Cleanup(func() {
+		if err := processor.Shutdown(context.Background()); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	var mu sync.Mutex
+	running, peak := 0, 0
+	started := make(chan struct{}, 10)
+	submit := func(release <-chan struct{}) {
+		job := &domain.ScheduledJob{UserKey: "user", Run: func(context.Context) {
+			mu.Lock()
+			running++
+			peak = max(peak, running)
+			mu.Unlock()
+			started <- struct{}{}
+			<-release
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}}
+		if err := processor.scheduler.Submit(context.Background(), job); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	waitStarted := func() {
+		select {
+		case <-started:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected a job to start")
+		}
+	}
+
+	first, second := make(chan struct{}), make(chan struct{})
+	submit(first)
+	submit(second)
+	waitStarted()
+	waitStarted()
+
+	// reduzir e aumentar com os dois workers ocupados não pode iniciar um terceiro processamento
+	shrunk, grown := settings.Worker, settings.Worker
+	shrunk.MaxVideos = 1
+	processor.ApplySettings(shrunk, settings.Extraction, settings.Features)
+	processor.ApplySettings(grown, settings.Extraction, settings.Features)
+	third := make(chan struct{})
+	submit(third)
+	select {
+	case <-started:
+		t.Fatal("Expected the queued job to wait for a worker slot")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// o worker removido libera a vaga ao terminar, e o novo worker retira o processamento da fila
+	close(first)
+	close(second)
+	waitStarted()
+	close(third)
+	mu.Lock()
+	defer mu.Unlock()
+	if peak > 2 {
+		t.Errorf("Expected at most 2 concurrent jobs, got %d", peak)
+	}
+}
+
+type downloadedBucketRepository struct {
+	portRepositories.BucketRepository
+}
+
+func (downloadedBucketRepository) GetFileSize(context.Context, string) (int64, error) {
+	return 10, nil
+}
+
+func (downloadedBucketRepository) DownloadFile(context.Context, string) ([]byte, map[string]string, error) {
+	return make([]byte, 10), nil, nil
+}
+
+// cancellingValidator cancela o processamento durante a validação do conteúdo, como o ffprobe interrompido
+type cancellingValidator struct {
+	portServices.VideoValidator
+	cancel context.CancelFunc
+}
+
+func (cancellingValidator) ValidateSize(int64, int64) *domain.VideoRejection {
+	return nil
+}
+
+func (v cancellingValidator) ValidateContent(context.Context, []byte) *domain.VideoRejection {
+	v.cancel()
+	return domain.NewVideoRejection(domain.RejectionProbeFailed, "signal: killed")
+}
+
+func TestJobProcessorDoesNotRejectVideoCancelledDuringProbe(t *testing.T) {
+	processor := newTestJobProcessor(&processorFilesRepository{messageIds: make(map[string]bool)}).(*jobProcessor)
+	t.Cleanup(func() {
+		if err := processor.Shutdown(context.Background()); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	processor.bucketRepository = downloadedBucketRepository{}
+	processor.videoValidator = cancellingValidator{cancel: cancel}
+
+	result := processor.processFile(ctx, domain.FilePayload{UserName: "user@test.com", FilePath: "user/video.mp4", FileSize: 10})
+	if result.Message != "processamento cancelado" || result.FailureReason != "" {
+		t.Errorf("Expected cancelled result instead of a rejection, got %+v", result)
+	}
+}
+
+func TestJobProcessorIgnoresReprocessOfFilesFromOtherUsers(t *testing.T) {
+	original := &domain.File{ID: uuid.New(), VideoFilePath: "user/video.mp4"}
+	version := &domain.File{ID: uuid.New(), VideoFilePath: "user/video.mp4", ParentFileID: &original.ID}
+	otherVersion := &domain.File{ID: uuid.New(), VideoFilePath: "other/video.mp4", ParentFileID: &original.ID}
+	unrelated := &domain.File{ID: uuid.New(), VideoFilePath: "user/other.mp4"}
+	reprocess := func(id, fileId uuid.UUID, replaceFileId *uuid.UUID) adapters.Message {
+		payload := fmt.Sprintf(`{"user_name":"user@test.com","file_path":"other/video.mp4","file_size":10,"file_id":%q}`, fileId)
+		if replaceFileId != nil {
+			payload = fmt.Sprintf(`{"user_name":"user@test.com","file_path":"other/video.mp4","file_size":10,"file_id":%q,"replace_file_id":%q}`, fileId, *replaceFileId)
+		}
+		return adapters.Message{ID: id.String(), Value: []byte(payload)}
+	}
+	source := newMemoryJobSource(
+		reprocess(uuid.New(), otherVersion.ID, nil),
+		reprocess(uuid.New(), version.ID, &unrelated.ID),
+		reprocess(uuid.New(), version.ID, &original.ID),
+	)
+	filesRepository := &processorFilesRepository{
+		messageIds: make(map[string]bool),
+		finished:   make(chan *domain.FileProcessingResult, 3),
+		userFiles: map[string]*domain.File{
+			"user@test.com/" + original.ID.String():      original,
+			"user@test.com/" + version.ID.String():       version,
+			"user@test.com/" + unrelated.ID.String():     unrelated,
+			"other@test.com/" + otherVersion.ID.String(): otherVersion,
+		},
+	}
+	bucketRepository := &pathRecordingBucketRepository{}
+	processor := newTestJobProcessor(filesRepository).(*jobProcessor)
+	processor.bucketRepository = bucketRepository
+	runJobProcessor(t, processor, source)
+
+	acked, nacked := waitForAcks(t, source, 3)
+	if len(acked) != 3 || len(nacked) != 0 {
+		t.Errorf("Expected all deliveries to be acked, got acked=%v nacked=%v", acked, nacked)
+	}
+	select {
+	case <-filesRepository.finished:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the reprocess of the user's own file to run")
+	}
+	select {
+	case result := <-filesRepository.finished:
+		t.Errorf("Expected reprocess of files from other users to be ignored, got %+v", result)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if paths := bucketRepository.requested(); len(paths) != 1 || paths[0] != "user/video.mp4" {
+		t.Errorf("Expected the stored video to be processed instead of the message path, got %v", paths)
+	}
+}
+
+// pathRecordingBucketRepository registra os videos consultados e falha o download
+type pathRecordingBucketRepository struct {
+	processorBucketRepository
+	mu    sync.Mutex
+	paths []string
+}
+
+func (r *pathRecordingBucketRepository) GetFileSize(ctx context.Context, path string) (int64, error) {
+	r.mu.Lock()
+	r.paths = append(r.paths, path)
+	r.mu.Unlock()
+	return r.processorBucketRepository.GetFileSize(ctx, path)
+}
+
+func (r *pathRecordingBucketRepository) requested() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.paths...)
+}
+
+func TestJobProcessorRejectsFilesAboveQuota(t *testing.T) {
+	payload := []byte(`{"user_name":"user@test.com","file_path":"user/video.mp4","file_size":10}`)
+	source := newMemoryJobSource(
+		adapters.Message{ID: "first", Value: payload},
+		adapters.Message{ID: "second", Value: payload},
+	)
+	filesRepository := &processorFilesRepository{
+		messageIds: make(map[string]bool),
+		finished:   make(chan *domain.FileProcessingResult, 2),
+	}
+	metrics := &recordingMetrics{}
+	processor := newTestJobProcessorWithMetrics(filesRepository, metrics).(*jobProcessor)
+	processor.quotaService = processorQuotaService{plan: domain.UserPlan{MaxConcurrentJobs: 1}}
+	runJobProcessor(t, processor, source)
+
+	statuses := make(map[int16]int)
+	for i := 0; i < 2; i++ {
+		select {
+		case result := <-filesRepository.finished:
+			statuses[result.Status]++
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Expected both files to be finished, got %v", statuses)
+		}
+	}
+	if statuses[domain.FileStatusRejected] != 1 || statuses[domain.FileStatusError] != 1 {
+		t.Errorf("Expected the second file to be rejected by the quota, got %v", statuses)
+	}
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if !slices.Contains(metrics.finished, jobOutcomeRejected+"/quota_concurrent_jobs") {
+		t.Errorf("Expected the quota rejection to be recorded, got %v", metrics.finished)
+	}
+}
+
+func TestJobProcessorClaimsReprocessedFileBeforeAck(t *testing.T) {
+	file := &domain.File{ID: uuid.New(), VideoFilePath: "user/video.mp4"}
+	payload := fmt.Sprintf(`{"user_name":"user@test.com","file_path":"user/video.mp4","file_size":10,"file_id":%q}`, file.ID)
+	source := newMemoryJobSource(adapters.Message{ID: "video", Value: []byte(payload)})
+	filesRepository := &processorFilesRepository{
+		messageIds:   make(map[string]bool),
+		finished:     make(chan *domain.FileProcessingResult, 1),
+		userFiles:    map[string]*domain.File{"user@test.com/" + file.ID.String(): file},
+		heartbeatErr: errors.New("base indisponível"),
+	}
+	runJobProcessor(t, newTestJobProcessor(filesRepository), source)
+
+	// sem o registro do aceite a mensagem volta ao broker, pois o arquivo não seria recuperado se a réplica caísse
+	acked, nacked := waitForAcks(t, source, 1)
+	if len(acked) != 0 || len(nacked) != 1 {
+		t.Errorf("Expected the delivery to be nacked, got acked=%v nacked=%v", acked, nacked)
+	}
+	filesRepository.mu.Lock()
+	defer filesRepository.mu.Unlock()
+	if len(filesRepository.heartbeats) != 1 || filesRepository.heartbeats[0] != file.ID {
+		t.Errorf("Expected the reprocessed file to be claimed, got %v", filesRepository.heartbeats)
+	}
+}
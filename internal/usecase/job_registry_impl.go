@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+
+	portServices "github.com/backstagefood/video-processor-worker/internal/domain/interface/services"
+	"github.com/google/uuid"
+)
+
+// jobRegistry guarda a função de cancelamento de cada processamento em execução nesta réplica
+type jobRegistry struct {
+	mu   sync.Mutex
+	jobs map[uuid.UUID]context.CancelFunc
+}
+
+func NewJobRegistry() portServices.JobRegistry {
+	return &jobRegistry{
+		jobs: make(map[uuid.UUID]context.CancelFunc),
+	}
+}
+
+func (r *jobRegistry) Register(fileId uuid.UUID, cancel context.CancelFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jobs[fileId] = cancel
+}
+
+func (r *jobRegistry) Unregister(fileId uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.jobs, fileId)
+}
+
+// Cancel sinaliza o cancelamento do processamento, retornando false quando ele não está nesta réplica
+func (r *jobRegistry) Cancel(fileId uuid.UUID) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cancel, ok := r.jobs[fileId]
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+func (r *jobRegistry) RunningJobs() []uuid.UUID {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]uuid.UUID, 0, len(r.jobs))
+	for id := range r.jobs {
+		ids = append(ids, id)
+	}
+	return ids
+}
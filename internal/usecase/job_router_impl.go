@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"sync"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	portServices "github.com/backstagefood/video-processor-worker/internal/domain/interface/services"
+)
+
+const (
+	// cloudEventTypeHeader, typeHeader e schemaHeader identificam o tipo do processamento, nessa ordem de preferência
+	cloudEventTypeHeader = cloudEventsHeaderPrefix + "type"
+	typeHeader           = "type"
+	schemaHeader         = "schema"
+
+	deadLetterReasonHeader  = "dead-letter-reason"
+	deadLetterSourceHeader  = "dead-letter-source"
+	deadLetterMessageHeader = "dead-letter-message-id"
+)
+
+// NewJobRouter cria o roteador que assume defaultType para as mensagens sem tipo
+func NewJobRouter(deadLetterProducer adapters.MessageProducer, defaultType string) portServices.JobRouter {
+	return &jobRouter{
+		handlers:           make(map[string]portServices.JobHandler),
+		defaultType:        defaultType,
+		deadLetterProducer: deadLetterProducer,
+	}
+}
+
+// jobRouter identifica o tipo de cada mensagem pelo header type ou schema, ou pelo campo de mesmo
+// nome no corpo da mensagem, que nos CloudEvents é o atributo type. Mensagens sem tipo são do tipo
+// padrão, mantendo os produtores antigos. As mensagens de tipos sem handler e as recusadas pelo
+// handler como domain.ErrInvalidPayload são publicadas no dead letter
+type jobRouter struct {
+	mu                 sync.RWMutex
+	handlers           map[string]portServices.JobHandler
+	defaultType        string
+	deadLetterProducer adapters.MessageProducer
+}
+
+func (r *jobRouter) Register(jobType string, handler portServices.JobHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[jobType] = handler
+	slog.Info("handler de processamento registrado", "type", jobType)
+}
+
+func (r *jobRouter) Route(ctx context.Context, message *adapters.Message) error {
+	jobType := r.resolveType(message)
+	r.mu.RLock()
+	handler, ok := r.handlers[jobType]
+	r.mu.RUnlock()
+	if !ok {
+		return r.deadLetter(ctx, message, jobType, fmt.Sprintf("%s: %s", domain.ErrUnknownJobType.Error(), jobType))
+	}
+	err := handler.HandleMessage(ctx, message)
+	if errors.Is(err, domain.ErrInvalidPayload) {
+		return r.deadLetter(ctx, message, jobType, err.Error())
+	}
+	return err
+}
+
+func (r *jobRouter) resolveType(message *adapters.Message) string {
+	for _, header := range []string{cloudEventTypeHeader, typeHeader, schemaHeader} {
+		if jobType := message.Header(header); jobType != "" {
+			return jobType
+		}
+	}
+	var fields struct {
+		Type   string `json:"type"`
+		Schema string `json:"schema"`
+	}
+	// corpos que não são um objeto JSON ficam com o tipo padrão e são tratados pelo seu handler
+	if err := json.Unmarshal(message.Value, &fields); err == nil {
+		if fields.Type != "" {
+			return fields.Type
+		}
+		if fields.Schema != "" {
+			return fields.Schema
+		}
+	}
+	return r.defaultType
+}
+
+// deadLetter publica a mensagem mantendo a chave, o corpo e os headers originais. O erro devolve a
+// mensagem para a fila, para que ela não seja perdida quando o dead letter estiver indisponível
+func (r *jobRouter) deadLetter(ctx context.Context, message *adapters.Message, jobType string, reason string) error {
+	headers := maps.Clone(message.Headers)
+	if headers == nil {
+		headers = make(map[string]string)
+	}
+	headers[deadLetterReasonHeader] = reason
+	headers[deadLetterSourceHeader] = message.Topic
+	headers[deadLetterMessageHeader] = message.ID
+
+	if err := r.deadLetterProducer.PublishMessageWithHeaders(ctx, message.Key, message.Value, headers); err != nil {
+		slog.ErrorContext(ctx, "não foi possível publicar a mensagem no dead letter", "messageId", message.ID, "type", jobType, "error", err)
+		return err
+	}
+	slog.WarnContext(ctx, "mensagem enviada para o dead letter", "messageId", message.ID, "topic", message.Topic, "type", jobType, "reason", reason)
+	return nil
+}
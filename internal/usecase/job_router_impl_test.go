@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+)
+
+type recordingHandler struct {
+	messages []string
+}
+
+func (h *recordingHandler) HandleMessage(_ context.Context, message *adapters.Message) error {
+	h.messages = append(h.messages, message.ID)
+	return nil
+}
+
+type failingProducer struct {
+	recordingProducer
+}
+
+func (p *failingProducer) PublishMessageWithHeaders(context.Context, string, []byte, map[string]string) error {
+	return errors.New("broker indisponível")
+}
+
+func TestJobRouterRoutesByType(t *testing.T) {
+	frames, thumbnails := &recordingHandler{}, &recordingHandler{}
+	deadLetter := &recordingProducer{}
+	router := NewJobRouter(deadLetter, domain.JobTypeVideoFrames)
+	router.Register(domain.JobTypeVideoFrames, frames)
+	router.Register("video.thumbnail", thumbnails)
+
+	messages := []*adapters.Message{
+		{ID: "legacy", Value: []byte(`{"user_name":"user@test.com"}`)},
+		{ID: "invalid", Value: []byte("{")},
+		{ID: "header", Value: []byte(`{}`), Headers: map[string]string{"type": "video.thumbnail"}},
+		{ID: "schema-header", Value: []byte(`{}`), Headers: map[string]string{"schema": "video.thumbnail"}},
+		{ID: "field", Value: []byte(`{"type":"video.thumbnail"}`)},
+		{ID: "cloudevent-header", Value: []byte(`{}`), Headers: map[string]string{"ce_type": "video.thumbnail", "type": "video.frames"}},
+		{ID: "schema-field", Value: []byte(`{"schema":"video.frames"}`)},
+		{ID: "header-wins", Value: []byte(`{"type":"video.frames"}`), Headers: map[string]string{"type": "video.thumbnail"}},
+	}
+	for _, message := range messages {
+		if err := router.Route(context.Background(), message); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if got := frames.messages; len(got) != 3 || got[0] != "legacy" || got[1] != "invalid" || got[2] != "schema-field" {
+		t.Errorf("Unexpected frames messages: %v", got)
+	}
+	if got := thumbnails.messages; len(got) != 5 || got[0] != "header" || got[4] != "header-wins" {
+		t.Errorf("Unexpected thumbnail messages: %v", got)
+	}
+	if len(deadLetter.keys) != 0 {
+		t.Errorf("Expected no dead letters, got %v", deadLetter.keys)
+	}
+}
+
+func TestJobRouterSendsUnknownTypesToDeadLetter(t *testing.T) {
+	deadLetter := &recordingProducer{}
+	router := NewJobRouter(deadLetter, domain.JobTypeVideoFrames)
+	router.Register(domain.JobTypeVideoFrames, &recordingHandler{})
+
+	message := &adapters.Message{
+		ID:      "videos/0/42",
+		Key:     "user@test.com",
+		Value:   []byte(`{"type":"audio.extract"}`),
+		Topic:   "videos",
+		Headers: map[string]string{"trace": "abc"},
+	}
+	if err := router.Route(context.Background(), message); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(deadLetter.keys) != 1 || deadLetter.keys[0] != "user@test.com" {
+		t.Fatalf("Expected one dead letter with the original key, got %v", deadLetter.keys)
+	}
+	headers := deadLetter.headers[0]
+	if headers["trace"] != "abc" || headers["dead-letter-source"] != "videos" || headers["dead-letter-message-id"] != "videos/0/42" {
+		t.Errorf("Unexpected dead letter headers: %v", headers)
+	}
+	if headers["dead-letter-reason"] == "" {
+		t.Error("Expected a dead letter reason")
+	}
+	if _, ok := message.Headers["dead-letter-reason"]; ok {
+		t.Error("Expected the original headers to be left untouched")
+	}
+}
+
+type rejectingHandler struct{}
+
+func (rejectingHandler) HandleMessage(context.Context, *adapters.Message) error {
+	return fmt.Errorf("%w: file_path ausente", domain.ErrInvalidPayload)
+}
+
+func TestJobRouterSendsInvalidPayloadsToDeadLetter(t *testing.T) {
+	deadLetter := &recordingProducer{}
+	router := NewJobRouter(deadLetter, domain.JobTypeVideoFrames)
+	router.Register(domain.JobTypeVideoFrames, rejectingHandler{})
+
+	if err := router.Route(context.Background(), &adapters.Message{ID: "1", Key: "user@test.com", Value: []byte(`{}`)}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(deadLetter.headers) != 1 || !strings.Contains(deadLetter.headers[0]["dead-letter-reason"], "file_path ausente") {
+		t.Errorf("Expected the invalid payload in the dead letter, got %v", deadLetter.headers)
+	}
+}
+
+func TestJobRouterReturnsErrorWhenDeadLetterFails(t *testing.T) {
+	router := NewJobRouter(&failingProducer{}, domain.JobTypeVideoFrames)
+
+	err := router.Route(context.Background(), &adapters.Message{ID: "1", Value: []byte(`{"type":"audio.extract"}`)})
+	if err == nil {
+		t.Fatal("Expected error when the dead letter is unavailable")
+	}
+}
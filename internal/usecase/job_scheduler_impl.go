@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	portServices "github.com/backstagefood/video-processor-worker/internal/domain/interface/services"
+)
+
+// classQueue mantém uma fila por usuário dentro da classe de prioridade, atendidos em round-robin
+type classQueue struct {
+	weight        int
+	currentWeight int
+	users         []string
+	jobs          map[string][]*domain.ScheduledJob
+	depth         int
+}
+
+func (q *classQueue) push(job *domain.ScheduledJob) {
+	if len(q.jobs[job.UserKey]) == 0 {
+		q.users = append(q.users, job.UserKey)
+	}
+	q.jobs[job.UserKey] = append(q.jobs[job.UserKey], job)
+	q.depth++
+}
+
+func (q *classQueue) pop() *domain.ScheduledJob {
+	userKey := q.users[0]
+	job := q.jobs[userKey][0]
+	q.jobs[userKey] = q.jobs[userKey][1:]
+	q.users = q.users[1:]
+	if len(q.jobs[userKey]) > 0 {
+		// o usuário volta para o fim da fila para que os demais sejam atendidos antes
+		q.users = append(q.users, userKey)
+	} else {
+		delete(q.jobs, userKey)
+	}
+	q.depth--
+	return job
+}
+
+// jobScheduler distribui os processamentos entre as classes de prioridade com weighted round-robin
+// suave e, dentro de cada classe, entre os usuários com round-robin. A fila não bloqueia quem envia;
+// ao atingir maxQueued o agendador fica saturado e a entrada de mensagens deve ser pausada
+type jobScheduler struct {
+	mu        sync.Mutex
+	classes   map[string]*classQueue
+	order     []string
+	depth     int
+	maxQueued int
+	ready     chan struct{}
+	released  chan struct{}
+	metrics   adapters.Metrics
+}
+
+func NewJobScheduler(weights map[string]int, maxQueued int, metrics adapters.Metrics) portServices.JobScheduler {
+	scheduler := &jobScheduler{
+		classes:   make(map[string]*classQueue),
+		maxQueued: max(maxQueued, 1),
+		ready:     make(chan struct{}, 1),
+		released:  make(chan struct{}, 1),
+		metrics:   metrics,
+	}
+	for _, class := range []string{domain.PriorityHigh, domain.PriorityNormal, domain.PriorityLow} {
+		scheduler.classes[class] = &classQueue{weight: classWeight(weights, class), jobs: make(map[string][]*domain.ScheduledJob)}
+		scheduler.order = append(scheduler.order, class)
+	}
+	return scheduler
+}
+
+// classWeight retorna o peso da classe, usando 1 para as classes sem peso informado
+func classWeight(weights map[string]int, class string) int {
+	if weight := weights[class]; weight > 0 {
+		return weight
+	}
+	return 1
+}
+
+// Resize altera os pesos das classes e o limite da fila. Os processamentos já agendados continuam na fila,
+// mesmo que passem do novo limite; o consumo só é retomado quando a fila baixar
+func (s *jobScheduler) Resize(weights map[string]int, maxQueued int) {
+	s.mu.Lock()
+	s.maxQueued = max(maxQueued, 1)
+	for class, queue := range s.classes {
+		queue.weight = classWeight(weights, class)
+		queue.currentWeight = 0
+	}
+	s.mu.Unlock()
+
+	// quem aguarda capacidade reavalia a fila com o novo limite
+	notify(s.released)
+}
+
+// Submit coloca o processamento na fila do usuário
+func (s *jobScheduler) Submit(ctx context.Context, job *domain.ScheduledJob) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	job.PriorityClass = domain.ResolvePriorityClass(job.PriorityClass, "")
+	job.EnqueuedAt = time.Now()
+	queue := s.classes[job.PriorityClass]
+	queue.push(job)
+	s.depth++
+	s.metrics.SchedulerQueueDepth(job.PriorityClass, queue.depth)
+	s.mu.Unlock()
+
+	notify(s.ready)
+	return nil
+}
+
+// Next aguarda e retorna o próximo processamento a ser executado. Com o contexto cancelado nenhum
+// processamento é retirado, e os que aguardam continuam na fila
+func (s *jobScheduler) Next(ctx context.Context) (*domain.ScheduledJob, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		if s.depth > 0 {
+			queue := s.nextClass()
+			job := queue.pop()
+			s.depth--
+			remaining := s.depth
+			s.metrics.SchedulerQueueDepth(job.PriorityClass, queue.depth)
+			s.mu.Unlock()
+
+			// repassa o aviso para outro worker livre enquanto houver processamentos na fila
+			if remaining > 0 {
+				notify(s.ready)
+			}
+			notify(s.released)
+			s.metrics.SchedulerWaitTime(job.PriorityClass, time.Since(job.EnqueuedAt))
+			return job, nil
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-s.ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (s *jobScheduler) Depth() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.depth
+}
+
+// Saturated indica que a fila atingiu maxQueued e novas mensagens não devem ser buscadas
+func (s *jobScheduler) Saturated() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.depth >= s.maxQueued
+}
+
+// WaitForCapacity aguarda a fila baixar até a metade de maxQueued, evitando pausar e retomar
+// o consumo a cada processamento finalizado
+func (s *jobScheduler) WaitForCapacity(ctx context.Context) error {
+	for !s.hasCapacity() {
+		select {
+		case <-s.released:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (s *jobScheduler) hasCapacity() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.depth <= s.maxQueued/2
+}
+
+func notify(signal chan struct{}) {
+	select {
+	case signal <- struct{}{}:
+	default:
+	}
+}
+
+// nextClass escolhe a classe com weighted round-robin suave entre as classes com processamentos na fila
+func (s *jobScheduler) nextClass() *classQueue {
+	var selected *classQueue
+	totalWeight := 0
+	for _, class := range s.order {
+		queue := s.classes[class]
+		if queue.depth == 0 {
+			continue
+		}
+		queue.currentWeight += queue.weight
+		totalWeight += queue.weight
+		if selected == nil || queue.currentWeight > selected.currentWeight {
+			selected = queue
+		}
+	}
+	selected.currentWeight -= totalWeight
+	return selected
+}
@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	portServices "github.com/backstagefood/video-processor-worker/internal/domain/interface/services"
+)
+
+type noopMetrics struct{}
+
+func (noopMetrics) ObjectsExpired(string, int)                     {}
+func (noopMetrics) BytesExpired(string, int64)                     {}
+func (noopMetrics) SchedulerQueueDepth(string, int)                {}
+func (noopMetrics) SchedulerWaitTime(string, time.Duration)        {}
+func (noopMetrics) JobFinished(string, string)                     {}
+func (noopMetrics) StageDuration(string, time.Duration)            {}
+func (noopMetrics) JobBytes(string, int64)                         {}
+func (noopMetrics) FramesExtracted(int)                            {}
+func (noopMetrics) FFmpegExitCode(int)                             {}
+func (noopMetrics) JobsInFlight(int)                               {}
+func (noopMetrics) WorkerSlots(int, int)                           {}
+func (noopMetrics) ConsumerLag(string, int32, int64)               {}
+func (noopMetrics) HTTPRequest(string, string, int, time.Duration) {}
+
+func submitJobs(t *testing.T, scheduler portServices.JobScheduler, userKey, priorityClass string, count int) {
+	for i := 0; i < count; i++ {
+		if err := scheduler.Submit(context.Background(), &domain.ScheduledJob{UserKey: userKey, PriorityClass: priorityClass}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+}
+
+func TestJobSchedulerRoundRobinBetweenUsers(t *testing.T) {
+	scheduler := NewJobScheduler(map[string]int{}, 10, noopMetrics{})
+	submitJobs(t, scheduler, "a", domain.PriorityNormal, 5)
+	submitJobs(t, scheduler, "b", domain.PriorityNormal, 1)
+
+	var order []string
+	for i := 0; i < 6; i++ {
+		job, err := scheduler.Next(context.Background())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		order = append(order, job.UserKey)
+	}
+
+	if order[1] != "b" {
+		t.Errorf("Expected user b to be served second, got order %v", order)
+	}
+}
+
+func TestJobSchedulerWeightedClasses(t *testing.T) {
+	scheduler := NewJobScheduler(map[string]int{domain.PriorityHigh: 3, domain.PriorityLow: 1}, 20, noopMetrics{})
+	submitJobs(t, scheduler, "a", domain.PriorityLow, 4)
+	submitJobs(t, scheduler, "b", domain.PriorityHigh, 4)
+
+	counts := map[string]int{}
+	for i := 0; i < 4; i++ {
+		job, err := scheduler.Next(context.Background())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		counts[job.PriorityClass]++
+	}
+
+	if counts[domain.PriorityHigh] != 3 || counts[domain.PriorityLow] != 1 {
+		t.Errorf("Expected 3 high and 1 low jobs, got %v", counts)
+	}
+}
+
+func TestJobSchedulerSaturation(t *testing.T) {
+	scheduler := NewJobScheduler(map[string]int{}, 4, noopMetrics{})
+	submitJobs(t, scheduler, "a", domain.PriorityNormal, 4)
+
+	if !scheduler.Saturated() {
+		t.Fatalf("Expected scheduler to be saturated with depth %d", scheduler.Depth())
+	}
+
+	released := make(chan error, 1)
+	go func() {
+		released <- scheduler.WaitForCapacity(context.Background())
+	}()
+	for i := 0; i < 2; i++ {
+		if _, err := scheduler.Next(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	select {
+	case err := <-released:
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected capacity to be released after the queue drained to half")
+	}
+	if scheduler.Saturated() {
+		t.Errorf("Expected scheduler not to be saturated with depth %d", scheduler.Depth())
+	}
+}
+
+func TestJobSchedulerNextHonoursContext(t *testing.T) {
+	scheduler := NewJobScheduler(map[string]int{}, 1, noopMetrics{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := scheduler.Next(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected next to wait for a job, got %v", err)
+	}
+}
+
+func TestJobSchedulerResize(t *testing.T) {
+	scheduler := NewJobScheduler(map[string]int{}, 2, noopMetrics{})
+	submitJobs(t, scheduler, "a", domain.PriorityNormal, 2)
+	if !scheduler.Saturated() {
+		t.Fatal("Expected scheduler to be saturated")
+	}
+
+	waited := make(chan error, 1)
+	go func() {
+		waited <- scheduler.WaitForCapacity(context.Background())
+	}()
+	scheduler.Resize(map[string]int{domain.PriorityHigh: 5}, 4)
+
+	if scheduler.Saturated() {
+		t.Error("Expected scheduler not to be saturated after resize")
+	}
+	select {
+	case err := <-waited:
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected WaitForCapacity to return after resize")
+	}
+	if depth := scheduler.Depth(); depth != 2 {
+		t.Errorf("Expected queued jobs to be kept, got depth %d", depth)
+	}
+}
+
+func TestJobSchedulerNextKeepsQueuedJobsWhenCancelled(t *testing.T) {
+	scheduler := NewJobScheduler(map[string]int{}, 10, noopMetrics{})
+	submitJobs(t, scheduler, "a", domain.PriorityNormal, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if job, err := scheduler.Next(ctx); !errors.Is(err, context.Canceled) || job != nil {
+		t.Fatalf("Expected cancelled context error, got job %v and error %v", job, err)
+	}
+	if depth := scheduler.Depth(); depth != 2 {
+		t.Errorf("Expected queued jobs to stay in the queue, got depth %d", depth)
+	}
+}
@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	portRepositories "github.com/backstagefood/video-processor-worker/internal/domain/interface/repositories"
+	portServices "github.com/backstagefood/video-processor-worker/internal/domain/interface/services"
+	"github.com/google/uuid"
+)
+
+type jobsService struct {
+	filesRepository portRepositories.FilesRepository
+	jobRegistry     portServices.JobRegistry
+}
+
+func NewJobsService(filesRepository portRepositories.FilesRepository, jobRegistry portServices.JobRegistry) portServices.JobsService {
+	return &jobsService{
+		filesRepository: filesRepository,
+		jobRegistry:     jobRegistry,
+	}
+}
+
+func (j *jobsService) CancelJob(ctx context.Context, userEmail string, fileId uuid.UUID) error {
+	file, err := j.filesRepository.FindFileByID(ctx, fileId, userEmail)
+	if err != nil {
+		return err
+	}
+	if file.IsDeleted() {
+		return domain.ErrFileNotFound
+	}
+	if file.FileStatus.ID != domain.FileStatusReceived && file.FileStatus.ID != domain.FileStatusProcessing {
+		return domain.ErrJobNotCancellable
+	}
+
+	cancelled, err := j.filesRepository.CancelFile(ctx, fileId, userEmail)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return domain.ErrJobNotCancellable
+	}
+
+	// o processamento pode estar em outra réplica, que será avisada pelo watcher de cancelamentos
+	if j.jobRegistry.Cancel(fileId) {
+		slog.Info("processamento cancelado nesta réplica", "fileId", fileId)
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	portServices "github.com/backstagefood/video-processor-worker/internal/domain/interface/services"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// dataSchemaVersion extrai a versão do final do dataschema, como em .../video.frames/v2.json
+var dataSchemaVersion = regexp.MustCompile(`/v(\d+)(?:\.json)?$`)
+
+func NewPayloadDecoder() portServices.PayloadDecoder {
+	return &payloadDecoder{payloads: make(map[string]*versionedPayload)}
+}
+
+type payloadDecoder struct {
+	mu       sync.RWMutex
+	payloads map[string]*versionedPayload
+	formats  []adapters.PayloadFormat
+}
+
+// versionedPayload guarda os schemas e os conversores de um tipo de processamento
+type versionedPayload struct {
+	schemas    map[int]*jsonschema.Schema
+	converters map[int]portServices.PayloadConverter
+	latest     int
+}
+
+func (d *payloadDecoder) payload(jobType string) *versionedPayload {
+	payload, ok := d.payloads[jobType]
+	if !ok {
+		payload = &versionedPayload{schemas: make(map[int]*jsonschema.Schema), converters: make(map[int]portServices.PayloadConverter)}
+		d.payloads[jobType] = payload
+	}
+	return payload
+}
+
+// RegisterSchema compila o JSON Schema da versão. A maior versão registrada é a versão atual do tipo
+func (d *payloadDecoder) RegisterSchema(jobType string, version int, schema []byte) error {
+	document, err := jsonschema.UnmarshalJSON(bytes.NewReader(schema))
+	if err != nil {
+		return fmt.Errorf("schema %s v%d inválido: %w", jobType, version, err)
+	}
+	location := fmt.Sprintf("urn:video-processor-worker:%s:v%d", jobType, version)
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(location, document); err != nil {
+		return fmt.Errorf("schema %s v%d inválido: %w", jobType, version, err)
+	}
+	compiled, err := compiler.Compile(location)
+	if err != nil {
+		return fmt.Errorf("schema %s v%d inválido: %w", jobType, version, err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	payload := d.payload(jobType)
+	payload.schemas[version] = compiled
+	payload.latest = max(payload.latest, version)
+	return nil
+}
+
+// RegisterFormat adiciona um formato de serialização, consultado na ordem de registro antes do JSON
+func (d *payloadDecoder) RegisterFormat(format adapters.PayloadFormat) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.formats = append(d.formats, format)
+}
+
+func (d *payloadDecoder) RegisterConverter(jobType string, fromVersion int, converter portServices.PayloadConverter) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.payload(jobType).converters[fromVersion] = converter
+}
+
+// Decode lê o conteúdo da mensagem, que pode ser um CloudEvent ou o conteúdo puro usado antes dos
+// CloudEvents, valida-o contra o schema da versão informada no dataschema e o converte até a versão
+// atual antes de preenchê-lo no target. Mensagens sem dataschema estão na versão atual. Os erros
+// de conteúdo são domain.ErrInvalidPayload, pois a mensagem nunca poderá ser processada
+func (d *payloadDecoder) Decode(ctx context.Context, jobType string, message *adapters.Message, target any) error {
+	d.mu.RLock()
+	payload, ok := d.payloads[jobType]
+	formats := d.formats
+	d.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("nenhum schema registrado para o tipo %s", jobType)
+	}
+
+	event, err := parseCloudEvent(message)
+	if err != nil {
+		return err
+	}
+	data, contentType, version := message.Value, message.Header(contentTypeHeader), payload.latest
+	if event != nil {
+		data, contentType = event.Data, event.DataContentType
+		if event.DataSchema != "" {
+			if version, err = parseDataSchemaVersion(event.DataSchema); err != nil {
+				return err
+			}
+		}
+	}
+
+	document, err := decodeDocument(ctx, formats, contentType, data)
+	if err != nil {
+		return err
+	}
+	if event == nil {
+		document = withoutRoutingFields(document)
+	}
+	if document, err = payload.upgrade(document, version); err != nil {
+		return err
+	}
+
+	encoded, err := json.Marshal(document)
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidPayload, err)
+	}
+	if err := json.Unmarshal(encoded, target); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidPayload, err)
+	}
+	return nil
+}
+
+// decodeDocument converte o conteúdo no documento JSON usado na validação, pelo primeiro formato que
+// o aceitar ou como JSON. O resultado dos formatos é normalizado pelo próprio JSON, removendo os campos
+// nulos, que nos formatos binários representam campos não informados
+func decodeDocument(ctx context.Context, formats []adapters.PayloadFormat, contentType string, data []byte) (any, error) {
+	for _, format := range formats {
+		if !format.Accepts(contentType, data) {
+			continue
+		}
+		decoded, err := format.Decode(ctx, data)
+		if errors.Is(err, adapters.ErrUndecodablePayload) {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidPayload, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		encoded, err := json.Marshal(withoutNulls(decoded))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidPayload, err)
+		}
+		data, contentType = encoded, ""
+		break
+	}
+
+	if err := checkJSONContentType(contentType); err != nil {
+		return nil, err
+	}
+	document, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidPayload, err)
+	}
+	return document, nil
+}
+
+func withoutNulls(document any) any {
+	switch value := document.(type) {
+	case map[string]any:
+		for key, field := range value {
+			if field == nil {
+				delete(value, key)
+				continue
+			}
+			value[key] = withoutNulls(field)
+		}
+	case []any:
+		for i, item := range value {
+			value[i] = withoutNulls(item)
+		}
+	}
+	return document
+}
+
+// upgrade valida o documento na versão recebida e aplica os conversores até a versão atual,
+// validando novamente o resultado de cada conversão
+func (p *versionedPayload) upgrade(document any, version int) (any, error) {
+	schema, ok := p.schemas[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: versão %d do payload não suportada", domain.ErrInvalidPayload, version)
+	}
+	if err := schema.Validate(document); err != nil {
+		return nil, fmt.Errorf("%w: payload v%d não corresponde ao schema: %v", domain.ErrInvalidPayload, version, err)
+	}
+	for ; version < p.latest; version++ {
+		converter, ok := p.converters[version]
+		if !ok {
+			return nil, fmt.Errorf("nenhum conversor registrado da versão %d do payload", version)
+		}
+		fields, ok := document.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: payload v%d não é um objeto", domain.ErrInvalidPayload, version)
+		}
+		converted, err := converter(fields)
+		if err != nil {
+			return nil, fmt.Errorf("%w: não foi possível converter o payload v%d: %v", domain.ErrInvalidPayload, version, err)
+		}
+		if next, ok := p.schemas[version+1]; ok {
+			if err := next.Validate(converted); err != nil {
+				return nil, fmt.Errorf("conversão do payload v%d gerou um payload inválido: %w", version, err)
+			}
+		}
+		document = converted
+	}
+	return document, nil
+}
+
+func parseDataSchemaVersion(dataSchema string) (int, error) {
+	match := dataSchemaVersion.FindStringSubmatch(dataSchema)
+	if match == nil {
+		return 0, fmt.Errorf("%w: dataschema sem versão: %s", domain.ErrInvalidPayload, dataSchema)
+	}
+	version, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, fmt.Errorf("%w: dataschema com versão inválida: %s", domain.ErrInvalidPayload, dataSchema)
+	}
+	return version, nil
+}
+
+// checkJSONContentType aceita o conteúdo JSON, informado como application/json, um tipo com o sufixo
+// +json ou sem datacontenttype, que na especificação indica JSON
+func checkJSONContentType(contentType string) error {
+	if contentType == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")) {
+		return nil
+	}
+	return fmt.Errorf("%w: datacontenttype não suportado: %s", domain.ErrInvalidPayload, contentType)
+}
+
+// withoutRoutingFields remove os campos type e schema usados pelo JobRouter nas mensagens sem
+// CloudEvents, que não fazem parte do payload
+func withoutRoutingFields(document any) any {
+	fields, ok := document.(map[string]any)
+	if !ok {
+		return document
+	}
+	delete(fields, "type")
+	delete(fields, "schema")
+	return fields
+}
@@ -0,0 +1,221 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+)
+
+const (
+	dataSchemaV1 = "https://backstagefood.github.io/video-processor-worker/schemas/video.frames/v1.json"
+	dataSchemaV2 = "https://backstagefood.github.io/video-processor-worker/schemas/video.frames/v2.json"
+)
+
+func newTestPayloadDecoder(t *testing.T) *payloadDecoder {
+	t.Helper()
+	decoder := NewPayloadDecoder().(*payloadDecoder)
+	if err := registerVideoFramesPayload(decoder); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return decoder
+}
+
+func TestPayloadDecoderDecodesSupportedFormats(t *testing.T) {
+	v1Data := `{"user_name":"user@test.com","file_path":"user/video.mp4","file_size":10}`
+	tests := []struct {
+		name        string
+		message     adapters.Message
+		wantVersion int
+		wantFPS     float64
+	}{
+		{
+			name:    "plain json",
+			message: adapters.Message{Value: []byte(`{"type":"video.frames","user_name":"user@test.com","file_path":"user/video.mp4","file_size":10,"extraction_options":{"fps":2}}`)},
+			wantFPS: 2,
+		},
+		{
+			name:        "structured v1 upgraded",
+			message:     adapters.Message{Value: []byte(`{"specversion":"1.0","id":"1","source":"/api","type":"video.frames","dataschema":"` + dataSchemaV1 + `","data":` + v1Data + `}`)},
+			wantVersion: 1,
+		},
+		{
+			name: "structured with content type header and base64 data",
+			message: adapters.Message{
+				Headers: map[string]string{"content-type": "application/cloudevents+json; charset=utf-8"},
+				Value:   []byte(`{"specversion":"1.0","id":"1","source":"/api","type":"video.frames","datacontenttype":"application/json","dataschema":"` + dataSchemaV2 + `","data_base64":"` + base64.StdEncoding.EncodeToString([]byte(`{"user_name":"user@test.com","file_path":"user/video.mp4","file_size":10,"version":3}`)) + `"}`),
+			},
+			wantVersion: 3,
+		},
+		{
+			name: "binary v2",
+			message: adapters.Message{
+				Headers: map[string]string{
+					"ce_specversion": "1.0",
+					"ce_id":          "1",
+					"ce_source":      "/api",
+					"ce_type":        "video.frames",
+					"ce_dataschema":  dataSchemaV2,
+					"content-type":   "application/json",
+				},
+				Value: []byte(`{"user_name":"user@test.com","file_path":"user/video.mp4","file_size":10,"priority":"high"}`),
+			},
+		},
+		{
+			name: "binary v1 upgraded",
+			message: adapters.Message{
+				Headers: map[string]string{"ce_specversion": "1.0", "ce_id": "1", "ce_source": "/api", "ce_type": "video.frames", "ce_dataschema": dataSchemaV1},
+				Value:   []byte(v1Data),
+			},
+			wantVersion: 1,
+		},
+	}
+
+	decoder := newTestPayloadDecoder(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload domain.FilePayload
+			if err := decoder.Decode(context.Background(), domain.JobTypeVideoFrames, &tt.message, &payload); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if payload.UserName != "user@test.com" || payload.FilePath != "user/video.mp4" || payload.FileSize != 10 {
+				t.Errorf("Unexpected payload: %+v", payload)
+			}
+			if payload.Version != tt.wantVersion {
+				t.Errorf("Expected version %d, got %d", tt.wantVersion, payload.Version)
+			}
+			if tt.wantFPS > 0 && (payload.ExtractionOptions == nil || payload.ExtractionOptions.FPS != tt.wantFPS) {
+				t.Errorf("Expected fps %v, got %+v", tt.wantFPS, payload.ExtractionOptions)
+			}
+		})
+	}
+}
+
+func TestPayloadDecoderRejectsInvalidPayloads(t *testing.T) {
+	binaryHeaders := func(dataSchema string) map[string]string {
+		return map[string]string{"ce_specversion": "1.0", "ce_id": "1", "ce_source": "/api", "ce_type": "video.frames", "ce_dataschema": dataSchema}
+	}
+	tests := []struct {
+		name    string
+		message adapters.Message
+		wantErr string
+	}{
+		{"invalid json", adapters.Message{Value: []byte("{")}, ""},
+		{"missing file path", adapters.Message{Value: []byte(`{"user_name":"user@test.com","file_size":10}`)}, "file_path"},
+		{"unknown field", adapters.Message{Value: []byte(`{"user_name":"user@test.com","file_path":"a.mp4","file_size":10,"fps":2}`)}, "fps"},
+		{"v1 with v2 fields", adapters.Message{Headers: binaryHeaders(dataSchemaV1), Value: []byte(`{"user_name":"user@test.com","file_path":"a.mp4","file_size":10,"version":2}`)}, "version"},
+		{"invalid priority", adapters.Message{Value: []byte(`{"user_name":"user@test.com","file_path":"a.mp4","file_size":10,"priority":"urgent"}`)}, "priority"},
+		{"unsupported version", adapters.Message{Headers: binaryHeaders("https://example.com/video.frames/v9.json"), Value: []byte(`{}`)}, "versão 9"},
+		{"dataschema without version", adapters.Message{Headers: binaryHeaders("https://example.com/video.frames.json"), Value: []byte(`{}`)}, "dataschema sem versão"},
+		{"unsupported specversion", adapters.Message{Value: []byte(`{"specversion":"0.3","id":"1","source":"/api","type":"video.frames","data":{}}`)}, "specversion"},
+		{"missing attributes", adapters.Message{Value: []byte(`{"specversion":"1.0","type":"video.frames","data":{}}`)}, "id, source"},
+		{"unsupported content type", adapters.Message{Value: []byte(`{"specversion":"1.0","id":"1","source":"/api","type":"video.frames","datacontenttype":"application/xml","data":{}}`)}, "datacontenttype"},
+		{"data and data_base64", adapters.Message{Value: []byte(`{"specversion":"1.0","id":"1","source":"/api","type":"video.frames","data":{},"data_base64":"e30="}`)}, "data_base64"},
+	}
+
+	decoder := newTestPayloadDecoder(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload domain.FilePayload
+			err := decoder.Decode(context.Background(), domain.JobTypeVideoFrames, &tt.message, &payload)
+			if !errors.Is(err, domain.ErrInvalidPayload) {
+				t.Fatalf("Expected ErrInvalidPayload, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestPayloadDecoderRequiresConverterChain(t *testing.T) {
+	decoder := NewPayloadDecoder()
+	for version, schema := range map[int]string{1: `{"type":"object"}`, 2: `{"type":"object"}`} {
+		if err := decoder.RegisterSchema("audio.extract", version, []byte(schema)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	message := &adapters.Message{
+		Headers: map[string]string{"ce_specversion": "1.0", "ce_id": "1", "ce_source": "/api", "ce_type": "audio.extract", "ce_dataschema": "audio.extract/v1"},
+		Value:   []byte(`{}`),
+	}
+
+	var payload map[string]any
+	err := decoder.Decode(context.Background(), "audio.extract", message, &payload)
+	if err == nil || errors.Is(err, domain.ErrInvalidPayload) {
+		t.Fatalf("Expected a configuration error for the missing converter, got %v", err)
+	}
+}
+
+// fakePayloadFormat aceita o conteúdo iniciado pelo byte zero, como o wire format dos registries
+type fakePayloadFormat struct {
+	document any
+	err      error
+}
+
+func (f *fakePayloadFormat) Accepts(_ string, data []byte) bool {
+	return len(data) > 0 && data[0] == 0
+}
+
+func (f *fakePayloadFormat) Decode(_ context.Context, _ []byte) (any, error) {
+	return f.document, f.err
+}
+
+func TestPayloadDecoderUsesRegisteredFormats(t *testing.T) {
+	transientErr := errors.New("registry indisponível")
+	tests := []struct {
+		name    string
+		format  *fakePayloadFormat
+		message adapters.Message
+		wantErr error
+	}{
+		{
+			name:    "binary payload",
+			format:  &fakePayloadFormat{document: map[string]any{"user_name": "user@test.com", "file_path": "user/video.mp4", "file_size": int64(10), "priority": nil}},
+			message: adapters.Message{Headers: map[string]string{"content-type": "application/vnd.apache.avro+binary"}, Value: []byte{0, 1}},
+		},
+		{
+			name:    "json payload is not handed to the format",
+			format:  &fakePayloadFormat{err: transientErr},
+			message: adapters.Message{Value: []byte(`{"user_name":"user@test.com","file_path":"user/video.mp4","file_size":10}`)},
+		},
+		{
+			name:    "undecodable payload",
+			format:  &fakePayloadFormat{err: adapters.ErrUndecodablePayload},
+			message: adapters.Message{Value: []byte{0, 1}},
+			wantErr: domain.ErrInvalidPayload,
+		},
+		{
+			name:    "transient failure",
+			format:  &fakePayloadFormat{err: transientErr},
+			message: adapters.Message{Value: []byte{0, 1}},
+			wantErr: transientErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := newTestPayloadDecoder(t)
+			decoder.RegisterFormat(tt.format)
+
+			var payload domain.FilePayload
+			err := decoder.Decode(context.Background(), domain.JobTypeVideoFrames, &tt.message, &payload)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if payload.UserName != "user@test.com" || payload.FileSize != 10 {
+				t.Errorf("Unexpected payload: %+v", payload)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	portRepositories "github.com/backstagefood/video-processor-worker/internal/domain/interface/repositories"
+	portServices "github.com/backstagefood/video-processor-worker/internal/domain/interface/services"
+	"github.com/backstagefood/video-processor-worker/pkg/config"
+	"github.com/google/uuid"
+)
+
+type quotaService struct {
+	userPlansRepository portRepositories.UserPlansRepository
+	filesRepository     portRepositories.FilesRepository
+	usersRepository     portRepositories.UsersRepository
+	defaults            config.QuotaConfig
+}
+
+func NewQuotaService(
+	userPlansRepository portRepositories.UserPlansRepository,
+	filesRepository portRepositories.FilesRepository,
+	usersRepository portRepositories.UsersRepository,
+	defaults config.QuotaConfig,
+) portServices.QuotaService {
+	return &quotaService{
+		userPlansRepository: userPlansRepository,
+		filesRepository:     filesRepository,
+		usersRepository:     usersRepository,
+		defaults:            defaults,
+	}
+}
+
+func (q *quotaService) QuotaCheck(ctx context.Context, userId uuid.UUID, incomingBytes int64) (domain.QuotaCheck, error) {
+	plan, err := q.findPlan(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	return func(usage domain.QuotaUsage) *domain.QuotaExceeded {
+		return plan.Check(usage, incomingBytes)
+	}, nil
+}
+
+func (q *quotaService) GetPlan(ctx context.Context, userId uuid.UUID) (*domain.UserPlan, error) {
+	return q.findPlan(ctx, userId)
+}
+
+func (q *quotaService) GetUsage(ctx context.Context, userEmail string) (*domain.UserPlan, *domain.QuotaUsage, error) {
+	user, err := q.usersRepository.FindUserByEmail(ctx, userEmail)
+	if err != nil {
+		return nil, nil, err
+	}
+	plan, err := q.findPlan(ctx, user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	usage, err := q.filesRepository.GetUserUsage(ctx, user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return plan, usage, nil
+}
+
+// findPlan obtém o plano do usuário, usando os limites padrão para usuários sem plano cadastrado
+func (q *quotaService) findPlan(ctx context.Context, userId uuid.UUID) (*domain.UserPlan, error) {
+	plan, err := q.userPlansRepository.FindPlanByUserID(ctx, userId)
+	if errors.Is(err, domain.ErrUserPlanNotFound) {
+		return defaultUserPlan(q.defaults), nil
+	}
+	return plan, err
+}
+
+func defaultUserPlan(defaults config.QuotaConfig) *domain.UserPlan {
+	return &domain.UserPlan{
+		PlanName:          "default",
+		MaxConcurrentJobs: defaults.DefaultMaxConcurrentJobs,
+		MaxJobsPerDay:     defaults.DefaultMaxJobsPerDay,
+		MaxBytesPerMonth:  defaults.DefaultMaxBytesPerMonth,
+		MaxStoredBytes:    defaults.DefaultMaxStoredBytes,
+		PriorityClass:     domain.PriorityNormal,
+	}
+}
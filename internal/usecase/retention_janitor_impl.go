@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	portRepositories "github.com/backstagefood/video-processor-worker/internal/domain/interface/repositories"
+	portServices "github.com/backstagefood/video-processor-worker/internal/domain/interface/services"
+	"github.com/backstagefood/video-processor-worker/pkg/config"
+)
+
+const (
+	retentionJanitorLock      = "retention-janitor"
+	retentionJanitorBatchSize = 100
+)
+
+type retentionJanitor struct {
+	filesRepository  portRepositories.FilesRepository
+	bucketRepository portRepositories.BucketRepository
+	lock             adapters.DistributedLock
+	metrics          adapters.Metrics
+	settings         config.RetentionConfig
+}
+
+func NewRetentionJanitor(
+	filesRepository portRepositories.FilesRepository,
+	bucketRepository portRepositories.BucketRepository,
+	lock adapters.DistributedLock,
+	metrics adapters.Metrics,
+	settings config.RetentionConfig,
+) portServices.RetentionJanitor {
+	return &retentionJanitor{
+		filesRepository:  filesRepository,
+		bucketRepository: bucketRepository,
+		lock:             lock,
+		metrics:          metrics,
+		settings:         settings,
+	}
+}
+
+// Run executa a limpeza periodicamente até o contexto ser cancelado. Somente a réplica que
+// obtiver o advisory lock executa cada rodada
+func (j *retentionJanitor) Run(ctx context.Context) {
+	interval := j.settings.JanitorInterval
+	if interval <= 0 {
+		slog.Info("limpeza de arquivos expirados desabilitada")
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		acquired, err := j.lock.TryWithLock(ctx, retentionJanitorLock, j.expire)
+		if err != nil {
+			slog.Error("erro na limpeza de arquivos expirados", "error", err)
+		} else if !acquired {
+			slog.Info("limpeza de arquivos expirados em execução em outra réplica")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *retentionJanitor) expire(ctx context.Context) error {
+	if err := j.expireZipFiles(ctx, j.settings.ZipDays); err != nil {
+		return err
+	}
+	return j.expireVideos(ctx, j.settings.VideoDays)
+}
+
+func (j *retentionJanitor) expireZipFiles(ctx context.Context, retentionDays int) error {
+	for ctx.Err() == nil {
+		files, err := j.filesRepository.ListExpiredZipFiles(ctx, retentionDays, retentionJanitorBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			if err := j.bucketRepository.DeleteFile(ctx, *file.ZipFilePath); err != nil {
+				return err
+			}
+			if err := j.filesRepository.MarkZipExpired(ctx, file.ID); err != nil {
+				return err
+			}
+			j.metrics.ObjectsExpired("zip", 1)
+			if file.ZipFileSize != nil {
+				j.metrics.BytesExpired("zip", *file.ZipFileSize)
+			}
+		}
+		if len(files) > 0 {
+			slog.Info("arquivos zip expirados removidos", "total", len(files))
+		}
+		if len(files) < retentionJanitorBatchSize {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
+
+func (j *retentionJanitor) expireVideos(ctx context.Context, retentionDays int) error {
+	for ctx.Err() == nil {
+		videos, err := j.filesRepository.ListExpiredVideos(ctx, retentionDays, retentionJanitorBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, video := range videos {
+			if err := j.bucketRepository.DeleteFile(ctx, video.VideoFilePath); err != nil {
+				return err
+			}
+			if err := j.filesRepository.MarkVideoExpired(ctx, video.FileIDs); err != nil {
+				return err
+			}
+			j.metrics.ObjectsExpired("video", 1)
+			j.metrics.BytesExpired("video", video.VideoFileSize)
+		}
+		if len(videos) > 0 {
+			slog.Info("videos expirados removidos", "total", len(videos))
+		}
+		if len(videos) < retentionJanitorBatchSize {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
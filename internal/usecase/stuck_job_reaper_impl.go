@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	portRepositories "github.com/backstagefood/video-processor-worker/internal/domain/interface/repositories"
+	portServices "github.com/backstagefood/video-processor-worker/internal/domain/interface/services"
+	"github.com/backstagefood/video-processor-worker/pkg/config"
+)
+
+const (
+	stuckJobReaperLock      = "stuck-job-reaper"
+	stuckJobReaperBatchSize = 100
+)
+
+type stuckJobReaper struct {
+	filesRepository  portRepositories.FilesRepository
+	messageProducer  adapters.MessageProducer
+	lock             adapters.DistributedLock
+	heartbeatTimeout time.Duration
+	maxAttempts      atomic.Int64
+	interval         time.Duration
+}
+
+func NewStuckJobReaper(
+	filesRepository portRepositories.FilesRepository,
+	messageProducer adapters.MessageProducer,
+	lock adapters.DistributedLock,
+	settings config.ReaperConfig,
+) portServices.StuckJobReaper {
+	reaper := &stuckJobReaper{
+		filesRepository:  filesRepository,
+		messageProducer:  messageProducer,
+		lock:             lock,
+		heartbeatTimeout: settings.HeartbeatTimeout,
+		interval:         settings.Interval,
+	}
+	reaper.maxAttempts.Store(int64(settings.MaxAttempts))
+	return reaper
+}
+
+func (r *stuckJobReaper) ApplySettings(settings config.ReaperConfig) {
+	r.maxAttempts.Store(int64(settings.MaxAttempts))
+}
+
+// Run procura periodicamente processamentos sem sinal de vida até o contexto ser cancelado. Somente
+// a réplica que obtiver o advisory lock executa cada rodada
+func (r *stuckJobReaper) Run(ctx context.Context) {
+	interval := r.interval
+	if interval <= 0 {
+		slog.Info("recuperação de processamentos travados desabilitada")
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		acquired, err := r.lock.TryWithLock(ctx, stuckJobReaperLock, r.reap)
+		if err != nil {
+			slog.Error("erro na recuperação de processamentos travados", "error", err)
+		} else if !acquired {
+			slog.Info("recuperação de processamentos travados em execução em outra réplica")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *stuckJobReaper) reap(ctx context.Context) error {
+	staleBefore := time.Now().Add(-r.heartbeatTimeout)
+	for ctx.Err() == nil {
+		stuckFiles, err := r.filesRepository.ListStuckFiles(ctx, staleBefore, stuckJobReaperBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, stuckFile := range stuckFiles {
+			if stuckFile.Attempts < int(r.maxAttempts.Load()) {
+				err = r.requeue(ctx, stuckFile, staleBefore)
+			} else {
+				err = r.fail(ctx, stuckFile, staleBefore)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if len(stuckFiles) < stuckJobReaperBatchSize {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
+
+// requeue devolve o arquivo para aguardando e publica novamente a mensagem de processamento
+func (r *stuckJobReaper) requeue(ctx context.Context, stuckFile *domain.StuckFile, staleBefore time.Time) error {
+	reaped, err := r.filesRepository.ReapStuckFile(ctx, stuckFile.File.ID, staleBefore, &domain.FileProcessingResult{
+		Status:  domain.FileStatusReceived,
+		Message: fmt.Sprintf("worker perdido, aguardando a tentativa %d de %d", stuckFile.Attempts+1, r.maxAttempts.Load()),
+	})
+	if err != nil || !reaped {
+		return err
+	}
+
+	message, err := json.Marshal(stuckFile.Payload())
+	if err == nil {
+		err = r.messageProducer.PublishMessage(ctx, stuckFile.UserEmail, message)
+	}
+	if err != nil {
+		// sem a mensagem o arquivo ficaria aguardando para sempre
+		slog.Error("não foi possível devolver o processamento travado para a fila", "fileId", stuckFile.File.ID, "error", err)
+		return r.filesRepository.UpdateFileStatus(ctx, &stuckFile.File.ID, domain.NewFileProcessingResultWithError("worker perdido e não foi possível devolver o arquivo para a fila"))
+	}
+	slog.Warn("processamento travado devolvido para a fila", "fileId", stuckFile.File.ID, "attempts", stuckFile.Attempts)
+	return nil
+}
+
+func (r *stuckJobReaper) fail(ctx context.Context, stuckFile *domain.StuckFile, staleBefore time.Time) error {
+	reaped, err := r.filesRepository.ReapStuckFile(ctx, stuckFile.File.ID, staleBefore,
+		domain.NewFileProcessingResultWithError(fmt.Sprintf("worker perdido após %d tentativas", stuckFile.Attempts)))
+	if err != nil || !reaped {
+		return err
+	}
+	slog.Warn("processamento travado finalizado com erro", "fileId", stuckFile.File.ID, "attempts", stuckFile.Attempts)
+	return nil
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	portRepositories "github.com/backstagefood/video-processor-worker/internal/domain/interface/repositories"
+	"github.com/google/uuid"
+)
+
+type stuckFilesRepository struct {
+	portRepositories.FilesRepository
+	stuckFiles []*domain.StuckFile
+	reaped     map[uuid.UUID]*domain.FileProcessingResult
+}
+
+func (r *stuckFilesRepository) ListStuckFiles(context.Context, time.Time, int) ([]*domain.StuckFile, error) {
+	stuckFiles := r.stuckFiles
+	r.stuckFiles = nil
+	return stuckFiles, nil
+}
+
+func (r *stuckFilesRepository) ReapStuckFile(_ context.Context, id uuid.UUID, _ time.Time, processingResult *domain.FileProcessingResult) (bool, error) {
+	r.reaped[id] = processingResult
+	return true, nil
+}
+
+type recordingProducer struct {
+	keys    []string
+	headers []map[string]string
+}
+
+func (p *recordingProducer) PublishMessage(ctx context.Context, key string, value []byte) error {
+	return p.PublishMessageWithHeaders(ctx, key, value, nil)
+}
+
+func (p *recordingProducer) PublishMessageWithHeaders(_ context.Context, key string, _ []byte, headers map[string]string) error {
+	p.keys = append(p.keys, key)
+	p.headers = append(p.headers, headers)
+	return nil
+}
+
+func (p *recordingProducer) Close() error {
+	return nil
+}
+
+func TestStuckJobReaperRequeuesUntilMaxAttempts(t *testing.T) {
+	retry := &domain.StuckFile{File: &domain.File{ID: uuid.New()}, UserEmail: "retry@test.com", Attempts: 1}
+	lost := &domain.StuckFile{File: &domain.File{ID: uuid.New()}, UserEmail: "lost@test.com", Attempts: 3}
+	repository := &stuckFilesRepository{
+		stuckFiles: []*domain.StuckFile{retry, lost},
+		reaped:     make(map[uuid.UUID]*domain.FileProcessingResult),
+	}
+	producer := &recordingProducer{}
+	reaper := &stuckJobReaper{filesRepository: repository, messageProducer: producer, heartbeatTimeout: time.Minute}
+	reaper.maxAttempts.Store(3)
+
+	if err := reaper.reap(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if status := repository.reaped[retry.File.ID].Status; status != domain.FileStatusReceived {
+		t.Errorf("Expected retried file status %d, got %d", domain.FileStatusReceived, status)
+	}
+	if status := repository.reaped[lost.File.ID].Status; status != domain.FileStatusError {
+		t.Errorf("Expected lost file status %d, got %d", domain.FileStatusError, status)
+	}
+	if len(producer.keys) != 1 || producer.keys[0] != retry.UserEmail {
+		t.Errorf("Expected only %s to be republished, got %v", retry.UserEmail, producer.keys)
+	}
+}
@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer cria os spans dos casos de uso. Sem um provider configurado os spans não são exportados
+var tracer globalTracer
+
+// globalTracer busca o provider global a cada span, pois o tracer obtido antes de o provider ser trocado
+// continua ligado ao primeiro provider configurado
+type globalTracer struct{}
+
+func (globalTracer) Start(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer("github.com/backstagefood/video-processor-worker/internal/usecase").Start(ctx, name, options...)
+}
+
+// startMessageSpan inicia o span de consumo da mensagem, filho do trace propagado pelo produtor nos headers
+func startMessageSpan(ctx context.Context, message *adapters.Message) (context.Context, trace.Span) {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(message.Headers))
+	return tracer.Start(ctx, "job.receive", trace.WithSpanKind(trace.SpanKindConsumer), trace.WithAttributes(
+		attribute.String("messaging.destination.name", message.Topic),
+		attribute.String("messaging.message.id", message.ID),
+		attribute.String("messaging.message.key", message.Key),
+	))
+}
+
+// endSpan finaliza o span registrando o erro da etapa, quando houver
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"embed"
+	"fmt"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	portServices "github.com/backstagefood/video-processor-worker/internal/domain/interface/services"
+)
+
+//go:embed schemas/video.frames/*.json
+var videoFramesSchemas embed.FS
+
+// registerVideoFramesPayload registra os schemas das versões do domain.FilePayload e os conversores
+// entre elas. A versão atual corresponde ao domain.FilePayload
+func registerVideoFramesPayload(decoder portServices.PayloadDecoder) error {
+	for _, version := range []int{1, 2} {
+		schema, err := videoFramesSchemas.ReadFile(fmt.Sprintf("schemas/video.frames/v%d.json", version))
+		if err != nil {
+			return err
+		}
+		if err := decoder.RegisterSchema(domain.JobTypeVideoFrames, version, schema); err != nil {
+			return err
+		}
+	}
+	decoder.RegisterConverter(domain.JobTypeVideoFrames, 1, convertVideoFramesV1)
+	return nil
+}
+
+// convertVideoFramesV1 converte o payload v1, anterior aos reprocessamentos, em que todo arquivo era a
+// primeira versão do vídeo
+func convertVideoFramesV1(data map[string]any) (map[string]any, error) {
+	data["version"] = 1
+	return data, nil
+}
@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain"
+	portServices "github.com/backstagefood/video-processor-worker/internal/domain/interface/services"
+	"github.com/backstagefood/video-processor-worker/pkg/config"
+	"github.com/backstagefood/video-processor-worker/utils"
+)
+
+type videoValidator struct {
+	maxFileSize       int64
+	maxDuration       float64
+	allowedContainers []string
+	allowedCodecs     []string
+}
+
+func NewVideoValidator(settings config.VideoConfig) portServices.VideoValidator {
+	return &videoValidator{
+		maxFileSize:       settings.MaxFileSizeMB * 1024 * 1024,
+		maxDuration:       settings.MaxDuration.Seconds(),
+		allowedContainers: lowerAll(settings.AllowedContainers),
+		allowedCodecs:     lowerAll(settings.AllowedCodecs),
+	}
+}
+
+func lowerAll(values []string) []string {
+	lowered := make([]string, len(values))
+	for i, value := range values {
+		lowered[i] = strings.ToLower(value)
+	}
+	return lowered
+}
+
+// ValidateSize compara o tamanho informado no payload com o tamanho real do objeto no bucket
+func (v *videoValidator) ValidateSize(declaredSize, actualSize int64) *domain.VideoRejection {
+	if actualSize == 0 {
+		return domain.NewVideoRejection(domain.RejectionEmptyFile, "o arquivo de video está vazio")
+	}
+	if actualSize > v.maxFileSize {
+		return domain.NewVideoRejection(domain.RejectionFileTooLarge,
+			fmt.Sprintf("o arquivo possui %d bytes, acima do limite de %d bytes", actualSize, v.maxFileSize))
+	}
+	if declaredSize > 0 && declaredSize != actualSize {
+		return domain.NewVideoRejection(domain.RejectionSizeMismatch,
+			fmt.Sprintf("tamanho informado (%d bytes) difere do tamanho do arquivo (%d bytes)", declaredSize, actualSize))
+	}
+	return nil
+}
+
+// ValidateContent identifica o container pelos magic bytes e verifica codecs e duração com o ffprobe
+func (v *videoValidator) ValidateContent(ctx context.Context, videoData []byte) *domain.VideoRejection {
+	if len(videoData) == 0 {
+		return domain.NewVideoRejection(domain.RejectionEmptyFile, "o arquivo de video está vazio")
+	}
+
+	container := utils.SniffVideoContainer(videoData[:min(len(videoData), 512)])
+	if container == "" {
+		return domain.NewVideoRejection(domain.RejectionUnknownContainer, "o conteúdo do arquivo não corresponde a um container de video conhecido")
+	}
+	if !slices.Contains(v.allowedContainers, container) {
+		return domain.NewVideoRejection(domain.RejectionContainerNotAllowed, fmt.Sprintf("container %s não é permitido", container))
+	}
+
+	probe, err := utils.ProbeVideo(ctx, videoData)
+	if err != nil {
+		return domain.NewVideoRejection(domain.RejectionProbeFailed, err.Error())
+	}
+	if len(probe.VideoCodecs) == 0 {
+		return domain.NewVideoRejection(domain.RejectionNoVideoStream, "o arquivo não possui stream de video")
+	}
+	for _, codec := range probe.VideoCodecs {
+		if !slices.Contains(v.allowedCodecs, strings.ToLower(codec)) {
+			return domain.NewVideoRejection(domain.RejectionCodecNotAllowed, fmt.Sprintf("codec %s não é permitido", codec))
+		}
+	}
+	if v.maxDuration > 0 && probe.Duration > v.maxDuration {
+		return domain.NewVideoRejection(domain.RejectionDurationExceeded,
+			fmt.Sprintf("o video possui %.0f segundos, acima do limite de %.0f segundos", probe.Duration, v.maxDuration))
+	}
+	return nil
+}
@@ -0,0 +1,3 @@
+-- status utilizado quando o usuário cancela o processamento pela API
+INSERT INTO file_status (id, status) VALUES (5, 'cancelado')
+ON CONFLICT (id) DO NOTHING;
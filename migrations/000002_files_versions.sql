@@ -0,0 +1,7 @@
+-- versões de reprocessamento ligadas ao arquivo original e opções de extração usadas
+ALTER TABLE files
+    ADD COLUMN IF NOT EXISTS parent_file_id UUID REFERENCES files (id),
+    ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS extraction_options JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_files_parent_file_id ON files (parent_file_id);
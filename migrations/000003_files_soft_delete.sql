@@ -0,0 +1,3 @@
+-- remoção lógica dos arquivos removidos pela API
+ALTER TABLE files
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
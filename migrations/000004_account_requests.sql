@@ -0,0 +1,16 @@
+-- solicitações de exportação e remoção dos dados da conta (LGPD/GDPR)
+CREATE TABLE IF NOT EXISTS account_requests (
+    id                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_email        VARCHAR(255) NOT NULL,
+    kind              VARCHAR(20)  NOT NULL,
+    status            VARCHAR(20)  NOT NULL,
+    objects_processed BIGINT       NOT NULL DEFAULT 0,
+    rows_processed    BIGINT       NOT NULL DEFAULT 0,
+    export_url        TEXT,
+    error_message     TEXT,
+    created_at        TIMESTAMP    NOT NULL DEFAULT now(),
+    updated_at        TIMESTAMP,
+    finished_at       TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_requests_user_email ON account_requests (user_email);
@@ -0,0 +1,16 @@
+-- política de retenção dos arquivos zip e dos videos
+INSERT INTO file_status (id, status) VALUES (6, 'expirado')
+ON CONFLICT (id) DO NOTHING;
+
+ALTER TABLE files
+    ADD COLUMN IF NOT EXISTS zip_expired_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS video_expired_at TIMESTAMP;
+
+-- prazos por usuário, que substituem RETENTION_ZIP_DAYS e RETENTION_VIDEO_DAYS (0 mantém para sempre)
+CREATE TABLE IF NOT EXISTS user_retention_policies (
+    user_id              UUID PRIMARY KEY REFERENCES users (id),
+    zip_retention_days   INTEGER,
+    video_retention_days INTEGER,
+    created_at           TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at           TIMESTAMP
+);
@@ -0,0 +1,14 @@
+-- limites de uso por usuário (0 não aplica o limite)
+INSERT INTO file_status (id, status) VALUES (7, 'cota excedida')
+ON CONFLICT (id) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS user_plans (
+    user_id             UUID PRIMARY KEY REFERENCES users (id),
+    plan_name           VARCHAR(50) NOT NULL,
+    max_concurrent_jobs INTEGER     NOT NULL DEFAULT 0,
+    max_jobs_per_day    INTEGER     NOT NULL DEFAULT 0,
+    max_bytes_per_month BIGINT      NOT NULL DEFAULT 0,
+    max_stored_bytes    BIGINT      NOT NULL DEFAULT 0,
+    created_at          TIMESTAMP   NOT NULL DEFAULT now(),
+    updated_at          TIMESTAMP
+);
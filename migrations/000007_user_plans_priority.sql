@@ -0,0 +1,3 @@
+-- classe de prioridade usada pelo agendador quando o payload não informa a prioridade
+ALTER TABLE user_plans
+    ADD COLUMN IF NOT EXISTS priority_class VARCHAR(10) NOT NULL DEFAULT 'normal';
@@ -0,0 +1,5 @@
+-- identificador da mensagem kafka (tópico/partição/offset) para ignorar mensagens entregues novamente após rebalance
+ALTER TABLE files
+    ADD COLUMN IF NOT EXISTS message_id VARCHAR(300);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_files_message_id ON files (message_id);
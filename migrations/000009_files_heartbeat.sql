@@ -0,0 +1,6 @@
+-- sinal de vida dos processamentos em execução e quantidade de tentativas de cada arquivo
+ALTER TABLE files
+    ADD COLUMN IF NOT EXISTS heartbeat_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_files_processing_heartbeat ON files (heartbeat_at) WHERE status_id = 2;
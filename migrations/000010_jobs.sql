@@ -0,0 +1,13 @@
+-- fila de processamentos na base de dados, alternativa ao kafka (MESSAGE_BROKER=postgres)
+CREATE TABLE IF NOT EXISTS jobs (
+    id          BIGSERIAL PRIMARY KEY,
+    queue       VARCHAR(100) NOT NULL,
+    message_key VARCHAR(300),
+    payload     BYTEA        NOT NULL,
+    attempts    INTEGER      NOT NULL DEFAULT 0,
+    -- mensagens retiradas ficam invisíveis até o fim do prazo e voltam para a fila se não forem confirmadas
+    visible_at  TIMESTAMP    NOT NULL DEFAULT now(),
+    created_at  TIMESTAMP    NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_queue_visible_at ON jobs (queue, visible_at);
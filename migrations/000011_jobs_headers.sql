@@ -0,0 +1,2 @@
+-- headers das mensagens da fila jobs, usados para identificar o tipo do processamento
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS headers JSONB NOT NULL DEFAULT '{}'::jsonb;
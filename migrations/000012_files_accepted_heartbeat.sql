@@ -0,0 +1,4 @@
+-- os arquivos aguardando na fila de uma réplica também enviam sinal de vida e são recuperados quando ela cai
+DROP INDEX IF EXISTS idx_files_processing_heartbeat;
+
+CREATE INDEX IF NOT EXISTS idx_files_heartbeat ON files (heartbeat_at) WHERE status_id IN (1, 2);
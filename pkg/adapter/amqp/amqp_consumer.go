@@ -0,0 +1,190 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	"github.com/backstagefood/video-processor-worker/pkg/adapter/flowcontrol"
+	"github.com/google/uuid"
+	amqp091 "github.com/rabbitmq/amqp091-go"
+)
+
+// keyHeader é o header com a chave da mensagem, equivalente à key das mensagens do kafka
+const keyHeader = "key"
+
+var (
+	errChannelClosed   = errors.New("o canal amqp foi fechado")
+	errConsumerStopped = errors.New("o consumidor amqp foi encerrado")
+)
+
+// amqpChannel contém as operações usadas do canal amqp, permitindo testar os adapters sem o broker
+type amqpChannel interface {
+	QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp091.Table) (amqp091.Queue, error)
+	Qos(prefetchCount, prefetchSize int, global bool) error
+	Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp091.Table) (<-chan amqp091.Delivery, error)
+	Cancel(consumer string, noWait bool) error
+	PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp091.Publishing) error
+	Close() error
+}
+
+// Consumer é o JobSource de uma fila AMQP 0-9-1. O prefetch limita as mensagens entregues e ainda
+// não confirmadas, acompanhando a quantidade de processamentos aceitos pelo worker
+type Consumer struct {
+	connection *amqp091.Connection
+	channel    amqpChannel
+	queue      string
+	// tag identifica o consumo no canal, usada no Stop para interromper as entregas sem fechar o canal
+	tag        string
+	deliveries <-chan amqp091.Delivery
+	stopped    chan struct{}
+	stop       sync.Once
+	stopErr    error
+	*flowcontrol.Gate
+}
+
+func NewConsumer(url string, queue string, prefetch int) (adapters.JobSource, error) {
+	connection, err := amqp091.Dial(url)
+	if err != nil {
+		slog.Error("erro ao conectar no broker amqp", "error", err)
+		return nil, err
+	}
+	channel, err := connection.Channel()
+	if err != nil {
+		_ = connection.Close()
+		slog.Error("erro ao abrir o canal amqp", "error", err)
+		return nil, err
+	}
+	consumer, err := newConsumer(channel, queue, prefetch)
+	if err != nil {
+		_ = connection.Close()
+		return nil, err
+	}
+	consumer.connection = connection
+	return consumer, nil
+}
+
+func newConsumer(channel amqpChannel, queue string, prefetch int) (*Consumer, error) {
+	if _, err := channel.QueueDeclare(queue, true, false, false, false, nil); err != nil {
+		slog.Error("erro ao declarar a fila amqp", "queue", queue, "error", err)
+		return nil, err
+	}
+	if err := channel.Qos(prefetch, 0, false); err != nil {
+		slog.Error("erro ao configurar o prefetch amqp", "prefetch", prefetch, "error", err)
+		return nil, err
+	}
+	tag := "video-processor-worker-" + uuid.NewString()
+	deliveries, err := channel.Consume(queue, tag, false, false, false, false, nil)
+	if err != nil {
+		slog.Error("erro ao consumir a fila amqp", "queue", queue, "error", err)
+		return nil, err
+	}
+	return &Consumer{
+		channel:    channel,
+		queue:      queue,
+		tag:        tag,
+		deliveries: deliveries,
+		stopped:    make(chan struct{}),
+		Gate:       flowcontrol.NewGate(),
+	}, nil
+}
+
+func (c *Consumer) Receive(ctx context.Context) (adapters.Delivery, error) {
+	if err := c.Wait(ctx); err != nil {
+		return nil, err
+	}
+	select {
+	case <-c.stopped:
+		return nil, errConsumerStopped
+	default:
+	}
+	select {
+	case <-c.stopped:
+		return nil, errConsumerStopped
+	case delivery, ok := <-c.deliveries:
+		if !ok {
+			return nil, errChannelClosed
+		}
+		slog.Info("recebendo nova mensagem", "routingKey", delivery.RoutingKey, "value", string(delivery.Body))
+		return &amqpDelivery{delivery: delivery, queue: c.queue}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Name e Check permitem usar o broker como verificação da prontidão
+func (c *Consumer) Name() string {
+	return "amqp"
+}
+
+func (c *Consumer) Check(context.Context) error {
+	if c.connection != nil && c.connection.IsClosed() {
+		return amqp091.ErrClosed
+	}
+	return nil
+}
+
+// Stop cancela o consumo sem fechar o canal, pelo qual as entregas em andamento ainda são confirmadas.
+// As mensagens já enviadas pelo broker e não recebidas voltam para a fila no Close
+func (c *Consumer) Stop() error {
+	c.stop.Do(func() {
+		close(c.stopped)
+		// com o canal já fechado pelo broker não há consumo a cancelar
+		if err := c.channel.Cancel(c.tag, false); !errors.Is(err, amqp091.ErrClosed) {
+			c.stopErr = err
+		}
+	})
+	return c.stopErr
+}
+
+// Close fecha o canal; as mensagens não confirmadas voltam para a fila
+func (c *Consumer) Close() error {
+	err := errors.Join(c.Stop(), c.channel.Close())
+	if c.connection != nil {
+		err = errors.Join(err, c.connection.Close())
+	}
+	return err
+}
+
+type amqpDelivery struct {
+	delivery amqp091.Delivery
+	queue    string
+}
+
+// Message usa o message id definido pelo produtor, que se mantém nas novas entregas da mensagem.
+// Sem ele o ID fica vazio e a mensagem não é verificada contra entregas repetidas
+func (d *amqpDelivery) Message() *adapters.Message {
+	key, _ := d.delivery.Headers[keyHeader].(string)
+	headers := make(map[string]string, len(d.delivery.Headers))
+	for name, value := range d.delivery.Headers {
+		if name == keyHeader {
+			continue
+		}
+		headers[name] = fmt.Sprint(value)
+	}
+	return &adapters.Message{
+		ID:      d.delivery.MessageId,
+		Key:     key,
+		Value:   d.delivery.Body,
+		Topic:   d.queue,
+		Headers: headers,
+	}
+}
+
+func (d *amqpDelivery) Ack() error {
+	return d.delivery.Ack(false)
+}
+
+// Nack devolve a mensagem para a fila para ser entregue novamente
+func (d *amqpDelivery) Nack() error {
+	return d.delivery.Nack(false, true)
+}
+
+// InProgress não faz nada: o RabbitMQ não tem prazo de confirmação por mensagem, apenas o consumer_timeout
+// do broker, que deve ser maior que o processamento mais longo
+func (d *amqpDelivery) InProgress() error {
+	return nil
+}
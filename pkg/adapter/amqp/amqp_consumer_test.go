@@ -0,0 +1,215 @@
+package amqp
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	"github.com/backstagefood/video-processor-worker/pkg/adapter/jobsourcetest"
+	amqp091 "github.com/rabbitmq/amqp091-go"
+)
+
+type queuedMessage struct {
+	publishing  amqp091.Publishing
+	redelivered bool
+}
+
+// fakeBroker simula uma fila do RabbitMQ em memória, respeitando o prefetch e a devolução das
+// mensagens não confirmadas
+type fakeBroker struct {
+	mu       sync.Mutex
+	ready    []queuedMessage
+	unacked  map[uint64]queuedMessage
+	nextTag  uint64
+	prefetch int
+	wake     chan struct{}
+}
+
+func newFakeBroker() *fakeBroker {
+	return &fakeBroker{unacked: make(map[uint64]queuedMessage), wake: make(chan struct{}, 1)}
+}
+
+func (b *fakeBroker) notify() {
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+}
+
+// next retira a próxima mensagem quando o prefetch permite
+func (b *fakeBroker) next() (amqp091.Delivery, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.ready) == 0 || (b.prefetch > 0 && len(b.unacked) >= b.prefetch) {
+		return amqp091.Delivery{}, false
+	}
+	message := b.ready[0]
+	b.ready = b.ready[1:]
+	b.nextTag++
+	b.unacked[b.nextTag] = message
+	return amqp091.Delivery{
+		Acknowledger: b,
+		DeliveryTag:  b.nextTag,
+		Redelivered:  message.redelivered,
+		Headers:      message.publishing.Headers,
+		MessageId:    message.publishing.MessageId,
+		Body:         message.publishing.Body,
+	}, true
+}
+
+func (b *fakeBroker) Ack(tag uint64, _ bool) error {
+	b.mu.Lock()
+	delete(b.unacked, tag)
+	b.mu.Unlock()
+	b.notify()
+	return nil
+}
+
+func (b *fakeBroker) Nack(tag uint64, _ bool, requeue bool) error {
+	b.mu.Lock()
+	message, ok := b.unacked[tag]
+	delete(b.unacked, tag)
+	if ok && requeue {
+		message.redelivered = true
+		b.ready = append([]queuedMessage{message}, b.ready...)
+	}
+	b.mu.Unlock()
+	b.notify()
+	return nil
+}
+
+func (b *fakeBroker) Reject(tag uint64, requeue bool) error {
+	return b.Nack(tag, false, requeue)
+}
+
+type fakeChannel struct {
+	broker     *fakeBroker
+	closed     chan struct{}
+	closeOnce  sync.Once
+	cancelled  chan struct{}
+	cancelOnce sync.Once
+	// delivered guarda as entregas deste canal, devolvidas para a fila quando ele fecha sem confirmá-las
+	delivered []uint64
+}
+
+func (b *fakeBroker) channel() *fakeChannel {
+	return &fakeChannel{broker: b, closed: make(chan struct{}), cancelled: make(chan struct{})}
+}
+
+func (c *fakeChannel) QueueDeclare(name string, _, _, _, _ bool, _ amqp091.Table) (amqp091.Queue, error) {
+	return amqp091.Queue{Name: name}, nil
+}
+
+func (c *fakeChannel) Qos(prefetchCount, _ int, _ bool) error {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+	c.broker.prefetch = prefetchCount
+	return nil
+}
+
+func (c *fakeChannel) Consume(string, string, bool, bool, bool, bool, amqp091.Table) (<-chan amqp091.Delivery, error) {
+	deliveries := make(chan amqp091.Delivery)
+	go func() {
+		defer close(deliveries)
+		for {
+			if delivery, ok := c.broker.next(); ok {
+				delivery.Acknowledger = c
+				c.broker.mu.Lock()
+				c.delivered = append(c.delivered, delivery.DeliveryTag)
+				c.broker.mu.Unlock()
+				select {
+				case deliveries <- delivery:
+					continue
+				case <-c.closed:
+					_ = c.broker.Nack(delivery.DeliveryTag, false, true)
+					return
+				case <-c.cancelled:
+					_ = c.broker.Nack(delivery.DeliveryTag, false, true)
+					return
+				}
+			}
+			select {
+			case <-c.broker.wake:
+			case <-c.closed:
+				return
+			case <-c.cancelled:
+				return
+			}
+		}
+	}()
+	return deliveries, nil
+}
+
+func (c *fakeChannel) PublishWithContext(_ context.Context, _, _ string, _, _ bool, msg amqp091.Publishing) error {
+	c.broker.mu.Lock()
+	c.broker.ready = append(c.broker.ready, queuedMessage{publishing: msg})
+	c.broker.mu.Unlock()
+	c.broker.notify()
+	return nil
+}
+
+// Cancel interrompe as entregas; as mensagens já entregues continuam aguardando a confirmação
+func (c *fakeChannel) Cancel(string, bool) error {
+	c.cancelOnce.Do(func() {
+		close(c.cancelled)
+	})
+	return nil
+}
+
+// Ack, Nack e Reject falham com o canal fechado, como no RabbitMQ, onde a confirmação usa o canal da entrega
+func (c *fakeChannel) Ack(tag uint64, multiple bool) error {
+	if c.isClosed() {
+		return amqp091.ErrClosed
+	}
+	return c.broker.Ack(tag, multiple)
+}
+
+func (c *fakeChannel) Nack(tag uint64, multiple bool, requeue bool) error {
+	if c.isClosed() {
+		return amqp091.ErrClosed
+	}
+	return c.broker.Nack(tag, multiple, requeue)
+}
+
+func (c *fakeChannel) Reject(tag uint64, requeue bool) error {
+	return c.Nack(tag, false, requeue)
+}
+
+func (c *fakeChannel) isClosed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *fakeChannel) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		c.broker.mu.Lock()
+		delivered := c.delivered
+		c.broker.mu.Unlock()
+		for _, tag := range delivered {
+			_ = c.broker.Nack(tag, false, true)
+		}
+	})
+	return nil
+}
+
+func TestConsumerConformance(t *testing.T) {
+	jobsourcetest.Run(t, func(t *testing.T, prefetch int) *jobsourcetest.Harness {
+		broker := newFakeBroker()
+		newSource := func() adapters.JobSource {
+			source, err := newConsumer(broker.channel(), "videos", prefetch)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			t.Cleanup(func() { _ = source.Close() })
+			return source
+		}
+		producer := &Producer{channel: broker.channel(), queue: "videos"}
+		return &jobsourcetest.Harness{Source: newSource(), Producer: producer, NewReplica: newSource}
+	})
+}
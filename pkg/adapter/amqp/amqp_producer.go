@@ -0,0 +1,71 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	"github.com/backstagefood/video-processor-worker/pkg/adapter/tracing"
+	"github.com/google/uuid"
+	amqp091 "github.com/rabbitmq/amqp091-go"
+)
+
+type Producer struct {
+	connection *amqp091.Connection
+	channel    amqpChannel
+	queue      string
+}
+
+func NewProducer(url string, queue string) (adapters.MessageProducer, error) {
+	connection, err := amqp091.Dial(url)
+	if err != nil {
+		slog.Error("erro ao conectar no broker amqp", "error", err)
+		return nil, err
+	}
+	channel, err := connection.Channel()
+	if err != nil {
+		_ = connection.Close()
+		slog.Error("erro ao abrir o canal amqp", "error", err)
+		return nil, err
+	}
+	return &Producer{connection: connection, channel: channel, queue: queue}, nil
+}
+
+// PublishMessage publica a mensagem persistente na fila, pela exchange padrão, com um message id
+// usado pelo consumidor para descartar entregas repetidas
+func (p *Producer) PublishMessage(ctx context.Context, key string, value []byte) error {
+	return p.PublishMessageWithHeaders(ctx, key, value, nil)
+}
+
+func (p *Producer) PublishMessageWithHeaders(ctx context.Context, key string, value []byte, headers map[string]string) error {
+	headers = tracing.InjectHeaders(ctx, headers)
+	messageId := uuid.NewString()
+	table := amqp091.Table{}
+	for name, headerValue := range headers {
+		table[name] = headerValue
+	}
+	// a chave é gravada por último para não ser substituída por um header de mesmo nome
+	table[keyHeader] = key
+	err := p.channel.PublishWithContext(ctx, "", p.queue, false, false, amqp091.Publishing{
+		Headers:      table,
+		ContentType:  "application/json",
+		DeliveryMode: amqp091.Persistent,
+		MessageId:    messageId,
+		Body:         value,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "erro ao publicar a mensagem", "queue", p.queue, "error", err)
+		return err
+	}
+	slog.InfoContext(ctx, "mensagem publicada", "queue", p.queue, "messageId", messageId)
+	return nil
+}
+
+func (p *Producer) Close() error {
+	err := p.channel.Close()
+	if p.connection != nil {
+		err = errors.Join(err, p.connection.Close())
+	}
+	return err
+}
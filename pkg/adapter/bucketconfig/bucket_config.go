@@ -1,14 +1,15 @@
 package bucketconfig
 
 import (
+	"context"
 	"log"
 	"log/slog"
-	"os"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/backstagefood/video-processor-worker/pkg/config"
 )
 
 type ApplicationS3BucketInterface interface {
@@ -21,16 +22,14 @@ type ApplicationS3Bucket struct {
 	bucketName string
 }
 
-func NewBucketConnection() *ApplicationS3Bucket {
+func NewBucketConnection(settings config.BucketConfig) *ApplicationS3Bucket {
 
-	endpoint := os.Getenv("S3_ENDPOINT")
-	bucketName := os.Getenv("S3_BUCKET_NAME")
-	region := os.Getenv("AWS_REGION") // AWS_REGION=us-east-1
-	s3User := os.Getenv("S3_USER")
-	s3Pass := os.Getenv("S3_PASS")
+	endpoint := settings.Endpoint
+	bucketName := settings.Name
+	region := settings.Region // AWS_REGION=us-east-1
 
 	bucketConnection := &aws.Config{
-		Credentials:      credentials.NewStaticCredentials(s3User, s3Pass, ""),
+		Credentials:      credentials.NewStaticCredentials(settings.User, settings.Password, ""),
 		Endpoint:         aws.String(endpoint),
 		Region:           aws.String(region),
 		DisableSSL:       aws.Bool(true),
@@ -54,3 +53,13 @@ func (s *ApplicationS3Bucket) Client() *s3.S3 {
 func (s *ApplicationS3Bucket) BucketName() string {
 	return s.bucketName
 }
+
+// Name e Check permitem usar o bucket como verificação da prontidão
+func (s *ApplicationS3Bucket) Name() string {
+	return "bucket"
+}
+
+func (s *ApplicationS3Bucket) Check(ctx context.Context) error {
+	_, err := s.s3Client.HeadBucketWithContext(ctx, &s3.HeadBucketInput{Bucket: aws.String(s.bucketName)})
+	return err
+}
@@ -1,39 +1,198 @@
 package adapter
 
 import (
+	"context"
+	"fmt"
 	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	"github.com/backstagefood/video-processor-worker/pkg/adapter/amqp"
 	"github.com/backstagefood/video-processor-worker/pkg/adapter/bucketconfig"
+	"github.com/backstagefood/video-processor-worker/pkg/adapter/ffmpeg"
 	"github.com/backstagefood/video-processor-worker/pkg/adapter/kafka"
+	"github.com/backstagefood/video-processor-worker/pkg/adapter/nats"
 	databaseconnection "github.com/backstagefood/video-processor-worker/pkg/adapter/postgres"
+	"github.com/backstagefood/video-processor-worker/pkg/adapter/resilience"
+	"github.com/backstagefood/video-processor-worker/pkg/adapter/schemaregistry"
+	"github.com/backstagefood/video-processor-worker/pkg/config"
 	"log/slog"
-	"os"
 )
 
 type ConnectionManager interface {
 	GetBucketConn() *bucketconfig.ApplicationS3Bucket
 	GetDBConn() *databaseconnection.ApplicationDatabase
-	GetMessageConsumer() adapters.MessageConsumer
+	GetJobSource() adapters.JobSource
+	GetMessageProducer() adapters.MessageProducer
+	GetDeadLetterProducer() adapters.MessageProducer
+	GetPayloadFormats() []adapters.PayloadFormat
+	GetHealthChecks() []adapters.HealthCheck
 }
 
 type connectionManagerImpl struct {
 	bucketConn      *bucketconfig.ApplicationS3Bucket
 	dbConn          *databaseconnection.ApplicationDatabase
-	messageConsumer adapters.MessageConsumer
+	jobSource       adapters.JobSource
+	messageProducer adapters.MessageProducer
+	// deadLetterProducer publica as mensagens que o worker não sabe tratar, separadas dos processamentos
+	deadLetterProducer adapters.MessageProducer
+	payloadFormats     []adapters.PayloadFormat
+	healthChecks       []adapters.HealthCheck
 }
 
-func NewConnectionManager() ConnectionManager {
-	broker := os.Getenv("KAFKA_BROKER")
-	groupId := os.Getenv("KAFKA_GROUP_ID")
-	topic := os.Getenv("KAFKA_TOPIC")
-	consumer, err := kafka.NewConsumer(broker, groupId, topic)
-	if err != nil {
-		slog.Error("não foi possível criar o consumidor do topico kafka", "error", err)
+// NewConnectionManager cria as conexões da aplicação. MESSAGE_BROKER escolhe o transporte das
+// mensagens: kafka (padrão), amqp, nats (JetStream) ou postgres, que usa a tabela jobs da própria base de dados
+//
+// As dependências indisponíveis na partida não impedem a aplicação de subir: a base de dados é aguardada
+// por até STARTUP_RETRY_TIMEOUT_SECONDS e os brokers são conectados sob demanda, com backoff entre as
+// tentativas. Cada dependência tem um circuit breaker cujo estado é reportado na prontidão
+//
+// As conexões seguem o modo de execução: a API apenas publica os processamentos, enquanto o consumo das
+// mensagens, o dead letter e os formatos do schema registry são abertos somente nos modos com workers
+func NewConnectionManager(settings *config.Config, metrics adapters.Metrics) ConnectionManager {
+	// a configuração kafka é validada antes de aguardar a base de dados, para que os erros apareçam na partida
+	var kafkaConfig *kafka.Config
+	if settings.Broker.Type == config.BrokerKafka {
+		var err error
+		if kafkaConfig, err = kafka.NewConfig(settings.Kafka); err != nil {
+			slog.Error("configuração kafka inválida", "error", err)
+			panic(fmt.Sprintf("configuração kafka inválida: %v", err))
+		}
 	}
+
+	backoff := retryBackoff(settings.Resilience)
+	startupCtx, cancel := context.WithTimeout(context.Background(), settings.Resilience.StartupTimeout)
+	defer cancel()
+	dbConn := databaseconnection.NewDbConnection(startupCtx, settings.Database, backoff)
+	bucketConn := bucketconfig.NewBucketConnection(settings.Bucket)
+
+	var jobSource adapters.JobSource
+	var producer, deadLetterProducer adapters.MessageProducer
+	broker := settings.Broker.Type
+	brokerBreaker := newCircuitBreaker(broker, settings.Resilience)
+	switch broker {
+	case config.BrokerPostgres:
+		jobSource, producer, deadLetterProducer = newPostgresConnections(dbConn, settings.JobQueue, settings.Broker.Prefetch, brokerBreaker, backoff)
+	case config.BrokerKafka:
+		jobSource, producer, deadLetterProducer = newKafkaConnections(kafkaConfig, metrics, brokerBreaker, backoff)
+	case config.BrokerAMQP:
+		jobSource, producer, deadLetterProducer = newAmqpConnections(settings.AMQP, settings.Broker.Prefetch, brokerBreaker, backoff)
+	case config.BrokerNATS:
+		jobSource, producer, deadLetterProducer = newNatsConnections(settings.NATS, settings.Broker.Prefetch, brokerBreaker, backoff)
+	default:
+		slog.Error("MESSAGE_BROKER inválido", "broker", broker)
+		panic(fmt.Sprintf("MESSAGE_BROKER inválido: %s", broker))
+	}
+	// o consumidor e os produtores só conectam no primeiro uso, então descartá-los não abre nenhuma conexão
+	var payloadFormats []adapters.PayloadFormat
+	if settings.RunsWorker() {
+		payloadFormats = newPayloadFormats(settings.SchemaRegistry)
+	} else {
+		jobSource, deadLetterProducer = nil, nil
+	}
+
+	healthChecks := []adapters.HealthCheck{
+		newCircuitBreaker(dbConn.Name(), settings.Resilience).Guard(dbConn),
+		newCircuitBreaker(bucketConn.Name(), settings.Resilience).Guard(bucketConn),
+	}
+	if check, ok := jobSource.(adapters.HealthCheck); ok {
+		healthChecks = append(healthChecks, brokerBreaker.Guard(check))
+	} else if check, ok := producer.(adapters.HealthCheck); ok {
+		// sem o consumidor, no modo serve-api, a prontidão acompanha o circuito das publicações do produtor.
+		// A verificação não passa pelo Guard, que fecharia o circuito sem nenhuma publicação bem-sucedida
+		healthChecks = append(healthChecks, check)
+	}
+	// sem o ffmpeg e o ffprobe o worker falharia todos os processamentos que recebesse
+	if settings.RunsWorker() {
+		healthChecks = append(healthChecks, ffmpeg.NewHealthCheck())
+	}
+
 	return &connectionManagerImpl{
-		bucketConn:      bucketconfig.NewBucketConnection(),
-		dbConn:          databaseconnection.NewDbConnection(),
-		messageConsumer: consumer,
+		bucketConn:         bucketConn,
+		dbConn:             dbConn,
+		jobSource:          jobSource,
+		messageProducer:    producer,
+		deadLetterProducer: deadLetterProducer,
+		payloadFormats:     payloadFormats,
+		healthChecks:       healthChecks,
+	}
+}
+
+func retryBackoff(settings config.ResilienceConfig) resilience.Backoff {
+	return resilience.Backoff{
+		Initial: settings.InitialBackoff,
+		Max:     settings.MaxBackoff,
+	}
+}
+
+func newCircuitBreaker(name string, settings config.ResilienceConfig) *resilience.CircuitBreaker {
+	return resilience.NewCircuitBreaker(name, settings.CircuitBreakerThreshold, settings.CircuitBreakerOpen)
+}
+
+// newPayloadFormats habilita as mensagens Avro, Protobuf e JSON Schema no wire format da Confluent
+// quando SCHEMA_REGISTRY_URL é informado. Sem o registry as mensagens são sempre JSON
+func newPayloadFormats(settings config.SchemaRegistryConfig) []adapters.PayloadFormat {
+	if settings.URL == "" {
+		return nil
 	}
+	client := schemaregistry.NewClient(settings.URL, settings.Username, settings.Password, settings.Timeout)
+	return []adapters.PayloadFormat{
+		schemaregistry.NewConfluentFormat(
+			client,
+			schemaregistry.NewAvroDecoder(client),
+			schemaregistry.NewProtobufDecoder(client),
+			schemaregistry.NewJSONDecoder(),
+		),
+	}
+}
+
+func newPostgresConnections(dbConn *databaseconnection.ApplicationDatabase, settings config.JobQueueConfig, prefetch int, breaker *resilience.CircuitBreaker, backoff resilience.Backoff) (adapters.JobSource, adapters.MessageProducer, adapters.MessageProducer) {
+	jobQueue := databaseconnection.NewJobQueue(dbConn, settings.Name, settings.VisibilityTimeout, settings.PollInterval, settings.BatchSize, prefetch)
+	deadLetterQueue := databaseconnection.NewJobQueue(dbConn, settings.DeadLetterName, settings.VisibilityTimeout, settings.PollInterval, settings.BatchSize, prefetch)
+	// a fila não precisa ser recriada, mas a supervisão aguarda a base voltar em vez de encerrar o consumo
+	jobSource := resilience.NewSupervisedSource(func() (adapters.JobSource, error) {
+		return jobQueue, nil
+	}, breaker, backoff)
+	return jobSource, jobQueue, deadLetterQueue
+}
+
+func newKafkaConnections(kafkaConfig *kafka.Config, metrics adapters.Metrics, breaker *resilience.CircuitBreaker, backoff resilience.Backoff) (adapters.JobSource, adapters.MessageProducer, adapters.MessageProducer) {
+	consumer := resilience.NewSupervisedSource(func() (adapters.JobSource, error) {
+		return kafka.NewConsumer(kafkaConfig, metrics)
+	}, breaker, backoff)
+	producer := resilience.NewLazyProducer(func() (adapters.MessageProducer, error) {
+		return kafka.NewProducer(kafkaConfig, kafkaConfig.Topic)
+	}, breaker)
+	deadLetterProducer := resilience.NewLazyProducer(func() (adapters.MessageProducer, error) {
+		return kafka.NewProducer(kafkaConfig, kafkaConfig.DeadLetterTopic)
+	}, breaker)
+	return consumer, producer, deadLetterProducer
+}
+
+// newAmqpConnections limita as mensagens entregues e não confirmadas a prefetch, que acompanha por padrão a
+// quantidade de workers
+func newAmqpConnections(settings config.AMQPConfig, prefetch int, breaker *resilience.CircuitBreaker, backoff resilience.Backoff) (adapters.JobSource, adapters.MessageProducer, adapters.MessageProducer) {
+	consumer := resilience.NewSupervisedSource(func() (adapters.JobSource, error) {
+		return amqp.NewConsumer(settings.URL, settings.Queue, prefetch)
+	}, breaker, backoff)
+	producer := resilience.NewLazyProducer(func() (adapters.MessageProducer, error) {
+		return amqp.NewProducer(settings.URL, settings.Queue)
+	}, breaker)
+	deadLetterProducer := resilience.NewLazyProducer(func() (adapters.MessageProducer, error) {
+		return amqp.NewProducer(settings.URL, settings.DeadLetterQueue)
+	}, breaker)
+	return consumer, producer, deadLetterProducer
+}
+
+func newNatsConnections(settings config.NATSConfig, prefetch int, breaker *resilience.CircuitBreaker, backoff resilience.Backoff) (adapters.JobSource, adapters.MessageProducer, adapters.MessageProducer) {
+	consumer := resilience.NewSupervisedSource(func() (adapters.JobSource, error) {
+		return nats.NewConsumer(settings.URL, settings.Stream, settings.Subject, settings.Consumer, prefetch, settings.AckWait)
+	}, breaker, backoff)
+	producer := resilience.NewLazyProducer(func() (adapters.MessageProducer, error) {
+		return nats.NewProducer(settings.URL, settings.Stream, settings.Subject)
+	}, breaker)
+	deadLetterProducer := resilience.NewLazyProducer(func() (adapters.MessageProducer, error) {
+		// o dead letter usa um stream próprio, pois o stream dos processamentos já existe com o seu subject
+		return nats.NewProducer(settings.URL, settings.DeadLetterStream, settings.DeadLetterSubject)
+	}, breaker)
+	return consumer, producer, deadLetterProducer
 }
 
 func (c *connectionManagerImpl) GetBucketConn() *bucketconfig.ApplicationS3Bucket {
@@ -43,6 +202,26 @@ func (c *connectionManagerImpl) GetDBConn() *databaseconnection.ApplicationDatab
 	return c.dbConn
 }
 
-func (c *connectionManagerImpl) GetMessageConsumer() adapters.MessageConsumer {
-	return c.messageConsumer
+// GetJobSource retorna o consumidor das mensagens, nil no modo serve-api
+func (c *connectionManagerImpl) GetJobSource() adapters.JobSource {
+	return c.jobSource
+}
+
+func (c *connectionManagerImpl) GetMessageProducer() adapters.MessageProducer {
+	return c.messageProducer
+}
+
+// GetDeadLetterProducer retorna o produtor de dead letter, nil no modo serve-api
+func (c *connectionManagerImpl) GetDeadLetterProducer() adapters.MessageProducer {
+	return c.deadLetterProducer
+}
+
+func (c *connectionManagerImpl) GetPayloadFormats() []adapters.PayloadFormat {
+	return c.payloadFormats
+}
+
+// GetHealthChecks retorna as verificações das dependências usadas na prontidão, protegidas pelo circuit
+// breaker de cada dependência
+func (c *connectionManagerImpl) GetHealthChecks() []adapters.HealthCheck {
+	return c.healthChecks
 }
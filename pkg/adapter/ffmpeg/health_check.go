@@ -0,0 +1,34 @@
+package ffmpeg
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+)
+
+// HealthCheck verifica se os executáveis usados nos processamentos estão instalados, usado na prontidão
+// do worker: sem eles todos os processamentos falhariam na extração
+type HealthCheck struct {
+	binaries []string
+}
+
+// NewHealthCheck verifica o ffmpeg e o ffprobe no PATH, ou os executáveis informados
+func NewHealthCheck(binaries ...string) *HealthCheck {
+	if len(binaries) == 0 {
+		binaries = []string{"ffmpeg", "ffprobe"}
+	}
+	return &HealthCheck{binaries: binaries}
+}
+
+func (h *HealthCheck) Name() string {
+	return "ffmpeg"
+}
+
+func (h *HealthCheck) Check(context.Context) error {
+	for _, binary := range h.binaries {
+		if _, err := exec.LookPath(binary); err != nil {
+			return fmt.Errorf("%s não encontrado: %w", binary, err)
+		}
+	}
+	return nil
+}
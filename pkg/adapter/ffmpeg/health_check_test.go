@@ -0,0 +1,23 @@
+package ffmpeg
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+)
+
+func TestHealthCheckFindsInstalledBinaries(t *testing.T) {
+	if err := NewHealthCheck("sh").Check(context.Background()); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestHealthCheckFailsWhenBinaryIsMissing(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	err := NewHealthCheck().Check(context.Background())
+	if !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("Expected exec.ErrNotFound, got %v", err)
+	}
+}
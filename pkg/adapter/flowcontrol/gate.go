@@ -0,0 +1,52 @@
+package flowcontrol
+
+import (
+	"context"
+	"sync"
+)
+
+// Gate implementa o FlowController para os brokers que não pausam o consumo no servidor: enquanto
+// pausado, Wait bloqueia e as mensagens ficam retidas no broker, limitadas pelo prefetch
+type Gate struct {
+	mu      sync.Mutex
+	resumed chan struct{}
+}
+
+func NewGate() *Gate {
+	resumed := make(chan struct{})
+	close(resumed)
+	return &Gate{resumed: resumed}
+}
+
+func (g *Gate) PauseAll() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	select {
+	case <-g.resumed:
+		g.resumed = make(chan struct{})
+	default:
+	}
+}
+
+func (g *Gate) ResumeAll() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	select {
+	case <-g.resumed:
+	default:
+		close(g.resumed)
+	}
+}
+
+// Wait aguarda até o consumo ser retomado ou o contexto ser cancelado
+func (g *Gate) Wait(ctx context.Context) error {
+	g.mu.Lock()
+	resumed := g.resumed
+	g.mu.Unlock()
+	select {
+	case <-resumed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
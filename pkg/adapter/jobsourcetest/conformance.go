@@ -0,0 +1,251 @@
+// Package jobsourcetest contém os testes de conformidade que todo adapters.JobSource deve passar
+package jobsourcetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+)
+
+const (
+	receiveTimeout = 5 * time.Second
+	// silenceTimeout é o tempo aguardado para concluir que nenhuma mensagem será entregue
+	silenceTimeout = 300 * time.Millisecond
+)
+
+// Harness é um broker de teste vazio com o JobSource avaliado e o produtor que publica nele
+type Harness struct {
+	Source   adapters.JobSource
+	Producer adapters.MessageProducer
+	// NewReplica cria outro JobSource no mesmo broker, como o de outra réplica do worker
+	NewReplica func() adapters.JobSource
+}
+
+// NewHarness cria um broker vazio cujo JobSource usa o prefetch informado. Os recursos devem ser
+// liberados com t.Cleanup
+type NewHarness func(t *testing.T, prefetch int) *Harness
+
+// Run executa os testes de conformidade com um broker novo para cada teste
+func Run(t *testing.T, newHarness NewHarness) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, newHarness NewHarness)
+	}{
+		{"DeliversPublishedMessage", testDeliversPublishedMessage},
+		{"DeliversPublishedHeaders", testDeliversPublishedHeaders},
+		{"DeliversInPublishOrder", testDeliversInPublishOrder},
+		{"AckedMessageIsNotRedelivered", testAckedMessageIsNotRedelivered},
+		{"NackedMessageIsRedelivered", testNackedMessageIsRedelivered},
+		{"PrefetchLimitsUnackedDeliveries", testPrefetchLimitsUnackedDeliveries},
+		{"PauseHoldsDeliveries", testPauseHoldsDeliveries},
+		{"ReceiveHonoursContext", testReceiveHonoursContext},
+		{"CloseStopsReceive", testCloseStopsReceive},
+		{"StopKeepsInFlightDeliveriesAckable", testStopKeepsInFlightDeliveriesAckable},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.run(t, newHarness)
+		})
+	}
+}
+
+func publish(t *testing.T, harness *Harness, key string, value string) {
+	t.Helper()
+	if err := harness.Producer.PublishMessage(context.Background(), key, []byte(value)); err != nil {
+		t.Fatalf("Unexpected error publishing: %v", err)
+	}
+}
+
+func receive(t *testing.T, source adapters.JobSource) adapters.Delivery {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), receiveTimeout)
+	defer cancel()
+	delivery, err := source.Receive(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error receiving: %v", err)
+	}
+	return delivery
+}
+
+func expectNoDelivery(t *testing.T, source adapters.JobSource) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), silenceTimeout)
+	defer cancel()
+	delivery, err := source.Receive(ctx)
+	if err == nil {
+		t.Fatalf("Expected no delivery, got %q", delivery.Message().Value)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func ack(t *testing.T, delivery adapters.Delivery) {
+	t.Helper()
+	if err := delivery.Ack(); err != nil {
+		t.Fatalf("Unexpected error acking: %v", err)
+	}
+}
+
+func testDeliversPublishedMessage(t *testing.T, newHarness NewHarness) {
+	harness := newHarness(t, 10)
+	publish(t, harness, "user@test.com", `{"file_path":"video.mp4"}`)
+
+	delivery := receive(t, harness.Source)
+	message := delivery.Message()
+	if message.Key != "user@test.com" {
+		t.Errorf("Expected key user@test.com, got %q", message.Key)
+	}
+	if string(message.Value) != `{"file_path":"video.mp4"}` {
+		t.Errorf("Unexpected value %q", message.Value)
+	}
+	if message.ID == "" {
+		t.Error("Expected a message ID")
+	}
+	ack(t, delivery)
+}
+
+func testDeliversPublishedHeaders(t *testing.T, newHarness NewHarness) {
+	harness := newHarness(t, 10)
+	headers := map[string]string{"type": "video.thumbnail", "schema": "thumbnail/v1"}
+	if err := harness.Producer.PublishMessageWithHeaders(context.Background(), "user@test.com", []byte("{}"), headers); err != nil {
+		t.Fatalf("Unexpected error publishing: %v", err)
+	}
+
+	delivery := receive(t, harness.Source)
+	message := delivery.Message()
+	if message.Key != "user@test.com" {
+		t.Errorf("Expected key user@test.com, got %q", message.Key)
+	}
+	for name, expected := range headers {
+		if value := message.Header(name); value != expected {
+			t.Errorf("Expected header %s=%s, got %q", name, expected, value)
+		}
+	}
+	if message.Topic == "" {
+		t.Error("Expected the topic the message was received from")
+	}
+	ack(t, delivery)
+}
+
+func testDeliversInPublishOrder(t *testing.T, newHarness NewHarness) {
+	harness := newHarness(t, 10)
+	values := []string{"1", "2", "3"}
+	for _, value := range values {
+		publish(t, harness, "key", value)
+	}
+
+	ids := make(map[string]bool)
+	for _, expected := range values {
+		delivery := receive(t, harness.Source)
+		if value := string(delivery.Message().Value); value != expected {
+			t.Errorf("Expected value %s, got %s", expected, value)
+		}
+		ids[delivery.Message().ID] = true
+		ack(t, delivery)
+	}
+	if len(ids) != len(values) {
+		t.Errorf("Expected %d distinct message IDs, got %v", len(values), ids)
+	}
+}
+
+func testAckedMessageIsNotRedelivered(t *testing.T, newHarness NewHarness) {
+	harness := newHarness(t, 10)
+	publish(t, harness, "key", "value")
+
+	ack(t, receive(t, harness.Source))
+	expectNoDelivery(t, harness.Source)
+}
+
+func testNackedMessageIsRedelivered(t *testing.T, newHarness NewHarness) {
+	harness := newHarness(t, 10)
+	publish(t, harness, "key", "value")
+
+	first := receive(t, harness.Source)
+	if err := first.Nack(); err != nil {
+		t.Fatalf("Unexpected error nacking: %v", err)
+	}
+	second := receive(t, harness.Source)
+	if string(second.Message().Value) != "value" {
+		t.Errorf("Expected the nacked message to be redelivered, got %q", second.Message().Value)
+	}
+	if second.Message().ID != first.Message().ID {
+		t.Errorf("Expected the redelivery to keep the message ID %q, got %q", first.Message().ID, second.Message().ID)
+	}
+	ack(t, second)
+}
+
+func testPrefetchLimitsUnackedDeliveries(t *testing.T, newHarness NewHarness) {
+	harness := newHarness(t, 1)
+	publish(t, harness, "key", "1")
+	publish(t, harness, "key", "2")
+
+	first := receive(t, harness.Source)
+	expectNoDelivery(t, harness.Source)
+	ack(t, first)
+
+	second := receive(t, harness.Source)
+	if string(second.Message().Value) != "2" {
+		t.Errorf("Expected the second message after the ack, got %q", second.Message().Value)
+	}
+	ack(t, second)
+}
+
+func testPauseHoldsDeliveries(t *testing.T, newHarness NewHarness) {
+	harness := newHarness(t, 10)
+	harness.Source.PauseAll()
+	publish(t, harness, "key", "value")
+
+	expectNoDelivery(t, harness.Source)
+	harness.Source.ResumeAll()
+	ack(t, receive(t, harness.Source))
+}
+
+func testReceiveHonoursContext(t *testing.T, newHarness NewHarness) {
+	harness := newHarness(t, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := harness.Source.Receive(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func testCloseStopsReceive(t *testing.T, newHarness NewHarness) {
+	harness := newHarness(t, 10)
+	if err := harness.Source.Close(); err != nil {
+		t.Fatalf("Unexpected error closing: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), receiveTimeout)
+	defer cancel()
+	if _, err := harness.Source.Receive(ctx); err == nil || errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected Receive to fail right after Close, got %v", err)
+	}
+}
+
+// testStopKeepsInFlightDeliveriesAckable reproduz o desligamento do worker: o recebimento para, os
+// processamentos em andamento são confirmados e só então a conexão é fechada, sem entregá-los a outra réplica
+func testStopKeepsInFlightDeliveriesAckable(t *testing.T, newHarness NewHarness) {
+	harness := newHarness(t, 10)
+	publish(t, harness, "key", "value")
+	inFlight := receive(t, harness.Source)
+
+	if err := harness.Source.Stop(); err != nil {
+		t.Fatalf("Unexpected error stopping: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), receiveTimeout)
+	defer cancel()
+	if _, err := harness.Source.Receive(ctx); err == nil || errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected Receive to fail right after Stop, got %v", err)
+	}
+	ack(t, inFlight)
+	if err := harness.Source.Close(); err != nil {
+		t.Fatalf("Unexpected error closing: %v", err)
+	}
+
+	expectNoDelivery(t, harness.NewReplica())
+}
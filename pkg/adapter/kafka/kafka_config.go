@@ -1,42 +1,312 @@
 package kafka
 
 import (
-	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
 	"github.com/IBM/sarama"
-	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
-	"log/slog"
+	"github.com/backstagefood/video-processor-worker/pkg/config"
+)
+
+const (
+	SASLMechanismPlain       = "PLAIN"
+	SASLMechanismScramSHA256 = "SCRAM-SHA-256"
+	SASLMechanismScramSHA512 = "SCRAM-SHA-512"
+
+	InitialOffsetNewest = "newest"
+	InitialOffsetOldest = "oldest"
+
+	RebalanceStrategyRange             = "range"
+	RebalanceStrategyRoundRobin        = "roundrobin"
+	RebalanceStrategySticky            = "sticky"
+	RebalanceStrategyCooperativeSticky = "cooperative-sticky"
 )
 
-func newKafkaConfig() *sarama.Config {
+// Config reúne a configuração do cliente kafka, compartilhada pelo consumidor e pelo produtor
+type Config struct {
+	Brokers  []string
+	ClientID string
+	GroupID  string
+	// Topics são os tópicos consumidos. Topic é onde o worker publica os processamentos de vídeo
+	// devolvidos à fila e DeadLetterTopic recebe as mensagens que nenhum handler sabe tratar
+	Topics          []string
+	Topic           string
+	DeadLetterTopic string
+
+	TLS  TLSConfig
+	SASL SASLConfig
+
+	// InitialOffset define onde o consumer group começa quando ainda não há offset confirmado
+	InitialOffset     string
+	RebalanceStrategy string
+
+	SessionTimeout    time.Duration
+	HeartbeatInterval time.Duration
+	RebalanceTimeout  time.Duration
+
+	FetchMinBytes     int32
+	FetchDefaultBytes int32
+	FetchMaxBytes     int32
+	MaxWaitTime       time.Duration
+}
+
+type TLSConfig struct {
+	Enabled            bool
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	InsecureSkipVerify bool
+}
+
+type SASLConfig struct {
+	Mechanism string
+	Username  string
+	Password  string
+}
+
+// NewConfig monta a configuração do cliente a partir da seção kafka da configuração da aplicação, já
+// convertida, e a valida
+func NewConfig(settings config.KafkaConfig) (*Config, error) {
+	kafkaConfig := &Config{
+		Brokers:         settings.Brokers,
+		ClientID:        settings.ClientID,
+		GroupID:         settings.GroupID,
+		Topics:          settings.Topics,
+		Topic:           settings.Topic,
+		DeadLetterTopic: settings.DeadLetterTopic,
+		TLS: TLSConfig{
+			Enabled:            settings.TLS.Enabled,
+			CAFile:             settings.TLS.CAFile,
+			CertFile:           settings.TLS.CertFile,
+			KeyFile:            settings.TLS.KeyFile,
+			InsecureSkipVerify: settings.TLS.InsecureSkipVerify,
+		},
+		SASL: SASLConfig{
+			Mechanism: settings.SASL.Mechanism,
+			Username:  settings.SASL.Username,
+			Password:  settings.SASL.Password,
+		},
+		InitialOffset:     settings.InitialOffset,
+		RebalanceStrategy: settings.RebalanceStrategy,
+		SessionTimeout:    settings.SessionTimeout,
+		HeartbeatInterval: settings.HeartbeatInterval,
+		RebalanceTimeout:  settings.RebalanceTimeout,
+		FetchMinBytes:     settings.FetchMinBytes,
+		FetchDefaultBytes: settings.FetchDefaultBytes,
+		FetchMaxBytes:     settings.FetchMaxBytes,
+		MaxWaitTime:       settings.MaxWaitTime,
+	}
+	if err := kafkaConfig.Validate(); err != nil {
+		return nil, err
+	}
+	return kafkaConfig, nil
+}
+
+// Validate confere a consistência da configuração, incluindo a leitura dos certificados e as
+// regras do próprio sarama, para que erros apareçam na inicialização e não na primeira conexão
+func (c *Config) Validate() error {
+	var errs []error
+	if len(c.Brokers) == 0 {
+		errs = append(errs, errors.New("KAFKA_BROKERS é obrigatório"))
+	}
+	if c.GroupID == "" {
+		errs = append(errs, errors.New("KAFKA_GROUP_ID é obrigatório"))
+	}
+	if len(c.Topics) == 0 {
+		errs = append(errs, errors.New("KAFKA_TOPIC ou KAFKA_TOPICS é obrigatório"))
+	}
+	if slices.Contains(c.Topics, c.DeadLetterTopic) {
+		errs = append(errs, fmt.Errorf("KAFKA_DEAD_LETTER_TOPIC não pode ser um dos tópicos consumidos: %s", c.DeadLetterTopic))
+	}
+
+	if !c.TLS.Enabled && (c.TLS.CAFile != "" || c.TLS.CertFile != "" || c.TLS.KeyFile != "") {
+		errs = append(errs, errors.New("certificados TLS informados sem KAFKA_TLS_ENABLED"))
+	}
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		errs = append(errs, errors.New("KAFKA_TLS_CERT_FILE e KAFKA_TLS_KEY_FILE devem ser informados juntos"))
+	}
+
+	switch c.SASL.Mechanism {
+	case "":
+		if c.SASL.Username != "" || c.SASL.Password != "" {
+			errs = append(errs, errors.New("credenciais SASL informadas sem KAFKA_SASL_MECHANISM"))
+		}
+	case SASLMechanismPlain, SASLMechanismScramSHA256, SASLMechanismScramSHA512:
+		if c.SASL.Username == "" || c.SASL.Password == "" {
+			errs = append(errs, fmt.Errorf("SASL %s exige KAFKA_SASL_USERNAME e KAFKA_SASL_PASSWORD", c.SASL.Mechanism))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("KAFKA_SASL_MECHANISM inválido: %s", c.SASL.Mechanism))
+	}
+
+	if _, err := c.initialOffset(); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := c.rebalanceStrategy(); err != nil {
+		errs = append(errs, err)
+	}
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"KAFKA_SESSION_TIMEOUT_MS", c.SessionTimeout},
+		{"KAFKA_HEARTBEAT_INTERVAL_MS", c.HeartbeatInterval},
+		{"KAFKA_REBALANCE_TIMEOUT_MS", c.RebalanceTimeout},
+		{"KAFKA_MAX_WAIT_MS", c.MaxWaitTime},
+	}
+	for _, duration := range durations {
+		if duration.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s inválido: deve ser maior que zero", duration.name))
+		}
+	}
+	sizes := []struct {
+		name  string
+		value int32
+	}{
+		{"KAFKA_FETCH_MIN_BYTES", c.FetchMinBytes},
+		{"KAFKA_FETCH_DEFAULT_BYTES", c.FetchDefaultBytes},
+		{"KAFKA_FETCH_MAX_BYTES", c.FetchMaxBytes},
+	}
+	for _, size := range sizes {
+		if size.value < 0 {
+			errs = append(errs, fmt.Errorf("%s inválido: não pode ser negativo", size.name))
+		}
+	}
+	if c.HeartbeatInterval >= c.SessionTimeout {
+		errs = append(errs, errors.New("KAFKA_HEARTBEAT_INTERVAL_MS deve ser menor que KAFKA_SESSION_TIMEOUT_MS"))
+	}
+	if c.FetchMaxBytes > 0 && c.FetchDefaultBytes > c.FetchMaxBytes {
+		errs = append(errs, errors.New("KAFKA_FETCH_DEFAULT_BYTES não pode ser maior que KAFKA_FETCH_MAX_BYTES"))
+	}
+	if c.FetchMinBytes > c.FetchDefaultBytes {
+		errs = append(errs, errors.New("KAFKA_FETCH_MIN_BYTES não pode ser maior que KAFKA_FETCH_DEFAULT_BYTES"))
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if _, err := c.saramaConfig(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *Config) initialOffset() (int64, error) {
+	switch c.InitialOffset {
+	case InitialOffsetNewest:
+		return sarama.OffsetNewest, nil
+	case InitialOffsetOldest:
+		return sarama.OffsetOldest, nil
+	default:
+		return 0, fmt.Errorf("KAFKA_INITIAL_OFFSET inválido: %s", c.InitialOffset)
+	}
+}
+
+func (c *Config) rebalanceStrategy() (sarama.BalanceStrategy, error) {
+	switch c.RebalanceStrategy {
+	case RebalanceStrategyRange:
+		return sarama.NewBalanceStrategyRange(), nil
+	case RebalanceStrategyRoundRobin:
+		return sarama.NewBalanceStrategyRoundRobin(), nil
+	case RebalanceStrategySticky:
+		return sarama.NewBalanceStrategySticky(), nil
+	case RebalanceStrategyCooperativeSticky:
+		return sarama.NewBalanceStrategyCooperativeSticky(), nil
+	default:
+		return nil, fmt.Errorf("KAFKA_REBALANCE_STRATEGY inválido: %s", c.RebalanceStrategy)
+	}
+}
+
+// saramaConfig monta a configuração do sarama, carregando os certificados TLS informados
+func (c *Config) saramaConfig() (*sarama.Config, error) {
 	config := sarama.NewConfig()
 	config.Version = sarama.V4_0_0_0
+	config.ClientID = c.ClientID
 	config.Consumer.Return.Errors = true
 	config.Producer.Return.Successes = true
 	config.Producer.Retry.Max = 5
 	config.Producer.RequiredAcks = sarama.WaitForAll
-	return config
-}
 
-type Consumer struct {
-	ConsumerGroup sarama.ConsumerGroup
-	Topic         string
-}
-
-func NewConsumer(broker string, groupID string, topic string) (adapters.MessageConsumer, error) {
-	consumerGroup, err := sarama.NewConsumerGroup([]string{broker}, groupID, newKafkaConfig())
+	offset, err := c.initialOffset()
 	if err != nil {
-		slog.Error("error creating kafka consumer group", slog.String("error", err.Error()))
 		return nil, err
 	}
-	return &Consumer{ConsumerGroup: consumerGroup, Topic: topic}, nil
+	config.Consumer.Offsets.Initial = offset
+	strategy, err := c.rebalanceStrategy()
+	if err != nil {
+		return nil, err
+	}
+	config.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{strategy}
+	config.Consumer.Group.Session.Timeout = c.SessionTimeout
+	config.Consumer.Group.Heartbeat.Interval = c.HeartbeatInterval
+	config.Consumer.Group.Rebalance.Timeout = c.RebalanceTimeout
+	config.Consumer.Fetch.Min = c.FetchMinBytes
+	config.Consumer.Fetch.Default = c.FetchDefaultBytes
+	config.Consumer.Fetch.Max = c.FetchMaxBytes
+	config.Consumer.MaxWaitTime = c.MaxWaitTime
+
+	if c.TLS.Enabled {
+		tlsConfig, err := c.TLS.load()
+		if err != nil {
+			return nil, err
+		}
+		config.Net.TLS.Enable = true
+		config.Net.TLS.Config = tlsConfig
+	}
+
+	if c.SASL.Mechanism != "" {
+		config.Net.SASL.Enable = true
+		config.Net.SASL.User = c.SASL.Username
+		config.Net.SASL.Password = c.SASL.Password
+		switch c.SASL.Mechanism {
+		case SASLMechanismPlain:
+			config.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+		case SASLMechanismScramSHA256:
+			config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA256
+			config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+				return &scramClient{HashGeneratorFcn: sha256Generator}
+			}
+		case SASLMechanismScramSHA512:
+			config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA512
+			config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+				return &scramClient{HashGeneratorFcn: sha512Generator}
+			}
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("configuração kafka inválida: %w", err)
+	}
+	return config, nil
 }
 
-func (kc *Consumer) ConsumeMessages(ctx context.Context, handler sarama.ConsumerGroupHandler) error {
-	for {
-		err := kc.ConsumerGroup.Consume(ctx, []string{kc.Topic}, handler)
+func (t TLSConfig) load() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+	if t.CAFile != "" {
+		caCert, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("não foi possível ler KAFKA_TLS_CA_FILE: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("KAFKA_TLS_CA_FILE não contém certificados PEM: %s", t.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
 		if err != nil {
-			slog.ErrorContext(ctx, "error consuming messages", slog.String("error", err.Error()))
-			return err
+			return nil, fmt.Errorf("não foi possível carregar o certificado do cliente: %w", err)
 		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
 	}
+	return tlsConfig, nil
 }
@@ -0,0 +1,242 @@
+package kafka
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/backstagefood/video-processor-worker/pkg/config"
+)
+
+func setKafkaEnv(t *testing.T, env map[string]string) {
+	t.Helper()
+	t.Setenv("KAFKA_BROKERS", "kafka-1:9092, kafka-2:9092")
+	t.Setenv("KAFKA_GROUP_ID", "video-processor")
+	t.Setenv("KAFKA_TOPIC", "videos")
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+}
+
+// loadConfig carrega a configuração da aplicação a partir do ambiente e monta a configuração kafka
+func loadConfig() (*Config, error) {
+	settings, err := config.Load(nil)
+	if err != nil {
+		return nil, err
+	}
+	return NewConfig(settings.Kafka)
+}
+
+func TestNewConfig_Defaults(t *testing.T) {
+	setKafkaEnv(t, nil)
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(config.Brokers) != 2 || config.Brokers[0] != "kafka-1:9092" || config.Brokers[1] != "kafka-2:9092" {
+		t.Fatalf("Unexpected brokers: %v", config.Brokers)
+	}
+
+	saramaConfig, err := config.saramaConfig()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if saramaConfig.Consumer.Offsets.Initial != sarama.OffsetNewest {
+		t.Errorf("Expected newest initial offset, got %d", saramaConfig.Consumer.Offsets.Initial)
+	}
+	if saramaConfig.Net.TLS.Enable || saramaConfig.Net.SASL.Enable {
+		t.Errorf("Expected TLS and SASL disabled by default")
+	}
+}
+
+func TestNewConfig_FallsBackToSingleBroker(t *testing.T) {
+	setKafkaEnv(t, map[string]string{"KAFKA_BROKERS": "", "KAFKA_BROKER": "localhost:9092"})
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(config.Brokers) != 1 || config.Brokers[0] != "localhost:9092" {
+		t.Fatalf("Unexpected brokers: %v", config.Brokers)
+	}
+}
+
+func TestNewConfig_Topics(t *testing.T) {
+	setKafkaEnv(t, map[string]string{"KAFKA_TOPIC": "", "KAFKA_TOPICS": "videos, thumbnails,audio"})
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(config.Topics) != 3 || config.Topics[1] != "thumbnails" {
+		t.Fatalf("Unexpected topics: %v", config.Topics)
+	}
+	if config.Topic != "videos" {
+		t.Errorf("Expected publish topic videos, got %s", config.Topic)
+	}
+	if config.DeadLetterTopic != "videos.dlq" {
+		t.Errorf("Expected dead letter topic videos.dlq, got %s", config.DeadLetterTopic)
+	}
+}
+
+func TestNewConfig_Tuning(t *testing.T) {
+	setKafkaEnv(t, map[string]string{
+		"KAFKA_INITIAL_OFFSET":        "oldest",
+		"KAFKA_REBALANCE_STRATEGY":    "cooperative-sticky",
+		"KAFKA_SESSION_TIMEOUT_MS":    "45000",
+		"KAFKA_HEARTBEAT_INTERVAL_MS": "5000",
+		"KAFKA_REBALANCE_TIMEOUT_MS":  "120000",
+		"KAFKA_FETCH_MIN_BYTES":       "1024",
+		"KAFKA_FETCH_DEFAULT_BYTES":   "2097152",
+		"KAFKA_FETCH_MAX_BYTES":       "10485760",
+		"KAFKA_MAX_WAIT_MS":           "750",
+		"KAFKA_SASL_MECHANISM":        "scram-sha-512",
+		"KAFKA_SASL_USERNAME":         "worker",
+		"KAFKA_SASL_PASSWORD":         "secret",
+	})
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	saramaConfig, err := config.saramaConfig()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if saramaConfig.Consumer.Offsets.Initial != sarama.OffsetOldest {
+		t.Errorf("Expected oldest initial offset, got %d", saramaConfig.Consumer.Offsets.Initial)
+	}
+	strategies := saramaConfig.Consumer.Group.Rebalance.GroupStrategies
+	if len(strategies) != 1 || strategies[0].Name() != sarama.CooperativeStickyBalanceStrategyName {
+		t.Errorf("Expected cooperative-sticky strategy, got %v", strategies)
+	}
+	if saramaConfig.Consumer.Group.Session.Timeout != 45*time.Second {
+		t.Errorf("Unexpected session timeout: %s", saramaConfig.Consumer.Group.Session.Timeout)
+	}
+	if saramaConfig.Consumer.Group.Heartbeat.Interval != 5*time.Second {
+		t.Errorf("Unexpected heartbeat interval: %s", saramaConfig.Consumer.Group.Heartbeat.Interval)
+	}
+	if saramaConfig.Consumer.Group.Rebalance.Timeout != 2*time.Minute {
+		t.Errorf("Unexpected rebalance timeout: %s", saramaConfig.Consumer.Group.Rebalance.Timeout)
+	}
+	if saramaConfig.Consumer.Fetch.Min != 1024 || saramaConfig.Consumer.Fetch.Default != 2097152 || saramaConfig.Consumer.Fetch.Max != 10485760 {
+		t.Errorf("Unexpected fetch sizes: %+v", saramaConfig.Consumer.Fetch)
+	}
+	if saramaConfig.Consumer.MaxWaitTime != 750*time.Millisecond {
+		t.Errorf("Unexpected max wait time: %s", saramaConfig.Consumer.MaxWaitTime)
+	}
+	if !saramaConfig.Net.SASL.Enable || saramaConfig.Net.SASL.Mechanism != sarama.SASLTypeSCRAMSHA512 {
+		t.Errorf("Expected SASL SCRAM-SHA-512, got %s", saramaConfig.Net.SASL.Mechanism)
+	}
+	if saramaConfig.Net.SASL.SCRAMClientGeneratorFunc == nil {
+		t.Errorf("Expected SCRAM client generator")
+	}
+}
+
+func TestNewConfig_TLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCertificate(t, dir)
+	setKafkaEnv(t, map[string]string{
+		"KAFKA_TLS_ENABLED":   "true",
+		"KAFKA_TLS_CA_FILE":   certFile,
+		"KAFKA_TLS_CERT_FILE": certFile,
+		"KAFKA_TLS_KEY_FILE":  keyFile,
+	})
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	saramaConfig, err := config.saramaConfig()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !saramaConfig.Net.TLS.Enable || saramaConfig.Net.TLS.Config.RootCAs == nil || len(saramaConfig.Net.TLS.Config.Certificates) != 1 {
+		t.Errorf("Expected TLS with CA and client certificate")
+	}
+}
+
+func TestNewConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{"missing brokers", map[string]string{"KAFKA_BROKERS": ""}, "KAFKA_BROKERS é obrigatório"},
+		{"missing topic", map[string]string{"KAFKA_TOPIC": ""}, "KAFKA_TOPIC ou KAFKA_TOPICS é obrigatório"},
+		{"dead letter consumed", map[string]string{"KAFKA_TOPICS": "videos,videos.dlq"}, "KAFKA_DEAD_LETTER_TOPIC não pode ser"},
+		{"invalid bool", map[string]string{"KAFKA_TLS_ENABLED": "talvez"}, "KAFKA_TLS_ENABLED inválido"},
+		{"invalid number", map[string]string{"KAFKA_FETCH_MIN_BYTES": "muito"}, "KAFKA_FETCH_MIN_BYTES inválido"},
+		{"invalid duration", map[string]string{"KAFKA_SESSION_TIMEOUT_MS": "-1"}, "KAFKA_SESSION_TIMEOUT_MS inválido"},
+		{"unknown offset", map[string]string{"KAFKA_INITIAL_OFFSET": "latest"}, "KAFKA_INITIAL_OFFSET inválido"},
+		{"unknown strategy", map[string]string{"KAFKA_REBALANCE_STRATEGY": "random"}, "KAFKA_REBALANCE_STRATEGY inválido"},
+		{"unknown mechanism", map[string]string{"KAFKA_SASL_MECHANISM": "GSSAPI"}, "KAFKA_SASL_MECHANISM inválido"},
+		{"sasl without credentials", map[string]string{"KAFKA_SASL_MECHANISM": "PLAIN"}, "exige KAFKA_SASL_USERNAME"},
+		{"certificates without tls", map[string]string{"KAFKA_TLS_CA_FILE": "/tmp/ca.pem"}, "sem KAFKA_TLS_ENABLED"},
+		{"cert without key", map[string]string{"KAFKA_TLS_ENABLED": "true", "KAFKA_TLS_CERT_FILE": "/tmp/cert.pem"}, "devem ser informados juntos"},
+		{"missing ca file", map[string]string{"KAFKA_TLS_ENABLED": "true", "KAFKA_TLS_CA_FILE": "/nao/existe.pem"}, "KAFKA_TLS_CA_FILE"},
+		{"heartbeat above session", map[string]string{"KAFKA_SESSION_TIMEOUT_MS": "3000", "KAFKA_HEARTBEAT_INTERVAL_MS": "3000"}, "deve ser menor que KAFKA_SESSION_TIMEOUT_MS"},
+		{"fetch default above max", map[string]string{"KAFKA_FETCH_DEFAULT_BYTES": "2048", "KAFKA_FETCH_MAX_BYTES": "1024"}, "KAFKA_FETCH_DEFAULT_BYTES não pode ser maior"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setKafkaEnv(t, tt.env)
+			_, err := loadConfig()
+			if err == nil {
+				t.Fatalf("Expected error containing %q", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// writeCertificate gera um certificado autoassinado usado tanto como CA quanto como certificado do cliente
+func writeCertificate(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "kafka-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return certFile, keyFile
+}
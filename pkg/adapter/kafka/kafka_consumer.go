@@ -0,0 +1,250 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/IBM/sarama"
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// redeliveryDelay é a espera antes de entregar novamente uma mensagem devolvida com Nack
+const redeliveryDelay = time.Second
+
+var errConsumerStopped = errors.New("o consumidor kafka foi encerrado")
+
+// Consumer adapta o consumer group ao JobSource. Cada partição entrega uma mensagem por vez e só
+// avança depois que ela é confirmada, preservando a ordem das mensagens da partição
+type Consumer struct {
+	ConsumerGroup sarama.ConsumerGroup
+	Topics        []string
+	metrics       adapters.Metrics
+	client        sarama.Client
+	// consumeErr guarda a falha que encerrou o consumo, reportada na prontidão
+	consumeErr atomic.Pointer[error]
+
+	deliveries chan *kafkaDelivery
+	errs       chan error
+	start      sync.Once
+	ctx        context.Context
+	cancel     context.CancelFunc
+	stop       sync.Once
+	stopErr    error
+}
+
+func NewConsumer(config *Config, metrics adapters.Metrics) (adapters.JobSource, error) {
+	saramaConfig, err := config.saramaConfig()
+	if err != nil {
+		return nil, err
+	}
+	// o client é mantido pelo consumer para verificar a conexão com o cluster
+	client, err := sarama.NewClient(config.Brokers, saramaConfig)
+	if err != nil {
+		slog.Error("error creating kafka client", slog.String("error", err.Error()))
+		return nil, err
+	}
+	consumerGroup, err := sarama.NewConsumerGroupFromClient(config.GroupID, client)
+	if err != nil {
+		_ = client.Close()
+		slog.Error("error creating kafka consumer group", slog.String("error", err.Error()))
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Consumer{
+		ConsumerGroup: consumerGroup,
+		Topics:        config.Topics,
+		metrics:       metrics,
+		client:        client,
+		deliveries:    make(chan *kafkaDelivery),
+		errs:          make(chan error, 1),
+		ctx:           ctx,
+		cancel:        cancel,
+	}, nil
+}
+
+// Receive inicia o consumer group na primeira chamada e aguarda a próxima mensagem de qualquer partição
+func (kc *Consumer) Receive(ctx context.Context) (adapters.Delivery, error) {
+	kc.start.Do(func() {
+		go kc.consume()
+	})
+	select {
+	case delivery := <-kc.deliveries:
+		return delivery, nil
+	case err := <-kc.errs:
+		return nil, err
+	case <-kc.ctx.Done():
+		return nil, errConsumerStopped
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (kc *Consumer) consume() {
+	handler := &consumerGroupHandler{deliveries: kc.deliveries, metrics: kc.metrics}
+	for {
+		err := kc.ConsumerGroup.Consume(kc.ctx, kc.Topics, handler)
+		if kc.ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			slog.Error("error consuming messages", slog.String("error", err.Error()))
+			kc.consumeErr.Store(&err)
+			kc.errs <- err
+			return
+		}
+	}
+}
+
+// Stop sai do consumer group, repassando as partições às outras réplicas. As mensagens do kafka são
+// confirmadas no recebimento, então não há entregas aguardando a conexão
+func (kc *Consumer) Stop() error {
+	kc.stop.Do(func() {
+		kc.cancel()
+		kc.stopErr = kc.ConsumerGroup.Close()
+	})
+	return kc.stopErr
+}
+
+// Close encerra a sessão; as mensagens entregues e ainda não confirmadas serão entregues novamente
+func (kc *Consumer) Close() error {
+	return errors.Join(kc.Stop(), kc.client.Close())
+}
+
+// Name e Check permitem usar o cluster como verificação da prontidão
+func (kc *Consumer) Name() string {
+	return "kafka"
+}
+
+func (kc *Consumer) Check(context.Context) error {
+	if err := kc.consumeErr.Load(); err != nil {
+		return *err
+	}
+	if kc.client.Closed() {
+		return sarama.ErrClosedClient
+	}
+	_, err := kc.client.Controller()
+	return err
+}
+
+func (kc *Consumer) PauseAll() {
+	kc.ConsumerGroup.PauseAll()
+}
+
+func (kc *Consumer) ResumeAll() {
+	kc.ConsumerGroup.ResumeAll()
+}
+
+type kafkaDelivery struct {
+	message adapters.Message
+	result  chan bool
+}
+
+func (d *kafkaDelivery) Message() *adapters.Message {
+	return &d.message
+}
+
+func (d *kafkaDelivery) Ack() error {
+	d.resolve(true)
+	return nil
+}
+
+func (d *kafkaDelivery) Nack() error {
+	d.resolve(false)
+	return nil
+}
+
+// resolve ignora confirmações repetidas da mesma entrega
+func (d *kafkaDelivery) resolve(acked bool) {
+	select {
+	case d.result <- acked:
+	default:
+	}
+}
+
+// consumerGroupHandler entrega as mensagens das partições recebidas ao JobSource
+type consumerGroupHandler struct {
+	deliveries chan *kafkaDelivery
+	metrics    adapters.Metrics
+}
+
+func (h *consumerGroupHandler) Setup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (h *consumerGroupHandler) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (h *consumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	// ao perder a partição no rebalance o loop termina imediatamente
+	for {
+		select {
+		case message, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
+			// o lag considera as mensagens da partição posteriores à recebida
+			h.metrics.ConsumerLag(message.Topic, message.Partition, max(claim.HighWaterMarkOffset()-message.Offset-1, 0))
+			slog.Info("recebendo nova mensagem",
+				slog.String("topic", message.Topic),
+				slog.String("key", string(message.Key)),
+				slog.String("value", string(message.Value)),
+			)
+			if !h.deliver(session, message) {
+				return nil
+			}
+		case <-session.Context().Done():
+			return nil
+		}
+	}
+}
+
+// deliver entrega a mensagem até ela ser confirmada, marcando o offset em seguida. Mensagens devolvidas
+// com Nack são entregues novamente após uma espera. Retorna false quando a sessão termina antes
+func (h *consumerGroupHandler) deliver(session sarama.ConsumerGroupSession, message *sarama.ConsumerMessage) bool {
+	for {
+		delivery := &kafkaDelivery{
+			message: adapters.Message{
+				ID:      fmt.Sprintf("%s/%d/%d", message.Topic, message.Partition, message.Offset),
+				Key:     string(message.Key),
+				Value:   message.Value,
+				Topic:   message.Topic,
+				Headers: recordHeaders(message.Headers),
+			},
+			result: make(chan bool, 1),
+		}
+		select {
+		case h.deliveries <- delivery:
+		case <-session.Context().Done():
+			return false
+		}
+
+		select {
+		case acked := <-delivery.result:
+			if acked {
+				session.MarkMessage(message, "")
+				return true
+			}
+		case <-session.Context().Done():
+			return false
+		}
+
+		select {
+		case <-time.After(redeliveryDelay):
+		case <-session.Context().Done():
+			return false
+		}
+	}
+}
+
+func recordHeaders(headers []*sarama.RecordHeader) map[string]string {
+	values := make(map[string]string, len(headers))
+	for _, header := range headers {
+		values[string(header.Key)] = string(header.Value)
+	}
+	return values
+}
@@ -0,0 +1,56 @@
+package kafka
+
+import (
+	"context"
+	"github.com/IBM/sarama"
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	"github.com/backstagefood/video-processor-worker/pkg/adapter/tracing"
+	"log/slog"
+)
+
+type Producer struct {
+	SyncProducer sarama.SyncProducer
+	Topic        string
+}
+
+// NewProducer cria o produtor que publica no tópico informado
+func NewProducer(config *Config, topic string) (adapters.MessageProducer, error) {
+	saramaConfig, err := config.saramaConfig()
+	if err != nil {
+		return nil, err
+	}
+	syncProducer, err := sarama.NewSyncProducer(config.Brokers, saramaConfig)
+	if err != nil {
+		slog.Error("error creating kafka producer", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return &Producer{SyncProducer: syncProducer, Topic: topic}, nil
+}
+
+func (kp *Producer) PublishMessage(ctx context.Context, key string, value []byte) error {
+	return kp.PublishMessageWithHeaders(ctx, key, value, nil)
+}
+
+func (kp *Producer) PublishMessageWithHeaders(ctx context.Context, key string, value []byte, headers map[string]string) error {
+	headers = tracing.InjectHeaders(ctx, headers)
+	recordHeaders := make([]sarama.RecordHeader, 0, len(headers))
+	for name, headerValue := range headers {
+		recordHeaders = append(recordHeaders, sarama.RecordHeader{Key: []byte(name), Value: []byte(headerValue)})
+	}
+	partition, offset, err := kp.SyncProducer.SendMessage(&sarama.ProducerMessage{
+		Topic:   kp.Topic,
+		Key:     sarama.StringEncoder(key),
+		Value:   sarama.ByteEncoder(value),
+		Headers: recordHeaders,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "error publishing message", slog.String("error", err.Error()))
+		return err
+	}
+	slog.InfoContext(ctx, "message published", slog.String("topic", kp.Topic), slog.Int("partition", int(partition)), slog.Int64("offset", offset))
+	return nil
+}
+
+func (kp *Producer) Close() error {
+	return kp.SyncProducer.Close()
+}
@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "video_processor"
+
+var (
+	expiredObjects = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "retention_expired_objects_total",
+		Help:      "Objetos removidos do bucket pela política de retenção",
+	}, []string{"kind"})
+	expiredBytes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "retention_expired_bytes_total",
+		Help:      "Bytes removidos do bucket pela política de retenção",
+	}, []string{"kind"})
+	schedulerQueueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "scheduler_queue_depth",
+		Help:      "Processamentos aguardando um worker livre por classe de prioridade",
+	}, []string{"priority_class"})
+	schedulerWaitTime = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "scheduler_wait_seconds",
+		Help:      "Tempo de espera dos processamentos no agendador por classe de prioridade",
+		Buckets:   []float64{0.1, 0.5, 1, 5, 15, 30, 60, 300, 900, 1800, 3600},
+	}, []string{"priority_class"})
+	jobsFinished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "jobs_finished_total",
+		Help:      "Processamentos finalizados por resultado e motivo da falha",
+	}, []string{"outcome", "reason"})
+	stageDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "job_stage_duration_seconds",
+		Help:      "Duração das etapas do processamento: download, extraction, zip e upload",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"stage"})
+	jobBytes = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "job_bytes",
+		Help:      "Tamanho do video recebido (input) e do arquivo ZIP gerado (output)",
+		Buckets:   prometheus.ExponentialBuckets(256*1024, 4, 9),
+	}, []string{"direction"})
+	framesExtracted = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "frames_extracted",
+		Help:      "Frames extraídos por processamento",
+		Buckets:   prometheus.ExponentialBuckets(1, 4, 9),
+	})
+	ffmpegExitCodes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ffmpeg_exits_total",
+		Help:      "Execuções do ffmpeg por código de saída",
+	}, []string{"code"})
+	jobsInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "jobs_in_flight",
+		Help:      "Processamentos aceitos que ainda não terminaram, aguardando ou em execução",
+	})
+	workerSlotsInUse = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "worker_slots_in_use",
+		Help:      "Workers ocupados com um processamento",
+	})
+	workerSlots = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "worker_slots",
+		Help:      "Workers disponíveis para os processamentos",
+	})
+	consumerLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "consumer_lag",
+		Help:      "Mensagens da partição ainda não recebidas pelo consumidor",
+	}, []string{"topic", "partition"})
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latência das requisições HTTP por rota",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+)
+
+type prometheusMetrics struct{}
+
+// NewPrometheusMetrics retorna a implementação das métricas da aplicação exportadas em /metrics
+func NewPrometheusMetrics() adapters.Metrics {
+	return &prometheusMetrics{}
+}
+
+func (m *prometheusMetrics) ObjectsExpired(kind string, count int) {
+	expiredObjects.WithLabelValues(kind).Add(float64(count))
+}
+
+func (m *prometheusMetrics) BytesExpired(kind string, size int64) {
+	expiredBytes.WithLabelValues(kind).Add(float64(size))
+}
+
+func (m *prometheusMetrics) SchedulerQueueDepth(priorityClass string, depth int) {
+	schedulerQueueDepth.WithLabelValues(priorityClass).Set(float64(depth))
+}
+
+func (m *prometheusMetrics) SchedulerWaitTime(priorityClass string, wait time.Duration) {
+	schedulerWaitTime.WithLabelValues(priorityClass).Observe(wait.Seconds())
+}
+
+func (m *prometheusMetrics) JobFinished(outcome, reason string) {
+	jobsFinished.WithLabelValues(outcome, reason).Inc()
+}
+
+func (m *prometheusMetrics) StageDuration(stage string, duration time.Duration) {
+	stageDuration.WithLabelValues(stage).Observe(duration.Seconds())
+}
+
+func (m *prometheusMetrics) JobBytes(direction string, size int64) {
+	jobBytes.WithLabelValues(direction).Observe(float64(size))
+}
+
+func (m *prometheusMetrics) FramesExtracted(count int) {
+	framesExtracted.Observe(float64(count))
+}
+
+func (m *prometheusMetrics) FFmpegExitCode(code int) {
+	ffmpegExitCodes.WithLabelValues(strconv.Itoa(code)).Inc()
+}
+
+func (m *prometheusMetrics) JobsInFlight(count int) {
+	jobsInFlight.Set(float64(count))
+}
+
+func (m *prometheusMetrics) WorkerSlots(inUse, capacity int) {
+	workerSlotsInUse.Set(float64(inUse))
+	workerSlots.Set(float64(capacity))
+}
+
+func (m *prometheusMetrics) ConsumerLag(topic string, partition int32, lag int64) {
+	consumerLag.WithLabelValues(topic, strconv.Itoa(int(partition))).Set(float64(lag))
+}
+
+func (m *prometheusMetrics) HTTPRequest(method, route string, status int, duration time.Duration) {
+	httpRequestDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
@@ -0,0 +1,201 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	"github.com/backstagefood/video-processor-worker/pkg/adapter/flowcontrol"
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	// keyHeader é o header com a chave da mensagem, equivalente à key das mensagens do kafka
+	keyHeader = "key"
+	// redeliveryDelay é a espera antes de entregar novamente uma mensagem devolvida com Nack
+	redeliveryDelay = time.Second
+)
+
+var errConsumerClosed = errors.New("o consumidor nats foi fechado")
+
+// Consumer é o JobSource de um consumer durável do JetStream. O MaxAckPending limita as mensagens
+// entregues e ainda não confirmadas, acompanhando a quantidade de processamentos aceitos pelo worker
+type Consumer struct {
+	connection *natsgo.Conn
+	messages   jetstream.MessagesContext
+	deliveries chan jetstream.Msg
+	errs       chan error
+	done       chan struct{}
+	stop       sync.Once
+	*flowcontrol.Gate
+}
+
+func NewConsumer(url string, stream string, subject string, durable string, prefetch int, ackWait time.Duration) (adapters.JobSource, error) {
+	connection, err := natsgo.Connect(url)
+	if err != nil {
+		slog.Error("erro ao conectar no nats", "error", err)
+		return nil, err
+	}
+	consumer, err := newConsumer(connection, stream, subject, durable, prefetch, ackWait)
+	if err != nil {
+		connection.Close()
+		return nil, err
+	}
+	return consumer, nil
+}
+
+func newConsumer(connection *natsgo.Conn, stream string, subject string, durable string, prefetch int, ackWait time.Duration) (*Consumer, error) {
+	js, err := jetstream.New(connection)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	if err := ensureStream(ctx, js, stream, subject); err != nil {
+		return nil, err
+	}
+	jsConsumer, err := js.CreateOrUpdateConsumer(ctx, stream, jetstream.ConsumerConfig{
+		Durable:       durable,
+		FilterSubject: subject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       ackWait,
+		MaxAckPending: prefetch,
+	})
+	if err != nil {
+		slog.Error("erro ao criar o consumer do jetstream", "stream", stream, "durable", durable, "error", err)
+		return nil, err
+	}
+	messages, err := jsConsumer.Messages(jetstream.PullMaxMessages(prefetch))
+	if err != nil {
+		return nil, err
+	}
+
+	consumer := &Consumer{
+		connection: connection,
+		messages:   messages,
+		deliveries: make(chan jetstream.Msg),
+		errs:       make(chan error, 1),
+		done:       make(chan struct{}),
+		Gate:       flowcontrol.NewGate(),
+	}
+	go consumer.pump()
+	return consumer, nil
+}
+
+// ensureStream cria o stream quando ele ainda não existe, sem alterar a configuração de um stream existente
+func ensureStream(ctx context.Context, js jetstream.JetStream, stream string, subject string) error {
+	_, err := js.Stream(ctx, stream)
+	if errors.Is(err, jetstream.ErrStreamNotFound) {
+		_, err = js.CreateStream(ctx, jetstream.StreamConfig{Name: stream, Subjects: []string{subject}})
+	}
+	if err != nil {
+		slog.Error("erro ao obter o stream do jetstream", "stream", stream, "error", err)
+	}
+	return err
+}
+
+// pump repassa as mensagens do iterador, que não aceita contexto, para o Receive
+func (c *Consumer) pump() {
+	for {
+		msg, err := c.messages.Next()
+		if err != nil {
+			if !errors.Is(err, jetstream.ErrMsgIteratorClosed) {
+				c.errs <- err
+			}
+			return
+		}
+		select {
+		case c.deliveries <- msg:
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (c *Consumer) Receive(ctx context.Context) (adapters.Delivery, error) {
+	if err := c.Wait(ctx); err != nil {
+		return nil, err
+	}
+	select {
+	case msg := <-c.deliveries:
+		slog.Info("recebendo nova mensagem", "subject", msg.Subject(), "value", string(msg.Data()))
+		return &natsDelivery{msg: msg}, nil
+	case err := <-c.errs:
+		return nil, err
+	case <-c.done:
+		return nil, errConsumerClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Name e Check permitem usar o broker como verificação da prontidão
+func (c *Consumer) Name() string {
+	return "nats"
+}
+
+func (c *Consumer) Check(context.Context) error {
+	if status := c.connection.Status(); status != natsgo.CONNECTED {
+		return fmt.Errorf("conexão com o nats %s", status)
+	}
+	return nil
+}
+
+// Stop encerra a assinatura e mantém a conexão, pela qual as entregas em andamento ainda são confirmadas
+func (c *Consumer) Stop() error {
+	c.stop.Do(func() {
+		close(c.done)
+		c.messages.Stop()
+	})
+	return nil
+}
+
+// Close encerra a assinatura e a conexão; as mensagens não confirmadas são entregues novamente ao fim do AckWait
+func (c *Consumer) Close() error {
+	_ = c.Stop()
+	c.connection.Close()
+	return nil
+}
+
+type natsDelivery struct {
+	msg jetstream.Msg
+}
+
+// Message usa a sequência da mensagem no stream como ID, que se mantém nas novas entregas
+func (d *natsDelivery) Message() *adapters.Message {
+	headers := make(map[string]string, len(d.msg.Headers()))
+	for name := range d.msg.Headers() {
+		if name == keyHeader {
+			continue
+		}
+		headers[name] = d.msg.Headers().Get(name)
+	}
+	message := &adapters.Message{
+		Key:     d.msg.Headers().Get(keyHeader),
+		Value:   d.msg.Data(),
+		Topic:   d.msg.Subject(),
+		Headers: headers,
+	}
+	if metadata, err := d.msg.Metadata(); err == nil {
+		message.ID = fmt.Sprintf("%s/%d", metadata.Stream, metadata.Sequence.Stream)
+	}
+	return message
+}
+
+func (d *natsDelivery) Ack() error {
+	return d.msg.Ack()
+}
+
+func (d *natsDelivery) Nack() error {
+	return d.msg.NakWithDelay(redeliveryDelay)
+}
+
+// InProgress reinicia o AckWait da mensagem, que de outra forma seria entregue novamente durante um
+// processamento longo
+func (d *natsDelivery) InProgress() error {
+	return d.msg.InProgress()
+}
@@ -0,0 +1,50 @@
+package nats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	"github.com/backstagefood/video-processor-worker/pkg/adapter/jobsourcetest"
+	"github.com/nats-io/nats-server/v2/server"
+)
+
+func startEmbeddedServer(t *testing.T) string {
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	srv.Start()
+	t.Cleanup(srv.Shutdown)
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatal("Embedded NATS server did not start")
+	}
+	return srv.ClientURL()
+}
+
+func TestConsumerConformance(t *testing.T) {
+	jobsourcetest.Run(t, func(t *testing.T, prefetch int) *jobsourcetest.Harness {
+		url := startEmbeddedServer(t)
+		producer, err := NewProducer(url, "VIDEOS", "videos.jobs")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		t.Cleanup(func() { _ = producer.Close() })
+		newSource := func() adapters.JobSource {
+			source, err := NewConsumer(url, "VIDEOS", "videos.jobs", "worker", prefetch, 30*time.Second)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			t.Cleanup(func() { _ = source.Close() })
+			return source
+		}
+		return &jobsourcetest.Harness{Source: newSource(), Producer: producer, NewReplica: newSource}
+	})
+}
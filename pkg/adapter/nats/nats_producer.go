@@ -0,0 +1,72 @@
+package nats
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	"github.com/backstagefood/video-processor-worker/pkg/adapter/tracing"
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+type Producer struct {
+	connection *natsgo.Conn
+	js         jetstream.JetStream
+	subject    string
+}
+
+func NewProducer(url string, stream string, subject string) (adapters.MessageProducer, error) {
+	connection, err := natsgo.Connect(url)
+	if err != nil {
+		slog.Error("erro ao conectar no nats", "error", err)
+		return nil, err
+	}
+	producer, err := newProducer(connection, stream, subject)
+	if err != nil {
+		connection.Close()
+		return nil, err
+	}
+	return producer, nil
+}
+
+func newProducer(connection *natsgo.Conn, stream string, subject string) (*Producer, error) {
+	js, err := jetstream.New(connection)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureStream(context.Background(), js, stream, subject); err != nil {
+		return nil, err
+	}
+	return &Producer{connection: connection, js: js, subject: subject}, nil
+}
+
+func (p *Producer) PublishMessage(ctx context.Context, key string, value []byte) error {
+	return p.PublishMessageWithHeaders(ctx, key, value, nil)
+}
+
+func (p *Producer) PublishMessageWithHeaders(ctx context.Context, key string, value []byte, headers map[string]string) error {
+	headers = tracing.InjectHeaders(ctx, headers)
+	header := natsgo.Header{}
+	for name, headerValue := range headers {
+		header.Set(name, headerValue)
+	}
+	// a chave é gravada por último para não ser substituída por um header de mesmo nome
+	header.Set(keyHeader, key)
+	ack, err := p.js.PublishMsg(ctx, &natsgo.Msg{
+		Subject: p.subject,
+		Header:  header,
+		Data:    value,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "erro ao publicar a mensagem", "subject", p.subject, "error", err)
+		return err
+	}
+	slog.InfoContext(ctx, "mensagem publicada", "stream", ack.Stream, "sequence", ack.Sequence)
+	return nil
+}
+
+func (p *Producer) Close() error {
+	p.connection.Close()
+	return nil
+}
@@ -0,0 +1,50 @@
+package databaseconnection
+
+import (
+	"context"
+	"hash/fnv"
+	"log/slog"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+)
+
+type advisoryLock struct {
+	db *ApplicationDatabase
+}
+
+// NewAdvisoryLock cria um lock distribuído entre as réplicas baseado em pg_try_advisory_lock
+func NewAdvisoryLock(db *ApplicationDatabase) adapters.DistributedLock {
+	return &advisoryLock{db: db}
+}
+
+// TryWithLock executa fn somente se o lock com o nome informado estiver livre, retornando false caso
+// outra réplica o possua. O lock fica preso à conexão dedicada e é liberado ao final da execução
+func (l *advisoryLock) TryWithLock(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error) {
+	conn, err := l.db.Client().Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	key := lockKey(name)
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&acquired); err != nil {
+		return false, err
+	}
+	if !acquired {
+		return false, nil
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key); err != nil {
+			slog.Error("não foi possível liberar o advisory lock", "name", name, "error", err)
+		}
+	}()
+
+	return true, fn(ctx)
+}
+
+func lockKey(name string) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(name))
+	return int64(hash.Sum64())
+}
@@ -0,0 +1,193 @@
+package databaseconnection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	"github.com/backstagefood/video-processor-worker/pkg/adapter/tracing"
+)
+
+var ErrJobQueueClosed = errors.New("o recebimento de mensagens da fila foi fechado")
+
+// JobQueue é uma fila de mensagens sobre a tabela jobs, usada no lugar do kafka em instalações pequenas
+// e no desenvolvimento local. Implementa tanto o JobSource quanto o produtor de mensagens
+type JobQueue struct {
+	db                *ApplicationDatabase
+	queue             string
+	visibilityTimeout time.Duration
+	pollInterval      time.Duration
+	batchSize         int
+	// prefetch limita as mensagens reservadas e ainda não confirmadas, como no amqp e no nats
+	prefetch int
+	unacked  atomic.Int64
+	paused   atomic.Bool
+	closed   atomic.Bool
+	claimed  []*jobDelivery
+}
+
+func NewJobQueue(db *ApplicationDatabase, queue string, visibilityTimeout, pollInterval time.Duration, batchSize, prefetch int) *JobQueue {
+	return &JobQueue{
+		db:                db,
+		queue:             queue,
+		visibilityTimeout: visibilityTimeout,
+		pollInterval:      pollInterval,
+		batchSize:         batchSize,
+		prefetch:          prefetch,
+	}
+}
+
+// Receive entrega a próxima mensagem visível da fila, buscando um novo lote quando as mensagens já
+// reservadas terminam e o prefetch permite. Deve ser chamado por uma única goroutine
+func (q *JobQueue) Receive(ctx context.Context) (adapters.Delivery, error) {
+	for {
+		if q.closed.Load() {
+			return nil, ErrJobQueueClosed
+		}
+		if len(q.claimed) > 0 {
+			delivery := q.claimed[0]
+			q.claimed = q.claimed[1:]
+			slog.Info("recebendo nova mensagem", "queue", q.queue, "key", delivery.message.Key, "value", string(delivery.message.Value))
+			return delivery, nil
+		}
+
+		if limit := min(q.batchSize, q.prefetch-int(q.unacked.Load())); limit > 0 && !q.paused.Load() {
+			deliveries, err := q.claim(ctx, limit)
+			if err != nil && ctx.Err() == nil {
+				slog.ErrorContext(ctx, "erro ao buscar mensagens da fila", "queue", q.queue, "error", err)
+			}
+			if len(deliveries) > 0 {
+				q.unacked.Add(int64(len(deliveries)))
+				q.claimed = deliveries
+				continue
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(q.pollInterval):
+		}
+	}
+}
+
+// jobDelivery é uma mensagem reservada; sem Ack ou Nack ela volta para a fila ao fim do prazo de visibilidade
+type jobDelivery struct {
+	queue   *JobQueue
+	message adapters.Message
+	jobId   int64
+	settled atomic.Bool
+}
+
+func (d *jobDelivery) Message() *adapters.Message {
+	return &d.message
+}
+
+// Ack remove a mensagem da fila
+func (d *jobDelivery) Ack() error {
+	d.settle()
+	_, err := d.queue.db.Client().Exec(`DELETE FROM jobs WHERE id = $1;`, d.jobId)
+	return err
+}
+
+// Nack torna a mensagem visível novamente após o intervalo de busca
+func (d *jobDelivery) Nack() error {
+	d.settle()
+	_, err := d.queue.db.Client().Exec(`UPDATE jobs SET visible_at = now() + make_interval(secs => $2) WHERE id = $1;`, d.jobId, d.queue.pollInterval.Seconds())
+	return err
+}
+
+// settle libera a vaga do prefetch uma única vez por mensagem. Se a confirmação falhar a mensagem volta
+// para a fila ao fim do prazo de visibilidade, então a vaga é liberada mesmo assim
+func (d *jobDelivery) settle() {
+	if d.settled.CompareAndSwap(false, true) {
+		d.queue.unacked.Add(-1)
+	}
+}
+
+// claim reserva até limit mensagens visíveis, ignorando as que estão bloqueadas por outra réplica
+func (q *JobQueue) claim(ctx context.Context, limit int) ([]*jobDelivery, error) {
+	query := `
+		UPDATE jobs
+		SET visible_at = now() + make_interval(secs => $3), attempts = attempts + 1
+		WHERE id IN (
+			SELECT id
+			FROM jobs
+			WHERE queue = $1
+			  AND visible_at <= now()
+			ORDER BY id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, COALESCE(message_key, ''), payload, headers;
+	`
+	rows, err := q.db.Client().QueryContext(ctx, query, q.queue, limit, q.visibilityTimeout.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	deliveries := make([]*jobDelivery, 0)
+	for rows.Next() {
+		delivery := jobDelivery{queue: q}
+		var headers []byte
+		if err := rows.Scan(&delivery.jobId, &delivery.message.Key, &delivery.message.Value, &headers); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(headers, &delivery.message.Headers); err != nil {
+			return nil, err
+		}
+		delivery.message.ID = fmt.Sprintf("jobs/%s/%d", q.queue, delivery.jobId)
+		delivery.message.Topic = q.queue
+		deliveries = append(deliveries, &delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func (q *JobQueue) PublishMessage(ctx context.Context, key string, value []byte) error {
+	return q.PublishMessageWithHeaders(ctx, key, value, nil)
+}
+
+func (q *JobQueue) PublishMessageWithHeaders(ctx context.Context, key string, value []byte, headers map[string]string) error {
+	headers = tracing.InjectHeaders(ctx, headers)
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	encodedHeaders, err := json.Marshal(headers)
+	if err != nil {
+		return err
+	}
+	var jobId int64
+	err = q.db.Client().QueryRowContext(ctx, `INSERT INTO jobs (queue, message_key, payload, headers) VALUES ($1, $2, $3, $4) RETURNING id;`, q.queue, key, value, encodedHeaders).Scan(&jobId)
+	if err != nil {
+		slog.ErrorContext(ctx, "erro ao publicar a mensagem", "queue", q.queue, "error", err)
+		return err
+	}
+	slog.InfoContext(ctx, "mensagem publicada", "queue", q.queue, "jobId", jobId)
+	return nil
+}
+
+func (q *JobQueue) PauseAll() {
+	q.paused.Store(true)
+}
+
+func (q *JobQueue) ResumeAll() {
+	q.paused.Store(false)
+}
+
+// Stop encerra o recebimento; as mensagens reservadas e não entregues voltam para a fila ao fim do prazo
+// de visibilidade. A publicação e as confirmações continuam disponíveis, pois a conexão com a base de dados
+// é fechada separadamente
+func (q *JobQueue) Stop() error {
+	q.closed.Store(true)
+	return nil
+}
+
+// Close equivale ao Stop, pois a fila não mantém uma conexão própria
+func (q *JobQueue) Close() error {
+	return q.Stop()
+}
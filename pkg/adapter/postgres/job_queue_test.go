@@ -0,0 +1,304 @@
+package databaseconnection
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	"github.com/backstagefood/video-processor-worker/pkg/adapter/jobsourcetest"
+)
+
+const testPollInterval = 20 * time.Millisecond
+
+type jobRow struct {
+	id        int64
+	queue     string
+	key       string
+	payload   []byte
+	headers   []byte
+	attempts  int
+	visibleAt time.Time
+}
+
+// fakeJobsTable simula a tabela jobs em memória, atendendo apenas aos comandos usados pela JobQueue.
+// Como o SKIP LOCKED, uma mensagem reservada fica invisível até o fim do prazo ou até o Nack
+type fakeJobsTable struct {
+	mu     sync.Mutex
+	rows   []*jobRow
+	nextId int64
+}
+
+func (f *fakeJobsTable) Connect(context.Context) (driver.Conn, error) {
+	return &fakeJobsConn{table: f}, nil
+}
+
+func (f *fakeJobsTable) Driver() driver.Driver {
+	return nil
+}
+
+func (f *fakeJobsTable) attempts(id int64) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, row := range f.rows {
+		if row.id == id {
+			return row.attempts
+		}
+	}
+	return 0
+}
+
+func (f *fakeJobsTable) insert(args []driver.NamedValue) *fakeJobsRows {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextId++
+	f.rows = append(f.rows, &jobRow{
+		id:        f.nextId,
+		queue:     args[0].Value.(string),
+		key:       args[1].Value.(string),
+		payload:   args[2].Value.([]byte),
+		headers:   args[3].Value.([]byte),
+		visibleAt: time.Now(),
+	})
+	return &fakeJobsRows{columns: []string{"id"}, values: [][]driver.Value{{f.nextId}}}
+}
+
+func (f *fakeJobsTable) claim(args []driver.NamedValue) *fakeJobsRows {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	queue, limit, visibility := args[0].Value.(string), args[1].Value.(int64), seconds(args[2])
+	rows := &fakeJobsRows{columns: []string{"id", "message_key", "payload", "headers"}}
+	now := time.Now()
+	for _, row := range f.rows {
+		if int64(len(rows.values)) == limit {
+			break
+		}
+		if row.queue != queue || row.visibleAt.After(now) {
+			continue
+		}
+		row.visibleAt = now.Add(visibility)
+		row.attempts++
+		rows.values = append(rows.values, []driver.Value{row.id, row.key, row.payload, row.headers})
+	}
+	return rows
+}
+
+func (f *fakeJobsTable) delete(args []driver.NamedValue) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rows = slices.DeleteFunc(f.rows, func(row *jobRow) bool {
+		return row.id == args[0].Value.(int64)
+	})
+}
+
+func (f *fakeJobsTable) nack(args []driver.NamedValue) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, row := range f.rows {
+		if row.id == args[0].Value.(int64) {
+			row.visibleAt = time.Now().Add(seconds(args[1]))
+		}
+	}
+}
+
+func seconds(arg driver.NamedValue) time.Duration {
+	return time.Duration(arg.Value.(float64) * float64(time.Second))
+}
+
+type fakeJobsConn struct {
+	table *fakeJobsTable
+}
+
+func (c *fakeJobsConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	switch {
+	case strings.Contains(query, "INSERT INTO jobs"):
+		return c.table.insert(args), nil
+	case strings.Contains(query, "FOR UPDATE SKIP LOCKED"):
+		return c.table.claim(args), nil
+	}
+	return nil, fmt.Errorf("consulta não suportada: %s", query)
+}
+
+func (c *fakeJobsConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	switch {
+	case strings.Contains(query, "DELETE FROM jobs"):
+		c.table.delete(args)
+	case strings.Contains(query, "UPDATE jobs SET visible_at"):
+		c.table.nack(args)
+	default:
+		return nil, fmt.Errorf("comando não suportado: %s", query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeJobsConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements não suportados")
+}
+
+func (c *fakeJobsConn) Close() error {
+	return nil
+}
+
+func (c *fakeJobsConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transações não suportadas")
+}
+
+type fakeJobsRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeJobsRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeJobsRows) Close() error {
+	return nil
+}
+
+func (r *fakeJobsRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func newFakeDatabase(t *testing.T) (*ApplicationDatabase, *fakeJobsTable) {
+	table := &fakeJobsTable{}
+	client := sql.OpenDB(table)
+	t.Cleanup(func() { _ = client.Close() })
+	return &ApplicationDatabase{sqlClient: client}, table
+}
+
+func receiveWithin(t *testing.T, queue *JobQueue, timeout time.Duration) (*jobDelivery, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	delivery, err := queue.Receive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return delivery.(*jobDelivery), nil
+}
+
+func TestJobQueueConformance(t *testing.T) {
+	jobsourcetest.Run(t, func(t *testing.T, prefetch int) *jobsourcetest.Harness {
+		db, _ := newFakeDatabase(t)
+		queue := NewJobQueue(db, "videos", 30*time.Second, testPollInterval, 10, prefetch)
+		newReplica := func() adapters.JobSource {
+			return NewJobQueue(db, "videos", 30*time.Second, testPollInterval, 10, prefetch)
+		}
+		return &jobsourcetest.Harness{Source: queue, Producer: queue, NewReplica: newReplica}
+	})
+}
+
+func TestJobQueueRedeliversAfterVisibilityTimeout(t *testing.T) {
+	db, table := newFakeDatabase(t)
+	visibilityTimeout := 300 * time.Millisecond
+	first := NewJobQueue(db, "videos", visibilityTimeout, testPollInterval, 10, 10)
+	// outra réplica consumindo a mesma fila
+	second := NewJobQueue(db, "videos", visibilityTimeout, testPollInterval, 10, 10)
+	if err := first.PublishMessage(context.Background(), "key", []byte("value")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	claimed, err := receiveWithin(t, first, time.Second)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := receiveWithin(t, second, visibilityTimeout/2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the claimed message to stay invisible, got %v", err)
+	}
+
+	redelivered, err := receiveWithin(t, second, 2*time.Second)
+	if err != nil {
+		t.Fatalf("Expected the unacked message after the visibility timeout, got %v", err)
+	}
+	if redelivered.Message().ID != claimed.Message().ID {
+		t.Errorf("Expected message %s, got %s", claimed.Message().ID, redelivered.Message().ID)
+	}
+	if attempts := table.attempts(redelivered.jobId); attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+	if err := redelivered.Ack(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestJobQueueNackedMessageGoesToAnotherReplica(t *testing.T) {
+	db, table := newFakeDatabase(t)
+	first := NewJobQueue(db, "videos", time.Minute, testPollInterval, 10, 10)
+	second := NewJobQueue(db, "videos", time.Minute, testPollInterval, 10, 10)
+	if err := first.PublishMessage(context.Background(), "key", []byte("value")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	claimed, err := receiveWithin(t, first, time.Second)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := claimed.Nack(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// sem o Nack a mensagem só voltaria após um minuto
+	redelivered, err := receiveWithin(t, second, time.Second)
+	if err != nil {
+		t.Fatalf("Expected the nacked message to be redelivered before the visibility timeout, got %v", err)
+	}
+	if attempts := table.attempts(redelivered.jobId); attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestJobQueueDeadLetterQueueIsSeparate(t *testing.T) {
+	db, _ := newFakeDatabase(t)
+	queue := NewJobQueue(db, "videos", time.Minute, testPollInterval, 10, 10)
+	deadLetterQueue := NewJobQueue(db, "videos.dlq", time.Minute, testPollInterval, 10, 10)
+	headers := map[string]string{"dead-letter-reason": "tipo de processamento desconhecido", "dead-letter-source": "videos"}
+	if err := deadLetterQueue.PublishMessageWithHeaders(context.Background(), "key", []byte("value"), headers); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := receiveWithin(t, queue, 200*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the dead-lettered message to stay out of the main queue, got %v", err)
+	}
+	delivery, err := receiveWithin(t, deadLetterQueue, time.Second)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if delivery.Message().Topic != "videos.dlq" {
+		t.Errorf("Expected topic videos.dlq, got %s", delivery.Message().Topic)
+	}
+	for name, expected := range headers {
+		if value := delivery.Message().Header(name); value != expected {
+			t.Errorf("Expected header %s=%s, got %q", name, expected, value)
+		}
+	}
+}
+
+func TestJobQueuePublishesAfterClose(t *testing.T) {
+	db, _ := newFakeDatabase(t)
+	queue := NewJobQueue(db, "videos", time.Minute, testPollInterval, 10, 10)
+	if err := queue.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// a mesma fila é o produtor de mensagens, que continua em uso após o fim do consumo
+	if err := queue.PublishMessage(context.Background(), "key", []byte("value")); err != nil {
+		t.Errorf("Expected publishing to keep working after Close, got %v", err)
+	}
+	if _, err := receiveWithin(t, queue, time.Second); !errors.Is(err, ErrJobQueueClosed) {
+		t.Errorf("Expected ErrJobQueueClosed, got %v", err)
+	}
+}
@@ -1,13 +1,19 @@
 package databaseconnection
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"log/slog"
 	"net/url"
-	"os"
 
+	"github.com/XSAM/otelsql"
+	"github.com/backstagefood/video-processor-worker/pkg/adapter/resilience"
+	"github.com/backstagefood/video-processor-worker/pkg/config"
 	_ "github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type ApplicationDatabaseInterface interface {
@@ -19,26 +25,39 @@ type ApplicationDatabase struct {
 	sqlClient *sql.DB
 }
 
-func NewDbConnection() *ApplicationDatabase {
+// NewDbConnection abre o pool de conexões e aguarda a base responder, tentando novamente com backoff até
+// o fim do contexto. Se a base não responder no prazo a aplicação segue sem ela: o pool reconecta no
+// próximo uso e a prontidão reporta a base indisponível enquanto isso
+func NewDbConnection(ctx context.Context, settings config.DatabaseConfig, backoff resilience.Backoff) *ApplicationDatabase {
 	connStr := fmt.Sprintf(
 		"%s://%s:%s@%s/%s%s",
-		os.Getenv("DB_DRIVER"),
-		os.Getenv("DB_USER"),
-		url.QueryEscape(os.Getenv("DB_PASS")),
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_NAME"),
-		os.Getenv("DB_OPTIONS"),
+		settings.Driver,
+		settings.User,
+		url.QueryEscape(settings.Password),
+		settings.Host,
+		settings.Name,
+		settings.Options,
 	)
 
-	client, err := sql.Open(os.Getenv("DB_DRIVER"), connStr)
+	// cada consulta gera um span filho do span do processamento ou da requisição, quando existir
+	client, err := otelsql.Open(settings.Driver, connStr,
+		otelsql.WithAttributes(attribute.String("db.system", "postgresql")),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitRows:             true,
+			SpanFilter: func(ctx context.Context, _ otelsql.Method, _ string, _ []driver.NamedValue) bool {
+				return trace.SpanContextFromContext(ctx).IsValid()
+			},
+		}),
+	)
 	if err != nil {
 		slog.Error("erro ao conectar com banco de dados", err.Error(), err)
 		panic(err)
 	}
 
-	if err = client.Ping(); err != nil {
-		slog.Error("erro testar banco de dados", err.Error(), err)
-		panic(err)
+	if err = resilience.Retry(ctx, "database", backoff, client.PingContext); err != nil {
+		slog.Error("banco de dados indisponível, seguindo sem conexão", "error", err)
+		return &ApplicationDatabase{sqlClient: client}
 	}
 
 	slog.Info("banco de dados conectado com sucesso!")
@@ -51,5 +70,18 @@ func (s *ApplicationDatabase) Client() *sql.DB {
 }
 
 func (s *ApplicationDatabase) DataBaseHealth() error {
-	return s.sqlClient.Ping()
+	return s.Check(context.Background())
+}
+
+// Name e Check permitem usar a base de dados como verificação da prontidão
+func (s *ApplicationDatabase) Name() string {
+	return "database"
+}
+
+func (s *ApplicationDatabase) Check(ctx context.Context) error {
+	return s.sqlClient.PingContext(ctx)
+}
+
+func (s *ApplicationDatabase) Close() error {
+	return s.sqlClient.Close()
 }
@@ -0,0 +1,51 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff define a espera entre as tentativas, que dobra a cada falha até Max. A espera é sorteada
+// entre a metade e o valor calculado, evitando que as réplicas tentem reconectar ao mesmo tempo
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+}
+
+// Delay retorna a espera antes da tentativa seguinte à tentativa attempt, começando em zero
+func (b Backoff) Delay(attempt int) time.Duration {
+	delay := b.Initial
+	for i := 0; i < attempt && delay < b.Max; i++ {
+		delay *= 2
+	}
+	delay = min(delay, b.Max)
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// Retry executa fn até ela ter sucesso ou o contexto terminar, aguardando o backoff entre as tentativas.
+// Retorna o último erro de fn junto com o erro do contexto
+func Retry(ctx context.Context, name string, backoff Backoff, fn func(ctx context.Context) error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			if attempt > 0 {
+				slog.InfoContext(ctx, "dependência disponível", "dependency", name, "attempts", attempt+1)
+			}
+			return nil
+		}
+
+		delay := backoff.Delay(attempt)
+		slog.WarnContext(ctx, "dependência indisponível, tentando novamente", "dependency", name, "attempt", attempt+1, "retryIn", delay, "error", err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package resilience
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+)
+
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half-open"
+)
+
+// CircuitBreaker acompanha as falhas de uma dependência. Depois de failureThreshold falhas seguidas o
+// circuito abre e as verificações deixam de chamar a dependência até openTimeout; em seguida uma nova
+// chamada é permitida (half-open), fechando o circuito no sucesso ou abrindo novamente na falha
+type CircuitBreaker struct {
+	name             string
+	failureThreshold int
+	openTimeout      time.Duration
+
+	mu        sync.Mutex
+	state     string
+	failures  int
+	openedAt  time.Time
+	lastError error
+}
+
+func NewCircuitBreaker(name string, failureThreshold int, openTimeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		name:             name,
+		failureThreshold: max(failureThreshold, 1),
+		openTimeout:      openTimeout,
+		state:            StateClosed,
+	}
+}
+
+func (b *CircuitBreaker) Name() string {
+	return b.name
+}
+
+func (b *CircuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Allow indica se a dependência pode ser chamada, passando o circuito aberto para half-open ao fim do openTimeout
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateOpen && time.Since(b.openedAt) >= b.openTimeout {
+		b.transition(StateHalfOpen)
+	}
+	return b.state != StateOpen
+}
+
+// Record registra o resultado de uma chamada à dependência
+func (b *CircuitBreaker) Record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err == nil {
+		b.failures = 0
+		b.lastError = nil
+		if b.state != StateClosed {
+			b.transition(StateClosed)
+		}
+		return
+	}
+
+	b.failures++
+	b.lastError = err
+	if b.state == StateHalfOpen || (b.state == StateClosed && b.failures >= b.failureThreshold) {
+		b.openedAt = time.Now()
+		b.transition(StateOpen)
+	}
+}
+
+// Err retorna o erro do circuito aberto, ou nil quando a dependência pode ser chamada
+func (b *CircuitBreaker) Err() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state != StateOpen {
+		return nil
+	}
+	return fmt.Errorf("circuito aberto após %d falhas: %w", b.failures, b.lastError)
+}
+
+func (b *CircuitBreaker) transition(state string) {
+	slog.Warn("estado do circuito alterado", "dependency", b.name, "from", b.state, "to", state, "failures", b.failures, "error", b.lastError)
+	b.state = state
+}
+
+// Guard protege a verificação de prontidão da dependência: com o circuito aberto a dependência não
+// é chamada e a prontidão reporta o circuito aberto
+func (b *CircuitBreaker) Guard(check adapters.HealthCheck) adapters.HealthCheck {
+	return &guardedCheck{check: check, breaker: b}
+}
+
+type guardedCheck struct {
+	check   adapters.HealthCheck
+	breaker *CircuitBreaker
+}
+
+func (g *guardedCheck) Name() string {
+	return g.check.Name()
+}
+
+func (g *guardedCheck) Check(ctx context.Context) error {
+	if !g.breaker.Allow() {
+		return g.breaker.Err()
+	}
+	err := g.check.Check(ctx)
+	g.breaker.Record(err)
+	return err
+}
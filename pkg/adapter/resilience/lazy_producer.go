@@ -0,0 +1,104 @@
+package resilience
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+)
+
+// LazyProducer cria o produtor na primeira publicação e tenta novamente nas publicações seguintes
+// enquanto a criação falhar, em vez de deixar a aplicação sem produtor quando o broker não responde na partida.
+// Uma publicação com falha descarta o produtor, recriado na próxima publicação, e é registrada no circuit
+// breaker, acompanhado pela prontidão
+type LazyProducer struct {
+	factory func() (adapters.MessageProducer, error)
+	breaker *CircuitBreaker
+
+	mu       sync.Mutex
+	producer adapters.MessageProducer
+}
+
+func NewLazyProducer(factory func() (adapters.MessageProducer, error), breaker *CircuitBreaker) *LazyProducer {
+	return &LazyProducer{factory: factory, breaker: breaker}
+}
+
+func (p *LazyProducer) PublishMessage(ctx context.Context, key string, value []byte) error {
+	return p.PublishMessageWithHeaders(ctx, key, value, nil)
+}
+
+func (p *LazyProducer) PublishMessageWithHeaders(ctx context.Context, key string, value []byte, headers map[string]string) error {
+	producer, err := p.get()
+	if err != nil {
+		return err
+	}
+	err = producer.PublishMessageWithHeaders(ctx, key, value, headers)
+	// a publicação interrompida pelo chamador não indica uma falha do broker
+	if err != nil && ctx.Err() != nil {
+		return err
+	}
+	p.breaker.Record(err)
+	if err != nil {
+		p.discard(producer)
+	}
+	return err
+}
+
+// discard fecha o produtor que falhou, assim a próxima publicação cria uma nova conexão. Apenas quem o
+// remove o fecha, pois publicações simultâneas podem falhar com o mesmo produtor
+func (p *LazyProducer) discard(producer adapters.MessageProducer) {
+	p.mu.Lock()
+	if p.producer != producer {
+		p.mu.Unlock()
+		return
+	}
+	p.producer = nil
+	p.mu.Unlock()
+	if err := producer.Close(); err != nil {
+		slog.Warn("erro ao fechar o produtor com falha", "dependency", p.breaker.Name(), "error", err)
+	}
+}
+
+// get serializa a criação para que publicações simultâneas não criem mais de um produtor
+func (p *LazyProducer) get() (adapters.MessageProducer, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.producer != nil {
+		return p.producer, nil
+	}
+	// com o circuito aberto a publicação falha sem aguardar o timeout da conexão
+	if !p.breaker.Allow() {
+		return nil, p.breaker.Err()
+	}
+	producer, err := p.factory()
+	p.breaker.Record(err)
+	if err != nil {
+		return nil, fmt.Errorf("produtor %s indisponível: %w", p.breaker.Name(), err)
+	}
+	p.producer = producer
+	return producer, nil
+}
+
+func (p *LazyProducer) Name() string {
+	return p.breaker.Name()
+}
+
+// Check reporta o circuito das publicações sem publicar nem conectar. Ao fim do openTimeout o circuito passa
+// para half-open e a prontidão volta, permitindo que a próxima publicação confirme se o broker voltou
+func (p *LazyProducer) Check(context.Context) error {
+	if !p.breaker.Allow() {
+		return p.breaker.Err()
+	}
+	return nil
+}
+
+func (p *LazyProducer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.producer == nil {
+		return nil
+	}
+	return p.producer.Close()
+}
@@ -0,0 +1,321 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+)
+
+var errUnavailable = errors.New("dependência indisponível")
+
+func TestBackoffDelay(t *testing.T) {
+	backoff := Backoff{Initial: 100 * time.Millisecond, Max: time.Second}
+	for attempt, expected := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		delay := backoff.Delay(attempt)
+		if delay < expected/2 || delay > expected {
+			t.Errorf("Expected delay of attempt %d between %s and %s, got %s", attempt, expected/2, expected, delay)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	backoff := Backoff{Initial: time.Millisecond, Max: time.Millisecond}
+	calls := 0
+	err := Retry(context.Background(), "database", backoff, func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errUnavailable
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = Retry(ctx, "database", backoff, func(context.Context) error { return errUnavailable })
+	if !errors.Is(err, errUnavailable) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the last error and the context error, got %v", err)
+	}
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	breaker := NewCircuitBreaker("database", 2, 20*time.Millisecond)
+
+	breaker.Record(errUnavailable)
+	if breaker.State() != StateClosed || !breaker.Allow() {
+		t.Fatalf("Expected the circuit to stay closed below the threshold, got %s", breaker.State())
+	}
+	breaker.Record(errUnavailable)
+	if breaker.State() != StateOpen || breaker.Allow() {
+		t.Fatalf("Expected the circuit to open, got %s", breaker.State())
+	}
+	if err := breaker.Err(); !errors.Is(err, errUnavailable) {
+		t.Errorf("Expected the open circuit error to wrap the last failure, got %v", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if !breaker.Allow() || breaker.State() != StateHalfOpen {
+		t.Fatalf("Expected the circuit to be half-open after the timeout, got %s", breaker.State())
+	}
+	breaker.Record(errUnavailable)
+	if breaker.State() != StateOpen {
+		t.Fatalf("Expected a half-open failure to reopen the circuit, got %s", breaker.State())
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	breaker.Allow()
+	breaker.Record(nil)
+	if breaker.State() != StateClosed || breaker.Err() != nil {
+		t.Errorf("Expected a half-open success to close the circuit, got %s", breaker.State())
+	}
+}
+
+type fakeHealthCheck struct {
+	err   error
+	calls int
+}
+
+func (c *fakeHealthCheck) Name() string { return "bucket" }
+
+func (c *fakeHealthCheck) Check(context.Context) error {
+	c.calls++
+	return c.err
+}
+
+func TestGuardedCheckSkipsDependencyWhileOpen(t *testing.T) {
+	check := &fakeHealthCheck{err: errUnavailable}
+	guarded := NewCircuitBreaker("bucket", 1, time.Minute).Guard(check)
+
+	if err := guarded.Check(context.Background()); !errors.Is(err, errUnavailable) {
+		t.Fatalf("Expected the dependency error, got %v", err)
+	}
+	if err := guarded.Check(context.Background()); err == nil {
+		t.Fatal("Expected the open circuit to be reported")
+	}
+	if check.calls != 1 {
+		t.Errorf("Expected the dependency to be skipped while the circuit is open, got %d calls", check.calls)
+	}
+}
+
+// fakeSource falha no Receive quando err é informado e registra as pausas, a parada e o fechamento
+type fakeSource struct {
+	err     error
+	mu      sync.Mutex
+	paused  bool
+	stopped bool
+	closed  bool
+}
+
+type fakeDelivery struct {
+	adapters.Delivery
+}
+
+func (s *fakeSource) Receive(context.Context) (adapters.Delivery, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return fakeDelivery{}, nil
+}
+
+func (s *fakeSource) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopped = true
+	return nil
+}
+
+func (s *fakeSource) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *fakeSource) PauseAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paused = true
+}
+
+func (s *fakeSource) ResumeAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paused = false
+}
+
+func TestSupervisedSourceRestartsFailedSource(t *testing.T) {
+	failing := &fakeSource{err: errUnavailable}
+	healthy := &fakeSource{}
+	attempts := 0
+	factory := func() (adapters.JobSource, error) {
+		attempts++
+		switch attempts {
+		case 1:
+			return nil, errUnavailable
+		case 2:
+			return failing, nil
+		default:
+			return healthy, nil
+		}
+	}
+	breaker := NewCircuitBreaker("kafka", 5, time.Minute)
+	source := NewSupervisedSource(factory, breaker, Backoff{Initial: time.Millisecond, Max: time.Millisecond})
+	source.PauseAll()
+
+	if _, err := source.Receive(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts to create the source, got %d", attempts)
+	}
+	if !failing.closed {
+		t.Error("Expected the failed source to be closed")
+	}
+	if !healthy.paused {
+		t.Error("Expected the pause to be applied to the new source")
+	}
+	if breaker.State() != StateClosed {
+		t.Errorf("Expected the circuit to close after a delivery, got %s", breaker.State())
+	}
+	if err := source.Check(context.Background()); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	// o Stop mantém a conexão do consumidor para as confirmações, fechada apenas no Close
+	if err := source.Stop(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !healthy.stopped || healthy.closed {
+		t.Errorf("Expected Stop to stop the source without closing it, got stopped=%v closed=%v", healthy.stopped, healthy.closed)
+	}
+	if _, err := source.Receive(context.Background()); !errors.Is(err, errSourceClosed) {
+		t.Errorf("Expected the closed source error, got %v", err)
+	}
+	if err := source.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !healthy.closed {
+		t.Error("Expected Close to close the source")
+	}
+}
+
+type fakeProducer struct {
+	adapters.MessageProducer
+	published int
+	// failAfter faz as publicações falharem depois da quantidade informada, quando maior que zero
+	failAfter int
+	closed    bool
+}
+
+func (p *fakeProducer) PublishMessageWithHeaders(context.Context, string, []byte, map[string]string) error {
+	if p.failAfter > 0 && p.published >= p.failAfter {
+		return errUnavailable
+	}
+	p.published++
+	return nil
+}
+
+func (p *fakeProducer) Close() error {
+	p.closed = true
+	return nil
+}
+
+func TestLazyProducerRetriesCreation(t *testing.T) {
+	producer := &fakeProducer{}
+	attempts := 0
+	lazy := NewLazyProducer(func() (adapters.MessageProducer, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, errUnavailable
+		}
+		return producer, nil
+	}, NewCircuitBreaker("kafka", 3, time.Minute))
+
+	if err := lazy.PublishMessage(context.Background(), "key", []byte("value")); !errors.Is(err, errUnavailable) {
+		t.Fatalf("Expected the creation error, got %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := lazy.PublishMessage(context.Background(), "key", []byte("value")); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if attempts != 2 || producer.published != 2 {
+		t.Errorf("Expected the producer to be created once after the failure, got attempts=%d published=%d", attempts, producer.published)
+	}
+}
+
+func TestLazyProducerRecreatesFailedProducer(t *testing.T) {
+	producers := []*fakeProducer{{failAfter: 1}, {}}
+	attempts := 0
+	breaker := NewCircuitBreaker("kafka", 1, 20*time.Millisecond)
+	lazy := NewLazyProducer(func() (adapters.MessageProducer, error) {
+		producer := producers[attempts]
+		attempts++
+		return producer, nil
+	}, breaker)
+
+	if err := lazy.PublishMessage(context.Background(), "key", []byte("value")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := lazy.PublishMessage(context.Background(), "key", []byte("value")); !errors.Is(err, errUnavailable) {
+		t.Fatalf("Expected the publish error, got %v", err)
+	}
+	if !producers[0].closed {
+		t.Error("Expected the failed producer to be closed")
+	}
+	if breaker.State() != StateOpen {
+		t.Fatalf("Expected the publish failure to open the circuit, got %s", breaker.State())
+	}
+	if err := lazy.PublishMessage(context.Background(), "key", []byte("value")); err == nil || attempts != 1 {
+		t.Fatalf("Expected the open circuit to skip the producer creation, got err=%v attempts=%d", err, attempts)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if err := lazy.PublishMessage(context.Background(), "key", []byte("value")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if attempts != 2 || producers[1].published != 1 {
+		t.Errorf("Expected a new producer to publish, got attempts=%d published=%d", attempts, producers[1].published)
+	}
+	if breaker.State() != StateClosed {
+		t.Errorf("Expected the publish success to close the circuit, got %s", breaker.State())
+	}
+}
+
+func TestLazyProducerReportsCircuitInReadiness(t *testing.T) {
+	attempts := 0
+	breaker := NewCircuitBreaker("kafka", 1, 20*time.Millisecond)
+	lazy := NewLazyProducer(func() (adapters.MessageProducer, error) {
+		attempts++
+		return &fakeProducer{}, nil
+	}, breaker)
+
+	if err := lazy.Check(context.Background()); err != nil || attempts != 0 {
+		t.Fatalf("Expected readiness without creating the producer, got err=%v attempts=%d", err, attempts)
+	}
+	breaker.Record(errUnavailable)
+	if err := lazy.Check(context.Background()); !errors.Is(err, errUnavailable) {
+		t.Fatalf("Expected the open circuit in readiness, got %v", err)
+	}
+	if lazy.Name() != "kafka" {
+		t.Errorf("Expected name kafka, got %s", lazy.Name())
+	}
+
+	// ao fim do openTimeout a prontidão volta para que a próxima publicação teste o broker
+	time.Sleep(30 * time.Millisecond)
+	if err := lazy.Check(context.Background()); err != nil {
+		t.Errorf("Expected readiness after the open timeout, got %v", err)
+	}
+	if breaker.State() != StateHalfOpen || attempts != 0 {
+		t.Errorf("Expected a half-open circuit without publishing, got %s attempts=%d", breaker.State(), attempts)
+	}
+}
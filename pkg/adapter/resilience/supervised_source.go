@@ -0,0 +1,163 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+)
+
+var errSourceClosed = errors.New("o recebimento de mensagens foi fechado")
+
+// SupervisedSource cria o JobSource sob demanda e o recria quando ele falha, aguardando o backoff entre
+// as tentativas. Assim uma queda do broker na partida ou durante o consumo não interrompe o worker
+type SupervisedSource struct {
+	name    string
+	factory func() (adapters.JobSource, error)
+	breaker *CircuitBreaker
+	backoff Backoff
+
+	mu     sync.Mutex
+	source adapters.JobSource
+	paused bool
+	closed bool
+}
+
+func NewSupervisedSource(factory func() (adapters.JobSource, error), breaker *CircuitBreaker, backoff Backoff) *SupervisedSource {
+	return &SupervisedSource{name: breaker.Name(), factory: factory, breaker: breaker, backoff: backoff}
+}
+
+func (s *SupervisedSource) Receive(ctx context.Context) (adapters.Delivery, error) {
+	for attempt := 0; ; attempt++ {
+		source, err := s.current()
+		if errors.Is(err, errSourceClosed) {
+			return nil, err
+		}
+		if err == nil {
+			var delivery adapters.Delivery
+			delivery, err = source.Receive(ctx)
+			if err == nil {
+				s.breaker.Record(nil)
+				return delivery, nil
+			}
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			s.discard(source)
+		}
+		s.breaker.Record(err)
+
+		delay := s.backoff.Delay(attempt)
+		slog.WarnContext(ctx, "falha no recebimento de mensagens, reiniciando o consumidor", "dependency", s.name, "attempt", attempt+1, "retryIn", delay, "error", err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// current retorna o consumidor ativo, criando um novo quando necessário. A criação acontece fora do
+// lock, pois pode demorar até o timeout da conexão
+func (s *SupervisedSource) current() (adapters.JobSource, error) {
+	s.mu.Lock()
+	source, closed := s.source, s.closed
+	s.mu.Unlock()
+	if closed {
+		return nil, errSourceClosed
+	}
+	if source != nil {
+		return source, nil
+	}
+
+	source, err := s.factory()
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		_ = source.Close()
+		return nil, errSourceClosed
+	}
+	// a pausa pedida enquanto não havia consumidor vale também para o novo
+	if s.paused {
+		source.PauseAll()
+	}
+	s.source = source
+	slog.Info("consumidor de mensagens conectado", "dependency", s.name)
+	return source, nil
+}
+
+func (s *SupervisedSource) discard(source adapters.JobSource) {
+	s.mu.Lock()
+	if s.source == source {
+		s.source = nil
+	}
+	s.mu.Unlock()
+	if err := source.Close(); err != nil {
+		slog.Warn("erro ao fechar o consumidor com falha", "dependency", s.name, "error", err)
+	}
+}
+
+// Stop encerra o recebimento sem recriar o consumidor, mantendo a conexão do consumidor atual para as
+// confirmações das entregas em andamento
+func (s *SupervisedSource) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.source == nil {
+		return nil
+	}
+	return s.source.Stop()
+}
+
+func (s *SupervisedSource) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.source == nil {
+		return nil
+	}
+	return s.source.Close()
+}
+
+func (s *SupervisedSource) PauseAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paused = true
+	if s.source != nil {
+		s.source.PauseAll()
+	}
+}
+
+func (s *SupervisedSource) ResumeAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paused = false
+	if s.source != nil {
+		s.source.ResumeAll()
+	}
+}
+
+// Name e Check permitem usar o consumidor como verificação da prontidão
+func (s *SupervisedSource) Name() string {
+	return s.name
+}
+
+func (s *SupervisedSource) Check(ctx context.Context) error {
+	s.mu.Lock()
+	source := s.source
+	s.mu.Unlock()
+	if source == nil {
+		return fmt.Errorf("consumidor %s não conectado", s.name)
+	}
+	if check, ok := source.(adapters.HealthCheck); ok {
+		return check.Check(ctx)
+	}
+	return nil
+}
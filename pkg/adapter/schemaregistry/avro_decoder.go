@@ -0,0 +1,131 @@
+package schemaregistry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	"github.com/hamba/avro/v2"
+)
+
+// AvroDecoder decodifica as mensagens Avro, mantendo os schemas já interpretados por id
+type AvroDecoder struct {
+	client *Client
+
+	mu      sync.RWMutex
+	schemas map[int]avro.Schema
+}
+
+func NewAvroDecoder(client *Client) *AvroDecoder {
+	return &AvroDecoder{client: client, schemas: make(map[int]avro.Schema)}
+}
+
+func (d *AvroDecoder) SchemaType() string {
+	return SchemaTypeAvro
+}
+
+func (d *AvroDecoder) Decode(ctx context.Context, schema *Schema, payload []byte) (any, error) {
+	parsed, err := d.parse(ctx, schema)
+	if err != nil {
+		return nil, err
+	}
+	var document any
+	if err := avro.Unmarshal(parsed, payload, &document); err != nil {
+		return nil, fmt.Errorf("%w: %v", adapters.ErrUndecodablePayload, err)
+	}
+	return unwrapUnions(parsed, document), nil
+}
+
+func (d *AvroDecoder) parse(ctx context.Context, schema *Schema) (avro.Schema, error) {
+	d.mu.RLock()
+	parsed, ok := d.schemas[schema.ID]
+	d.mu.RUnlock()
+	if ok {
+		return parsed, nil
+	}
+
+	// os tipos das referências são registrados no cache antes do schema que os usa
+	cache := &avro.SchemaCache{}
+	if err := d.parseReferences(ctx, schema.References, cache); err != nil {
+		return nil, err
+	}
+	parsed, err := avro.ParseWithCache(schema.Schema, "", cache)
+	if err != nil {
+		return nil, fmt.Errorf("%w: schema avro %d inválido: %v", adapters.ErrUndecodablePayload, schema.ID, err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.schemas[schema.ID] = parsed
+	return parsed, nil
+}
+
+func (d *AvroDecoder) parseReferences(ctx context.Context, references []Reference, cache *avro.SchemaCache) error {
+	for _, reference := range references {
+		referenced, err := d.client.SchemaBySubjectVersion(ctx, reference.Subject, reference.Version)
+		if err != nil {
+			return err
+		}
+		if err := d.parseReferences(ctx, referenced.References, cache); err != nil {
+			return err
+		}
+		if _, err := avro.ParseWithCache(referenced.Schema, "", cache); err != nil {
+			return fmt.Errorf("%w: referência avro %s inválida: %v", adapters.ErrUndecodablePayload, reference.Name, err)
+		}
+	}
+	return nil
+}
+
+// unwrapUnions remove o envelope {"nome do tipo": valor} usado pelo avro nos valores de unions,
+// deixando o documento com o mesmo formato do JSON
+func unwrapUnions(schema avro.Schema, value any) any {
+	switch typed := schema.(type) {
+	case *avro.RecordSchema:
+		fields, ok := value.(map[string]any)
+		if !ok {
+			return value
+		}
+		for _, field := range typed.Fields() {
+			if fieldValue, ok := fields[field.Name()]; ok {
+				fields[field.Name()] = unwrapUnions(field.Type(), fieldValue)
+			}
+		}
+	case *avro.ArraySchema:
+		if items, ok := value.([]any); ok {
+			for i, item := range items {
+				items[i] = unwrapUnions(typed.Items(), item)
+			}
+		}
+	case *avro.MapSchema:
+		if values, ok := value.(map[string]any); ok {
+			for key, item := range values {
+				values[key] = unwrapUnions(typed.Values(), item)
+			}
+		}
+	case *avro.UnionSchema:
+		if value == nil {
+			return nil
+		}
+		if wrapped, ok := value.(map[string]any); ok && len(wrapped) == 1 {
+			for _, member := range typed.Types() {
+				if inner, ok := wrapped[unionMemberName(member)]; ok {
+					return unwrapUnions(member, inner)
+				}
+			}
+		}
+		for _, member := range typed.Types() {
+			if member.Type() != avro.Null {
+				return unwrapUnions(member, value)
+			}
+		}
+	}
+	return value
+}
+
+func unionMemberName(schema avro.Schema) string {
+	if named, ok := schema.(avro.NamedSchema); ok {
+		return named.FullName()
+	}
+	return string(schema.Type())
+}
@@ -0,0 +1,77 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+)
+
+const (
+	// magicByte inicia as mensagens no wire format da Confluent, seguido do id do schema em 4 bytes
+	magicByte        = 0
+	wireHeaderLength = 5
+)
+
+// SchemaDecoder decodifica o conteúdo das mensagens de um tipo de schema do registry
+type SchemaDecoder interface {
+	SchemaType() string
+	Decode(ctx context.Context, schema *Schema, payload []byte) (any, error)
+}
+
+// ConfluentFormat é o adapters.PayloadFormat das mensagens no wire format da Confluent. O id do
+// schema no início da mensagem escolhe o SchemaDecoder pelo tipo do schema registrado
+type ConfluentFormat struct {
+	client   *Client
+	decoders map[string]SchemaDecoder
+}
+
+func NewConfluentFormat(client *Client, decoders ...SchemaDecoder) adapters.PayloadFormat {
+	format := &ConfluentFormat{client: client, decoders: make(map[string]SchemaDecoder)}
+	for _, decoder := range decoders {
+		format.decoders[decoder.SchemaType()] = decoder
+	}
+	return format
+}
+
+// Accepts identifica o wire format pelo magic byte, que não inicia nenhum documento JSON válido
+func (f *ConfluentFormat) Accepts(_ string, data []byte) bool {
+	return len(data) >= wireHeaderLength && data[0] == magicByte
+}
+
+func (f *ConfluentFormat) Decode(ctx context.Context, data []byte) (any, error) {
+	if !f.Accepts("", data) {
+		return nil, fmt.Errorf("%w: mensagem fora do wire format", adapters.ErrUndecodablePayload)
+	}
+	schemaID := int(binary.BigEndian.Uint32(data[1:wireHeaderLength]))
+	schema, err := f.client.SchemaByID(ctx, schemaID)
+	if err != nil {
+		return nil, err
+	}
+	decoder, ok := f.decoders[schema.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w: schemas do tipo %s não são suportados", adapters.ErrUndecodablePayload, schema.Type)
+	}
+	return decoder.Decode(ctx, schema, data[wireHeaderLength:])
+}
+
+// JSONDecoder decodifica as mensagens dos schemas JSON, que o registry usa apenas para identificar o schema
+type JSONDecoder struct{}
+
+func NewJSONDecoder() *JSONDecoder {
+	return &JSONDecoder{}
+}
+
+func (d *JSONDecoder) SchemaType() string {
+	return SchemaTypeJSON
+}
+
+func (d *JSONDecoder) Decode(_ context.Context, _ *Schema, payload []byte) (any, error) {
+	var document any
+	if err := json.Unmarshal(payload, &document); err != nil {
+		return nil, fmt.Errorf("%w: %v", adapters.ErrUndecodablePayload, err)
+	}
+	return document, nil
+}
@@ -0,0 +1,197 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// ProtobufDecoder decodifica as mensagens Protobuf compilando o .proto registrado, mantendo os
+// arquivos já compilados por id
+type ProtobufDecoder struct {
+	client *Client
+
+	mu    sync.RWMutex
+	files map[int]protoreflect.FileDescriptor
+}
+
+func NewProtobufDecoder(client *Client) *ProtobufDecoder {
+	return &ProtobufDecoder{client: client, files: make(map[int]protoreflect.FileDescriptor)}
+}
+
+func (d *ProtobufDecoder) SchemaType() string {
+	return SchemaTypeProtobuf
+}
+
+// Decode lê os índices da mensagem, que identificam o tipo dentro do .proto, e em seguida a mensagem
+func (d *ProtobufDecoder) Decode(ctx context.Context, schema *Schema, payload []byte) (any, error) {
+	file, err := d.compile(ctx, schema)
+	if err != nil {
+		return nil, err
+	}
+	indexes, payload, err := readMessageIndexes(payload)
+	if err != nil {
+		return nil, err
+	}
+	descriptor, err := messageDescriptor(file, indexes)
+	if err != nil {
+		return nil, err
+	}
+
+	message := dynamicpb.NewMessage(descriptor)
+	if err := proto.Unmarshal(payload, message); err != nil {
+		return nil, fmt.Errorf("%w: %v", adapters.ErrUndecodablePayload, err)
+	}
+	return messageToMap(message), nil
+}
+
+func (d *ProtobufDecoder) compile(ctx context.Context, schema *Schema) (protoreflect.FileDescriptor, error) {
+	d.mu.RLock()
+	file, ok := d.files[schema.ID]
+	d.mu.RUnlock()
+	if ok {
+		return file, nil
+	}
+
+	fileName := fmt.Sprintf("schema-%d.proto", schema.ID)
+	sources := map[string]string{fileName: schema.Schema}
+	if err := d.collectReferences(ctx, schema.References, sources); err != nil {
+		return nil, err
+	}
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{Accessor: protocompile.SourceAccessorFromMap(sources)}),
+	}
+	files, err := compiler.Compile(ctx, fileName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: schema protobuf %d inválido: %v", adapters.ErrUndecodablePayload, schema.ID, err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.files[schema.ID] = files[0]
+	return files[0], nil
+}
+
+// collectReferences busca os arquivos importados, registrados no nome usado no import
+func (d *ProtobufDecoder) collectReferences(ctx context.Context, references []Reference, sources map[string]string) error {
+	for _, reference := range references {
+		if _, ok := sources[reference.Name]; ok {
+			continue
+		}
+		referenced, err := d.client.SchemaBySubjectVersion(ctx, reference.Subject, reference.Version)
+		if err != nil {
+			return err
+		}
+		sources[reference.Name] = referenced.Schema
+		if err := d.collectReferences(ctx, referenced.References, sources); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readMessageIndexes lê a lista de índices em varint zigzag. A lista vazia, gravada como um único
+// zero, indica a primeira mensagem do arquivo
+func readMessageIndexes(payload []byte) ([]int, []byte, error) {
+	count, read := binary.Varint(payload)
+	if read <= 0 || count < 0 {
+		return nil, nil, fmt.Errorf("%w: índices da mensagem protobuf inválidos", adapters.ErrUndecodablePayload)
+	}
+	payload = payload[read:]
+	if count == 0 {
+		return []int{0}, payload, nil
+	}
+	indexes := make([]int, 0, count)
+	for range count {
+		index, read := binary.Varint(payload)
+		if read <= 0 || index < 0 {
+			return nil, nil, fmt.Errorf("%w: índices da mensagem protobuf inválidos", adapters.ErrUndecodablePayload)
+		}
+		indexes = append(indexes, int(index))
+		payload = payload[read:]
+	}
+	return indexes, payload, nil
+}
+
+func messageDescriptor(file protoreflect.FileDescriptor, indexes []int) (protoreflect.MessageDescriptor, error) {
+	messages := file.Messages()
+	var descriptor protoreflect.MessageDescriptor
+	for _, index := range indexes {
+		if index >= messages.Len() {
+			return nil, fmt.Errorf("%w: mensagem %v não existe no schema", adapters.ErrUndecodablePayload, indexes)
+		}
+		descriptor = messages.Get(index)
+		messages = descriptor.Messages()
+	}
+	return descriptor, nil
+}
+
+// messageToMap converte a mensagem no documento JSON com os nomes dos campos do .proto. Campos não
+// informados ficam de fora, assim como no JSON dos produtores que omitem campos vazios
+func messageToMap(message protoreflect.Message) map[string]any {
+	document := make(map[string]any)
+	message.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		switch {
+		case field.IsList():
+			list := value.List()
+			items := make([]any, list.Len())
+			for i := range items {
+				items[i] = singularValue(field, list.Get(i))
+			}
+			document[string(field.Name())] = items
+		case field.IsMap():
+			entries := make(map[string]any)
+			value.Map().Range(func(key protoreflect.MapKey, entry protoreflect.Value) bool {
+				entries[key.String()] = singularValue(field.MapValue(), entry)
+				return true
+			})
+			document[string(field.Name())] = entries
+		default:
+			document[string(field.Name())] = singularValue(field, value)
+		}
+		return true
+	})
+	return document
+}
+
+func singularValue(field protoreflect.FieldDescriptor, value protoreflect.Value) any {
+	switch field.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return messageToMap(value.Message())
+	case protoreflect.EnumKind:
+		if enumValue := field.Enum().Values().ByNumber(value.Enum()); enumValue != nil {
+			return enumName(field.Enum(), enumValue)
+		}
+		return int32(value.Enum())
+	default:
+		return value.Interface()
+	}
+}
+
+// enumName converte o valor do enum para o formato dos payloads JSON: sem o prefixo com o nome do enum,
+// recomendado pelo guia de estilo do Protobuf, e em minúsculas. PRIORITY_HIGH e HIGH viram high
+func enumName(enum protoreflect.EnumDescriptor, value protoreflect.EnumValueDescriptor) string {
+	name := strings.TrimPrefix(string(value.Name()), screamingSnakeCase(string(enum.Name()))+"_")
+	return strings.ToLower(name)
+}
+
+// screamingSnakeCase converte o nome em CamelCase do enum para o prefixo dos seus valores: JobPriority vira JOB_PRIORITY
+func screamingSnakeCase(name string) string {
+	var builder strings.Builder
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) && !unicode.IsUpper(rune(name[i-1])) {
+			builder.WriteByte('_')
+		}
+		builder.WriteRune(unicode.ToUpper(r))
+	}
+	return builder.String()
+}
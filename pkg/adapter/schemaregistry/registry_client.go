@@ -0,0 +1,142 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+)
+
+const (
+	SchemaTypeAvro     = "AVRO"
+	SchemaTypeProtobuf = "PROTOBUF"
+	SchemaTypeJSON     = "JSON"
+)
+
+// Schema é um schema registrado. Type vazio no registry indica Avro
+type Schema struct {
+	ID         int         `json:"id"`
+	Type       string      `json:"schemaType"`
+	Schema     string      `json:"schema"`
+	References []Reference `json:"references"`
+}
+
+// Reference aponta para outro schema usado pelo schema, como um import do protobuf
+type Reference struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
+
+// Client consulta um schema registry compatível com o da Confluent. Os schemas de um id ou de uma
+// versão de subject não mudam depois de registrados e por isso ficam em cache sem expiração
+type Client struct {
+	baseURL    string
+	username   string
+	password   string
+	httpClient *http.Client
+
+	mu        sync.RWMutex
+	byID      map[int]*Schema
+	byVersion map[string]*Schema
+}
+
+func NewClient(baseURL string, username string, password string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		username:   username,
+		password:   password,
+		httpClient: &http.Client{Timeout: timeout},
+		byID:       make(map[int]*Schema),
+		byVersion:  make(map[string]*Schema),
+	}
+}
+
+// SchemaByID retorna o schema usado no wire format das mensagens
+func (c *Client) SchemaByID(ctx context.Context, id int) (*Schema, error) {
+	c.mu.RLock()
+	schema, ok := c.byID[id]
+	c.mu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+
+	schema = &Schema{}
+	if err := c.get(ctx, fmt.Sprintf("/schemas/ids/%d", id), schema); err != nil {
+		return nil, err
+	}
+	schema.ID = id
+	schema.Type = normalizeSchemaType(schema.Type)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.byID[id] = schema
+	return schema, nil
+}
+
+// SchemaBySubjectVersion retorna a versão do subject, usada para resolver as referências dos schemas
+func (c *Client) SchemaBySubjectVersion(ctx context.Context, subject string, version int) (*Schema, error) {
+	key := fmt.Sprintf("%s/%d", subject, version)
+	c.mu.RLock()
+	schema, ok := c.byVersion[key]
+	c.mu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+
+	schema = &Schema{}
+	if err := c.get(ctx, fmt.Sprintf("/subjects/%s/versions/%d", url.PathEscape(subject), version), schema); err != nil {
+		return nil, err
+	}
+	schema.Type = normalizeSchemaType(schema.Type)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.byVersion[key] = schema
+	c.byID[schema.ID] = schema
+	return schema, nil
+}
+
+// get faz a consulta ao registry. Schemas inexistentes são adapters.ErrUndecodablePayload, pois a
+// mensagem nunca poderá ser lida; as demais falhas são temporárias
+func (c *Client) get(ctx context.Context, path string, target any) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if c.username != "" {
+		request.SetBasicAuth(c.username, c.password)
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		slog.ErrorContext(ctx, "erro ao consultar o schema registry", "path", path, "error", err)
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: schema não encontrado no registry: %s", adapters.ErrUndecodablePayload, path)
+	}
+	if response.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("schema registry respondeu %d para %s: %s", response.StatusCode, path, body)
+	}
+	return json.NewDecoder(response.Body).Decode(target)
+}
+
+func normalizeSchemaType(schemaType string) string {
+	if schemaType == "" {
+		return SchemaTypeAvro
+	}
+	return strings.ToUpper(schemaType)
+}
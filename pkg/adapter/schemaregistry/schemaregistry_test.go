@@ -0,0 +1,302 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/backstagefood/video-processor-worker/internal/domain/interface/adapters"
+	"github.com/bufbuild/protocompile"
+	"github.com/hamba/avro/v2"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	avroSchemaID     = 1
+	protobufSchemaID = 2
+	jsonSchemaID     = 3
+	// prefixedEnumSchemaID usa os valores de enum com o prefixo do nome do enum
+	prefixedEnumSchemaID = 4
+
+	avroSchema = `{
+		"type": "record",
+		"name": "VideoFrames",
+		"namespace": "backstagefood",
+		"fields": [
+			{"name": "user_name", "type": "string"},
+			{"name": "file_size", "type": "long"},
+			{"name": "priority", "type": ["null", "string"], "default": null},
+			{"name": "extraction_options", "type": ["null", {
+				"type": "record",
+				"name": "ExtractionOptions",
+				"fields": [{"name": "fps", "type": "double"}]
+			}], "default": null}
+		]
+	}`
+
+	commonProto = `syntax = "proto3";
+package backstagefood;
+message ExtractionOptions {
+  double fps = 1;
+}`
+
+	jobProto = `syntax = "proto3";
+package backstagefood;
+import "common.proto";
+message Envelope {
+  string id = 1;
+}
+message Jobs {
+  enum Priority {
+    NORMAL = 0;
+    HIGH = 1;
+  }
+  message VideoFrames {
+    string user_name = 1;
+    int64 file_size = 2;
+    Priority priority = 3;
+    ExtractionOptions extraction_options = 4;
+    repeated string tags = 5;
+  }
+}`
+
+	prefixedEnumProto = `syntax = "proto3";
+package backstagefood;
+enum JobPriority {
+  JOB_PRIORITY_UNSPECIFIED = 0;
+  JOB_PRIORITY_LOW = 1;
+  JOB_PRIORITY_HIGH = 2;
+}
+message VideoFrames {
+  string user_name = 1;
+  JobPriority priority = 2;
+}`
+)
+
+// fakeRegistry é um schema registry em memória que conta as consultas recebidas
+type fakeRegistry struct {
+	server   *httptest.Server
+	requests atomic.Int32
+}
+
+func newFakeRegistry(t *testing.T) *fakeRegistry {
+	t.Helper()
+	registry := &fakeRegistry{}
+	responses := map[string]any{
+		"/schemas/ids/1": map[string]any{"schema": avroSchema},
+		"/schemas/ids/2": map[string]any{
+			"schemaType": "PROTOBUF",
+			"schema":     jobProto,
+			"references": []map[string]any{{"name": "common.proto", "subject": "common", "version": 1}},
+		},
+		"/schemas/ids/3":              map[string]any{"schemaType": "JSON", "schema": `{"type":"object"}`},
+		"/schemas/ids/4":              map[string]any{"schemaType": "PROTOBUF", "schema": prefixedEnumProto},
+		"/subjects/common/versions/1": map[string]any{"id": 10, "schemaType": "PROTOBUF", "schema": commonProto},
+	}
+	registry.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		registry.requests.Add(1)
+		if user, password, ok := r.BasicAuth(); !ok || user != "user" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code":40403,"message":"Schema not found"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(registry.server.Close)
+	return registry
+}
+
+func (r *fakeRegistry) format() adapters.PayloadFormat {
+	client := NewClient(r.server.URL, "user", "secret", time.Second)
+	return NewConfluentFormat(client, NewAvroDecoder(client), NewProtobufDecoder(client), NewJSONDecoder())
+}
+
+func wireFormat(schemaID int, payload []byte) []byte {
+	data := []byte{magicByte, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(data[1:], uint32(schemaID))
+	return append(data, payload...)
+}
+
+func TestConfluentFormatDecodesAvro(t *testing.T) {
+	registry := newFakeRegistry(t)
+	format := registry.format()
+
+	schema := avro.MustParse(avroSchema)
+	payload, err := avro.Marshal(schema, map[string]any{
+		"user_name":          "user@test.com",
+		"file_size":          int64(10),
+		"priority":           map[string]any{"string": "high"},
+		"extraction_options": map[string]any{"backstagefood.ExtractionOptions": map[string]any{"fps": 2.0}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for range 2 {
+		document, err := format.Decode(context.Background(), wireFormat(avroSchemaID, payload))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		assertDocument(t, document, `{"extraction_options":{"fps":2},"file_size":10,"priority":"high","user_name":"user@test.com"}`)
+	}
+	if requests := registry.requests.Load(); requests != 1 {
+		t.Errorf("Expected the schema to be fetched once, got %d requests", requests)
+	}
+}
+
+func TestConfluentFormatDecodesNestedProtobufMessage(t *testing.T) {
+	registry := newFakeRegistry(t)
+	format := registry.format()
+
+	compiler := protocompile.Compiler{
+		Resolver: &protocompile.SourceResolver{Accessor: protocompile.SourceAccessorFromMap(map[string]string{
+			"job.proto":    jobProto,
+			"common.proto": commonProto,
+		})},
+	}
+	files, err := compiler.Compile(context.Background(), "job.proto")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	descriptor := files[0].Messages().ByName("Jobs").Messages().ByName("VideoFrames")
+	message := dynamicpb.NewMessage(descriptor)
+	message.Set(descriptor.Fields().ByName("user_name"), protoreflect.ValueOfString("user@test.com"))
+	message.Set(descriptor.Fields().ByName("file_size"), protoreflect.ValueOfInt64(10))
+	message.Set(descriptor.Fields().ByName("priority"), protoreflect.ValueOfEnum(1))
+	options := message.Mutable(descriptor.Fields().ByName("extraction_options")).Message()
+	options.Set(options.Descriptor().Fields().ByName("fps"), protoreflect.ValueOfFloat64(2))
+	tags := message.Mutable(descriptor.Fields().ByName("tags")).List()
+	tags.Append(protoreflect.ValueOfString("a"))
+	encoded, err := proto.Marshal(message)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// índices [1, 0]: a mensagem VideoFrames dentro da segunda mensagem do arquivo
+	indexes := binary.AppendVarint(nil, 2)
+	indexes = binary.AppendVarint(indexes, 1)
+	indexes = binary.AppendVarint(indexes, 0)
+
+	document, err := format.Decode(context.Background(), wireFormat(protobufSchemaID, append(indexes, encoded...)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	assertDocument(t, document, `{"extraction_options":{"fps":2},"file_size":10,"priority":"high","tags":["a"],"user_name":"user@test.com"}`)
+}
+
+func TestConfluentFormatNormalisesPrefixedProtobufEnums(t *testing.T) {
+	format := newFakeRegistry(t).format()
+
+	files, err := (&protocompile.Compiler{
+		Resolver: &protocompile.SourceResolver{Accessor: protocompile.SourceAccessorFromMap(map[string]string{"job.proto": prefixedEnumProto})},
+	}).Compile(context.Background(), "job.proto")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	descriptor := files[0].Messages().ByName("VideoFrames")
+	message := dynamicpb.NewMessage(descriptor)
+	message.Set(descriptor.Fields().ByName("user_name"), protoreflect.ValueOfString("user@test.com"))
+	message.Set(descriptor.Fields().ByName("priority"), protoreflect.ValueOfEnum(2))
+	encoded, err := proto.Marshal(message)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	document, err := format.Decode(context.Background(), wireFormat(prefixedEnumSchemaID, append([]byte{0}, encoded...)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// as prioridades do domínio são high, normal e low
+	assertDocument(t, document, `{"priority":"high","user_name":"user@test.com"}`)
+}
+
+func TestConfluentFormatDecodesFirstProtobufMessage(t *testing.T) {
+	format := newFakeRegistry(t).format()
+
+	document, err := format.Decode(context.Background(), wireFormat(protobufSchemaID, append([]byte{0}, 0x0a, 0x01, 'x')))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	assertDocument(t, document, `{"id":"x"}`)
+}
+
+func TestConfluentFormatDecodesJSONSchema(t *testing.T) {
+	format := newFakeRegistry(t).format()
+
+	document, err := format.Decode(context.Background(), wireFormat(jsonSchemaID, []byte(`{"user_name":"user@test.com"}`)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	assertDocument(t, document, `{"user_name":"user@test.com"}`)
+}
+
+func TestConfluentFormatRejectsUndecodablePayloads(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"unknown schema", wireFormat(99, []byte{0})},
+		{"corrupted avro", wireFormat(avroSchemaID, []byte{0x02})},
+		{"missing protobuf message", wireFormat(protobufSchemaID, []byte{2, 8, 0})},
+		{"invalid json", wireFormat(jsonSchemaID, []byte("{"))},
+	}
+
+	format := newFakeRegistry(t).format()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := format.Decode(context.Background(), tt.data)
+			if !errors.Is(err, adapters.ErrUndecodablePayload) {
+				t.Fatalf("Expected ErrUndecodablePayload, got %v", err)
+			}
+		})
+	}
+}
+
+func TestConfluentFormatTreatsRegistryFailuresAsTransient(t *testing.T) {
+	registry := newFakeRegistry(t)
+	client := NewClient(registry.server.URL, "user", "wrong", time.Second)
+	format := NewConfluentFormat(client, NewAvroDecoder(client))
+
+	_, err := format.Decode(context.Background(), wireFormat(avroSchemaID, []byte{0}))
+	if err == nil || errors.Is(err, adapters.ErrUndecodablePayload) {
+		t.Fatalf("Expected a transient error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "401") {
+		t.Errorf("Expected the registry status in the error, got %v", err)
+	}
+}
+
+func TestConfluentFormatAcceptsOnlyWireFormat(t *testing.T) {
+	format := NewConfluentFormat(nil)
+	if format.Accepts("application/json", []byte(`{"user_name":"user@test.com"}`)) {
+		t.Error("Expected JSON payloads to be left to the JSON decoder")
+	}
+	if !format.Accepts("", wireFormat(avroSchemaID, []byte{0})) {
+		t.Error("Expected wire format payloads to be accepted")
+	}
+}
+
+func assertDocument(t *testing.T, document any, want string) {
+	t.Helper()
+	got, err := json.Marshal(document)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
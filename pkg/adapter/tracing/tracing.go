@@ -0,0 +1,79 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/backstagefood/video-processor-worker/pkg/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const defaultServiceName = "video-processor-worker"
+
+// Setup configura a propagação do contexto de trace e o exportador escolhido em OTEL_TRACES_EXPORTER:
+// none, otlp ou stdout. O exportador otlp envia os spans via HTTP para o endpoint de
+// OTEL_EXPORTER_OTLP_ENDPOINT e a amostragem segue OTEL_TRACES_SAMPLER, como nos demais SDKs.
+// A função retornada envia os spans pendentes e deve ser chamada no desligamento
+func Setup(ctx context.Context, settings config.TracingConfig) (func(context.Context) error, error) {
+	// a propagação é configurada mesmo sem exportador, mantendo o trace entre os serviços
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	exporterName := settings.Exporter
+	switch exporterName {
+	case config.TracesExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case config.TracesExporterOTLP:
+		exporter, err = otlptracehttp.New(ctx)
+	case config.TracesExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		return nil, fmt.Errorf("OTEL_TRACES_EXPORTER inválido: %s", exporterName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// OTEL_SERVICE_NAME e OTEL_RESOURCE_ATTRIBUTES substituem o nome padrão do serviço
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", defaultServiceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	slog.Info("exportação de traces habilitada", "exporter", exporterName)
+	return provider.Shutdown, nil
+}
+
+// InjectHeaders retorna uma cópia dos headers da mensagem com o contexto de trace de ctx, que
+// substitui o contexto recebido de mensagens anteriores
+func InjectHeaders(ctx context.Context, headers map[string]string) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return headers
+	}
+	injected := make(map[string]string, len(headers)+len(carrier))
+	for name, value := range headers {
+		injected[name] = value
+	}
+	for name, value := range carrier {
+		injected[name] = value
+	}
+	return injected
+}
@@ -0,0 +1,68 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func testSpanContext(t *testing.T) trace.SpanContext {
+	t.Helper()
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled})
+}
+
+func TestLogHandlerAddsTraceAndSpanIds(t *testing.T) {
+	var output bytes.Buffer
+	logger := slog.New(NewLogHandler(slog.NewTextHandler(&output, nil))).With("component", "test")
+
+	logger.InfoContext(context.Background(), "sem trace")
+	if strings.Contains(output.String(), "trace_id") {
+		t.Errorf("Expected no trace id without an active span, got %q", output.String())
+	}
+
+	output.Reset()
+	ctx := trace.ContextWithSpanContext(context.Background(), testSpanContext(t))
+	logger.InfoContext(ctx, "com trace")
+	if !strings.Contains(output.String(), "trace_id=4bf92f3577b34da6a3ce929d0e0e4736") ||
+		!strings.Contains(output.String(), "span_id=00f067aa0ba902b7") ||
+		!strings.Contains(output.String(), "component=test") {
+		t.Errorf("Unexpected log record: %q", output.String())
+	}
+}
+
+func TestInjectHeaders(t *testing.T) {
+	previousPropagator := otel.GetTextMapPropagator()
+	t.Cleanup(func() { otel.SetTextMapPropagator(previousPropagator) })
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	headers := map[string]string{"job-type": "video", "traceparent": "00-00000000000000000000000000000001-0000000000000001-01"}
+
+	if injected := InjectHeaders(context.Background(), headers); injected["traceparent"] != headers["traceparent"] {
+		t.Errorf("Expected headers to be kept without an active span, got %v", injected)
+	}
+
+	ctx := trace.ContextWithSpanContext(context.Background(), testSpanContext(t))
+	injected := InjectHeaders(ctx, headers)
+	if injected["traceparent"] != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Errorf("Unexpected traceparent: %q", injected["traceparent"])
+	}
+	if injected["job-type"] != "video" {
+		t.Errorf("Expected the original headers to be kept, got %v", injected)
+	}
+	if headers["traceparent"] != "00-00000000000000000000000000000001-0000000000000001-01" {
+		t.Errorf("Expected the original headers to be unchanged, got %v", headers)
+	}
+}
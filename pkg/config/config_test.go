@@ -0,0 +1,292 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return path
+}
+
+func TestDefault_IsValid(t *testing.T) {
+	if err := Default().Validate(); err != nil {
+		t.Fatalf("Expected default configuration to be valid, got %v", err)
+	}
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	config, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.Worker.MaxVideos != 20 || config.Worker.MaxQueued != 20 || config.Broker.Prefetch != 20 {
+		t.Errorf("Unexpected worker defaults: %+v, prefetch %d", config.Worker, config.Broker.Prefetch)
+	}
+	if config.Worker.ClassWeights["high"] != 4 || config.Worker.ClassWeights["normal"] != 2 || config.Worker.ClassWeights["low"] != 1 {
+		t.Errorf("Unexpected class weights: %v", config.Worker.ClassWeights)
+	}
+	if config.Health.CheckTimeout != 2*time.Second || config.Retention.JanitorInterval != time.Hour {
+		t.Errorf("Unexpected durations: %v, %v", config.Health.CheckTimeout, config.Retention.JanitorInterval)
+	}
+	if config.JobQueue.DeadLetterName != "videos.dlq" || config.NATS.DeadLetterStream != "VIDEOS_DLQ" {
+		t.Errorf("Unexpected derived dead letter names: %s, %s", config.JobQueue.DeadLetterName, config.NATS.DeadLetterStream)
+	}
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	for name, content := range map[string]string{
+		"config.yaml": "worker:\n  max_videos: 5\n  max_queued: 7\n  default_job_type: from.file\n",
+		"config.toml": "[worker]\nmax_videos = 5\nmax_queued = 7\ndefault_job_type = \"from.file\"\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(ConfigFileEnv, writeFile(t, name, content))
+			t.Setenv("MAX_VIDEOS", "8")
+			t.Setenv("JOB_DEFAULT_TYPE", "from.env")
+
+			config, err := Load([]string{"--worker.default-job-type", "from.flag"})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if config.Worker.MaxQueued != 7 {
+				t.Errorf("Expected value from file, got %d", config.Worker.MaxQueued)
+			}
+			if config.Worker.MaxVideos != 8 {
+				t.Errorf("Expected env to override file, got %d", config.Worker.MaxVideos)
+			}
+			if config.Worker.DefaultJobType != "from.flag" {
+				t.Errorf("Expected flag to override env, got %s", config.Worker.DefaultJobType)
+			}
+		})
+	}
+}
+
+func TestLoad_ConfigFlagOverridesEnv(t *testing.T) {
+	t.Setenv(ConfigFileEnv, filepath.Join(t.TempDir(), "missing.yaml"))
+	path := writeFile(t, "config.yml", "extraction:\n  fps: 2.5\n")
+
+	config, err := Load([]string{"--config", path})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.Extraction.FPS != 2.5 || config.File() != path {
+		t.Errorf("Unexpected configuration: fps %g, file %s", config.Extraction.FPS, config.File())
+	}
+}
+
+func TestLoad_InvalidValuesAreReported(t *testing.T) {
+	t.Setenv("MAX_VIDEOS", "abc")
+	t.Setenv("HEALTH_CHECK_TIMEOUT_MS", "soon")
+
+	_, err := Load([]string{"--server.port", "http"})
+	if err == nil {
+		t.Fatal("Expected error for invalid values")
+	}
+	for _, expected := range []string{
+		`MAX_VIDEOS inválido: "abc" não é um número inteiro (variável de ambiente MAX_VIDEOS)`,
+		"HEALTH_CHECK_TIMEOUT_MS inválido",
+		"SERVER_PORT inválido",
+		"flag --server.port",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error to contain %q, got %v", expected, err)
+		}
+	}
+}
+
+func TestLoad_ValidationErrors(t *testing.T) {
+	t.Setenv("DEFAULT_IMAGE_QUALITY", "300")
+	t.Setenv("SCHEDULER_CLASS_WEIGHTS", "high:4,urgent:2,low:0")
+	t.Setenv("MESSAGE_BROKER", "sqs")
+
+	_, err := Load(nil)
+	if err == nil {
+		t.Fatal("Expected validation error")
+	}
+	for _, expected := range []string{
+		"DEFAULT_IMAGE_QUALITY deve estar entre 1 e 100: 300",
+		"o peso da classe low deve ser maior que zero",
+		"classe de prioridade desconhecida urgent",
+		"MESSAGE_BROKER inválido: sqs",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error to contain %q, got %v", expected, err)
+		}
+	}
+}
+
+func TestLoad_InvalidClassWeights(t *testing.T) {
+	t.Setenv("SCHEDULER_CLASS_WEIGHTS", "high:4,normal")
+
+	_, err := Load(nil)
+	if err == nil || !strings.Contains(err.Error(), `item "normal" não está no formato chave:número`) {
+		t.Fatalf("Expected class weights format error, got %v", err)
+	}
+}
+
+func TestLoad_Durations(t *testing.T) {
+	t.Setenv("REAPER_INTERVAL_SECONDS", "90")
+	t.Setenv("RETENTION_JANITOR_INTERVAL_MINUTES", "1h30m")
+	t.Setenv("HEALTH_CHECK_CACHE_MS", "1.5s")
+
+	config, err := Load([]string{"--resilience.initial-backoff=250"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.Reaper.Interval != 90*time.Second {
+		t.Errorf("Expected number in field unit, got %v", config.Reaper.Interval)
+	}
+	if config.Retention.JanitorInterval != 90*time.Minute || config.Health.CacheTTL != 1500*time.Millisecond {
+		t.Errorf("Expected Go durations, got %v and %v", config.Retention.JanitorInterval, config.Health.CacheTTL)
+	}
+	if config.Resilience.InitialBackoff != 250*time.Millisecond {
+		t.Errorf("Expected flag in milliseconds, got %v", config.Resilience.InitialBackoff)
+	}
+}
+
+func TestLoad_Lists(t *testing.T) {
+	path := writeFile(t, "config.yaml", "video:\n  allowed_containers: [mp4, mov]\nworker:\n  class_weights:\n    high: 8\n    normal: 1\n")
+	t.Setenv("KAFKA_BROKER", "kafka-1:9092, kafka-2:9092,")
+
+	config, err := Load([]string{"--config", path})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Join(config.Video.AllowedContainers, ",") != "mp4,mov" {
+		t.Errorf("Unexpected containers: %v", config.Video.AllowedContainers)
+	}
+	if len(config.Worker.ClassWeights) != 2 || config.Worker.ClassWeights["high"] != 8 {
+		t.Errorf("Unexpected class weights: %v", config.Worker.ClassWeights)
+	}
+	if strings.Join(config.Kafka.Brokers, ",") != "kafka-1:9092,kafka-2:9092" {
+		t.Errorf("Expected brokers from alias env, got %v", config.Kafka.Brokers)
+	}
+}
+
+func TestLoad_SecretsFromFiles(t *testing.T) {
+	t.Setenv("DB_PASS_FILE", writeFile(t, "db_pass", "db-secret\n"))
+	adminToken := writeFile(t, "admin_token", "admin-secret")
+	emailPassword := writeFile(t, "email_password", "email-secret\r\n")
+	path := writeFile(t, "config.yaml", "admin:\n  token_file: "+adminToken+"\nemail:\n  from: worker@example.com\n")
+
+	config, err := Load([]string{"--config", path, "--email.password-file", emailPassword})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.Database.Password != "db-secret" || config.Admin.Token != "admin-secret" || config.Email.Password != "email-secret" {
+		t.Errorf("Unexpected secrets: %q, %q, %q", config.Database.Password, config.Admin.Token, config.Email.Password)
+	}
+}
+
+func TestLoad_SecretsAreNotShownInErrors(t *testing.T) {
+	t.Setenv("EMAIL_SENDER_PASSWORD", "super-secret")
+
+	_, err := Load(nil)
+	if err == nil {
+		t.Fatal("Expected error for password without sender")
+	}
+	if strings.Contains(err.Error(), "super-secret") {
+		t.Errorf("Expected secret to be hidden, got %v", err)
+	}
+}
+
+func TestLoad_UnknownFileKey(t *testing.T) {
+	path := writeFile(t, "config.yaml", "worker:\n  max_video: 5\nunknown: true\n")
+
+	_, err := Load([]string{"--config", path})
+	if err == nil || !strings.Contains(err.Error(), "chave desconhecida no arquivo "+path+": unknown") ||
+		!strings.Contains(err.Error(), "worker.max_video") {
+		t.Fatalf("Expected unknown key errors, got %v", err)
+	}
+}
+
+func TestLoad_UnsupportedFileFormat(t *testing.T) {
+	path := writeFile(t, "config.json", "{}")
+
+	if _, err := Load([]string{"--config", path}); err == nil || !strings.Contains(err.Error(), "formato do arquivo de configuração não suportado") {
+		t.Fatalf("Expected unsupported format error, got %v", err)
+	}
+}
+
+func TestLoad_UnexpectedArgument(t *testing.T) {
+	if _, err := Load([]string{"serve"}); err == nil || !strings.Contains(err.Error(), "argumento inesperado: serve") {
+		t.Fatalf("Expected unexpected argument error, got %v", err)
+	}
+}
+
+func TestPrint_RedactsSecrets(t *testing.T) {
+	t.Setenv("DB_PASS", "db-secret")
+	t.Setenv("ADMIN_API_TOKEN", "admin-secret")
+
+	config, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var out bytes.Buffer
+	if err := config.Print(&out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	printed := out.String()
+	if strings.Contains(printed, "db-secret") || strings.Contains(printed, "admin-secret") {
+		t.Fatalf("Expected secrets to be redacted, got:\n%s", printed)
+	}
+	for _, expected := range []string{"  password: '******'", "  token: '******'", "  max_videos: 20", "  check_timeout: 2s"} {
+		if !strings.Contains(printed, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, printed)
+		}
+	}
+}
+
+func TestPrint_RoundTrip(t *testing.T) {
+	t.Setenv("MAX_VIDEOS", "4")
+	t.Setenv("SCHEDULER_CLASS_WEIGHTS", "high:3,low:1")
+
+	config, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var out bytes.Buffer
+	if err := config.Print(&out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Setenv("MAX_VIDEOS", "")
+	t.Setenv("SCHEDULER_CLASS_WEIGHTS", "")
+	reloaded, err := Load([]string{"--config", writeFile(t, "config.yaml", out.String())})
+	if err != nil {
+		t.Fatalf("Expected printed configuration to load, got %v", err)
+	}
+	if reloaded.Worker.MaxVideos != 4 || len(reloaded.Worker.ClassWeights) != 2 || reloaded.Health.CheckTimeout != config.Health.CheckTimeout {
+		t.Errorf("Unexpected reloaded configuration: %+v", reloaded.Worker)
+	}
+}
+
+func TestLoad_Mode(t *testing.T) {
+	for mode, roles := range map[string][2]bool{
+		ModeAll:    {true, true},
+		ModeAPI:    {true, false},
+		ModeWorker: {false, true},
+	} {
+		config, err := Load([]string{"--mode", mode})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if config.RunsAPI() != roles[0] || config.RunsWorker() != roles[1] {
+			t.Errorf("Unexpected roles for %s: api %v, worker %v", mode, config.RunsAPI(), config.RunsWorker())
+		}
+	}
+
+	t.Setenv("RUN_MODE", "api")
+	if _, err := Load(nil); err == nil || !strings.Contains(err.Error(), "RUN_MODE inválido: api") {
+		t.Errorf("Expected invalid mode error, got %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package config
+
+import "sort"
+
+// Feature flags reconhecidas em FEATURE_FLAGS
+const (
+	// FeatureEmailNotifications envia os emails de falha, rejeição e cota excedida aos usuários
+	FeatureEmailNotifications = "email_notifications"
+)
+
+// featureDefaults é o estado de cada feature flag quando FEATURE_FLAGS não a informa
+var featureDefaults = map[string]bool{
+	FeatureEmailNotifications: true,
+}
+
+// FeatureFlags liga e desliga comportamentos da aplicação, no formato nome:true,outro:false
+type FeatureFlags map[string]bool
+
+// Enabled informa se a feature flag está ligada, usando o padrão da flag quando ela não foi informada
+func (f FeatureFlags) Enabled(name string) bool {
+	if enabled, ok := f[name]; ok {
+		return enabled
+	}
+	return featureDefaults[name]
+}
+
+func (f FeatureFlags) names() []string {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,482 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv informa o arquivo de configuração quando a flag --config não é usada
+const ConfigFileEnv = "CONFIG_FILE"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// field é um valor configurável da Config, identificado pela chave no arquivo e pelas variáveis de ambiente
+type field struct {
+	path     []string
+	envs     []string
+	def      string
+	unit     time.Duration
+	unitName string
+	secret   bool
+	// reload indica que o campo pode ser alterado sem reiniciar a aplicação
+	reload bool
+	value  reflect.Value
+}
+
+// name identifica o campo nas mensagens de erro pela variável de ambiente, conhecida dos deploys atuais
+func (f *field) name() string {
+	return f.envs[0]
+}
+
+func (f *field) key() string {
+	return strings.Join(f.path, ".")
+}
+
+func (f *field) flagName() string {
+	return strings.ReplaceAll(f.key(), "_", "-")
+}
+
+// rawValue é o valor ainda não convertido de um campo e a origem dele, usada nas mensagens de erro
+type rawValue struct {
+	value  string
+	source string
+}
+
+// Load monta a configuração a partir dos padrões, do arquivo informado em --config ou CONFIG_FILE, das
+// variáveis de ambiente e das flags em args, nessa ordem de precedência. Variáveis de ambiente vazias são
+// ignoradas. Todos os valores inválidos são reportados juntos, em vez de trocados pelo padrão
+func Load(args []string) (*Config, error) {
+	return load(args, nil)
+}
+
+// load aplica overrides, indexados pela chave do campo, acima de todas as outras fontes. O Runtime os usa
+// para os valores alterados pela rota administrativa
+func load(args []string, overrides map[string]string) (*Config, error) {
+	config := &Config{}
+	fields := collectFields(reflect.ValueOf(config).Elem(), nil)
+
+	flags, configFile, err := parseFlags(fields, args)
+	if err != nil {
+		return nil, err
+	}
+	if configFile == "" {
+		configFile = strings.TrimSpace(os.Getenv(ConfigFileEnv))
+	}
+	var fileValues map[string]any
+	if configFile != "" {
+		if fileValues, err = readConfigFile(configFile); err != nil {
+			return nil, err
+		}
+	}
+
+	errs := unknownKeys(fileValues, fields, configFile)
+	for _, f := range fields {
+		raw, err := resolve(f, fileValues, configFile, flags)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if value, ok := overrides[f.key()]; ok {
+			raw = rawValue{value: value, source: "rota administrativa"}
+		}
+		if err := setValue(f, raw.value); err != nil {
+			shown := strconv.Quote(raw.value)
+			if f.secret {
+				shown = redacted
+			}
+			errs = append(errs, fmt.Errorf("%s inválido: %s %v (%s)", f.name(), shown, err, raw.source))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	config.file = configFile
+	config.applyDerivedDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Default retorna a configuração com os valores padrão, sem ler o arquivo, o ambiente ou as flags
+func Default() *Config {
+	config := &Config{}
+	for _, f := range collectFields(reflect.ValueOf(config).Elem(), nil) {
+		if err := setValue(f, f.def); err != nil {
+			panic(fmt.Sprintf("padrão inválido para %s: %v", f.name(), err))
+		}
+	}
+	config.applyDerivedDefaults()
+	return config
+}
+
+// collectFields percorre as seções da Config. Structs com a tag key e sem a tag env são seções aninhadas
+func collectFields(v reflect.Value, path []string) []*field {
+	var fields []*field
+	for i := 0; i < v.NumField(); i++ {
+		structField := v.Type().Field(i)
+		key, ok := structField.Tag.Lookup("key")
+		if !ok {
+			continue
+		}
+		fieldPath := append(append([]string(nil), path...), key)
+		env := structField.Tag.Get("env")
+		if env == "" && structField.Type.Kind() == reflect.Struct {
+			fields = append(fields, collectFields(v.Field(i), fieldPath)...)
+			continue
+		}
+		f := &field{
+			path:   fieldPath,
+			envs:   strings.Split(env, ","),
+			def:    structField.Tag.Get("default"),
+			secret: structField.Tag.Get("secret") == "true",
+			reload: structField.Tag.Get("reload") == "true",
+			value:  v.Field(i),
+		}
+		f.unitName = structField.Tag.Get("unit")
+		switch f.unitName {
+		case "ms":
+			f.unit = time.Millisecond
+		case "s":
+			f.unit = time.Second
+		case "m":
+			f.unit = time.Minute
+		case "h":
+			f.unit = time.Hour
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// flagValue guarda o valor de uma flag e se ela foi informada, pois apenas as flags informadas sobrescrevem
+// os demais valores
+type flagValue struct {
+	value  string
+	set    bool
+	isBool bool
+}
+
+func (v *flagValue) String() string   { return v.value }
+func (v *flagValue) IsBoolFlag() bool { return v.isBool }
+
+func (v *flagValue) Set(value string) error {
+	v.value, v.set = value, true
+	return nil
+}
+
+// parseFlags registra uma flag por campo, além de --config e de --<chave>-file para os segredos
+func parseFlags(fields []*field, args []string) (map[string]*flagValue, string, error) {
+	flagSet := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	configFile := flagSet.String("config", "", "arquivo de configuração YAML ou TOML (ou "+ConfigFileEnv+")")
+	flags := make(map[string]*flagValue)
+	for _, f := range fields {
+		value := &flagValue{isBool: f.value.Kind() == reflect.Bool}
+		flags[f.flagName()] = value
+		flagSet.Var(value, f.flagName(), usage(f))
+		if f.secret {
+			fileValue := &flagValue{}
+			flags[f.flagName()+"-file"] = fileValue
+			flagSet.Var(fileValue, f.flagName()+"-file", "arquivo com o valor de "+f.name())
+		}
+	}
+	if err := flagSet.Parse(args); err != nil {
+		return nil, "", err
+	}
+	if flagSet.NArg() > 0 {
+		return nil, "", fmt.Errorf("argumento inesperado: %s", flagSet.Arg(0))
+	}
+	return flags, *configFile, nil
+}
+
+func usage(f *field) string {
+	description := strings.Join(f.envs, " ou ")
+	if f.unit != 0 {
+		description += fmt.Sprintf(" (duração; números sem unidade em %s)", f.unitName)
+	}
+	if f.def != "" && !f.secret {
+		description += fmt.Sprintf(" (padrão %s)", f.def)
+	}
+	if f.reload {
+		description += " (recarregável)"
+	}
+	return description
+}
+
+func readConfigFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível ler o arquivo de configuração: %w", err)
+	}
+	values := make(map[string]any)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		_, err = toml.Decode(string(data), &values)
+	default:
+		return nil, fmt.Errorf("formato do arquivo de configuração não suportado: %s (use .yaml, .yml ou .toml)", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("arquivo de configuração %s inválido: %w", path, err)
+	}
+	return values, nil
+}
+
+// unknownKeys reporta as chaves do arquivo que não correspondem a nenhum campo, em geral erros de digitação
+func unknownKeys(values map[string]any, fields []*field, configFile string) []error {
+	known := make(map[string]bool)
+	for _, f := range fields {
+		for i := 1; i < len(f.path); i++ {
+			known[strings.Join(f.path[:i], ".")] = true
+		}
+		known[f.key()] = true
+		if f.secret {
+			known[f.key()+"_file"] = true
+		}
+	}
+	var errs []error
+	var walk func(map[string]any, string)
+	walk = func(values map[string]any, prefix string) {
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			path := prefix + key
+			if !known[path] {
+				errs = append(errs, fmt.Errorf("chave desconhecida no arquivo %s: %s", configFile, path))
+				continue
+			}
+			if nested, ok := values[key].(map[string]any); ok && !isLeaf(fields, path) {
+				walk(nested, path+".")
+			}
+		}
+	}
+	walk(values, "")
+	return errs
+}
+
+func isLeaf(fields []*field, path string) bool {
+	for _, f := range fields {
+		if f.key() == path {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve aplica as fontes em ordem de precedência e retorna o último valor informado
+func resolve(f *field, fileValues map[string]any, configFile string, flags map[string]*flagValue) (rawValue, error) {
+	raw := rawValue{value: f.def, source: "padrão"}
+
+	if value, ok := lookupFile(fileValues, f.path); ok {
+		raw = rawValue{value: formatFileValue(value), source: fmt.Sprintf("arquivo %s, chave %s", configFile, f.key())}
+	}
+	if f.secret {
+		secretPath := append(append([]string(nil), f.path[:len(f.path)-1]...), f.path[len(f.path)-1]+"_file")
+		if path, ok := lookupFile(fileValues, secretPath); ok {
+			secret, err := readSecretFile(f, formatFileValue(path))
+			if err != nil {
+				return raw, err
+			}
+			raw = rawValue{value: secret, source: fmt.Sprintf("arquivo %s, chave %s_file", configFile, f.key())}
+		}
+	}
+
+	for _, env := range f.envs {
+		if value := strings.TrimSpace(os.Getenv(env)); value != "" {
+			raw = rawValue{value: value, source: "variável de ambiente " + env}
+			break
+		}
+	}
+	if f.secret {
+		if path := strings.TrimSpace(os.Getenv(f.name() + "_FILE")); path != "" {
+			secret, err := readSecretFile(f, path)
+			if err != nil {
+				return raw, err
+			}
+			raw = rawValue{value: secret, source: "variável de ambiente " + f.name() + "_FILE"}
+		}
+	}
+
+	if value := flags[f.flagName()]; value != nil && value.set {
+		raw = rawValue{value: value.value, source: "flag --" + f.flagName()}
+	}
+	if value := flags[f.flagName()+"-file"]; value != nil && value.set {
+		secret, err := readSecretFile(f, value.value)
+		if err != nil {
+			return raw, err
+		}
+		raw = rawValue{value: secret, source: "flag --" + f.flagName() + "-file"}
+	}
+	return raw, nil
+}
+
+// readSecretFile lê um segredo montado em arquivo, como os secrets do Docker e do Kubernetes, descartando a
+// quebra de linha final
+func readSecretFile(f *field, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("não foi possível ler o arquivo de %s: %w", f.name(), err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func lookupFile(values map[string]any, path []string) (any, bool) {
+	var current any = values
+	for _, key := range path {
+		section, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = section[key]; !ok {
+			return nil, false
+		}
+	}
+	return current, current != nil
+}
+
+// formatFileValue converte os valores do arquivo para o mesmo formato das variáveis de ambiente: listas
+// separadas por vírgula e mapas como chave:valor
+func formatFileValue(value any) string {
+	switch value := value.(type) {
+	case []any:
+		items := make([]string, len(value))
+		for i, item := range value {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ",")
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		items := make([]string, len(keys))
+		for i, key := range keys {
+			items[i] = fmt.Sprintf("%s:%v", key, value[key])
+		}
+		return strings.Join(items, ",")
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		return strings.TrimSpace(fmt.Sprint(value))
+	}
+}
+
+func setValue(f *field, raw string) error {
+	v := f.value
+	if v.Type() == durationType {
+		duration, err := parseDuration(raw, f.unit)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(duration))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		if raw == "" {
+			v.SetBool(false)
+			return nil
+		}
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return errors.New("não é um booleano")
+		}
+		v.SetBool(parsed)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		if raw == "" {
+			v.SetInt(0)
+			return nil
+		}
+		parsed, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("não é um número inteiro")
+		}
+		v.SetInt(parsed)
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return errors.New("não é um número")
+		}
+		v.SetFloat(parsed)
+	case reflect.Slice:
+		v.Set(reflect.ValueOf(splitList(raw)))
+	case reflect.Map:
+		items := reflect.MakeMap(v.Type())
+		for _, item := range splitList(raw) {
+			key, value, found := strings.Cut(item, ":")
+			parsed, err := parseMapValue(v.Type().Elem().Kind(), strings.TrimSpace(value))
+			if !found || err != nil {
+				return fmt.Errorf("item %q não está no formato chave:%s", item, mapValueName(v.Type().Elem().Kind()))
+			}
+			items.SetMapIndex(reflect.ValueOf(strings.TrimSpace(key)), reflect.ValueOf(parsed))
+		}
+		v.Set(items)
+	default:
+		return fmt.Errorf("tipo não suportado: %s", v.Type())
+	}
+	return nil
+}
+
+// parseMapValue converte os valores dos mapas, que são números, como os pesos das classes, ou booleanos,
+// como as feature flags
+func parseMapValue(kind reflect.Kind, raw string) (any, error) {
+	if kind == reflect.Bool {
+		return strconv.ParseBool(raw)
+	}
+	return strconv.Atoi(raw)
+}
+
+func mapValueName(kind reflect.Kind) string {
+	if kind == reflect.Bool {
+		return "booleano"
+	}
+	return "número"
+}
+
+// parseDuration aceita durações do Go (30s, 5m, 1h30m) e números, inclusive fracionários, na unidade do campo
+func parseDuration(raw string, unit time.Duration) (time.Duration, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	if unit != 0 {
+		if number, err := strconv.ParseFloat(raw, 64); err == nil {
+			return time.Duration(number * float64(unit)), nil
+		}
+	}
+	duration, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, errors.New("não é uma duração (ex.: 30s, 5m, 1h)")
+	}
+	return duration, nil
+}
+
+// splitList separa uma lista delimitada por vírgulas, ignorando itens vazios
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redacted substitui os segredos informados na impressão da configuração e nas mensagens de erro
+const redacted = "******"
+
+// Print escreve a configuração efetiva em YAML, no formato aceito pelo arquivo de configuração, com os
+// segredos informados substituídos por ******
+func (c *Config) Print(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(sectionNode(reflect.ValueOf(c).Elem())); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+func sectionNode(v reflect.Value) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for i := 0; i < v.NumField(); i++ {
+		structField := v.Type().Field(i)
+		key, ok := structField.Tag.Lookup("key")
+		if !ok {
+			continue
+		}
+		var value *yaml.Node
+		if structField.Tag.Get("env") == "" && structField.Type.Kind() == reflect.Struct {
+			value = sectionNode(v.Field(i))
+		} else {
+			value = valueNode(v.Field(i), structField.Tag.Get("secret") == "true")
+		}
+		node.Content = append(node.Content, scalarNode(key, "!!str"), value)
+	}
+	return node
+}
+
+func valueNode(v reflect.Value, secret bool) *yaml.Node {
+	if secret {
+		if v.String() == "" {
+			return scalarNode("", "!!str")
+		}
+		return scalarNode(redacted, "!!str")
+	}
+	if v.Type() == durationType {
+		return scalarNode(time.Duration(v.Int()).String(), "!!str")
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return scalarNode(strconv.FormatBool(v.Bool()), "!!bool")
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return scalarNode(strconv.FormatInt(v.Int(), 10), "!!int")
+	case reflect.Float64:
+		// o ponto decimal mantém o tipo float sem precisar da tag explícita no YAML
+		value := strconv.FormatFloat(v.Float(), 'f', -1, 64)
+		if !strings.Contains(value, ".") {
+			value += ".0"
+		}
+		return scalarNode(value, "!!float")
+	case reflect.Slice:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for i := 0; i < v.Len(); i++ {
+			node.Content = append(node.Content, scalarNode(v.Index(i).String(), "!!str"))
+		}
+		return node
+	case reflect.Map:
+		node := &yaml.Node{Kind: yaml.MappingNode, Style: yaml.FlowStyle}
+		keys := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			node.Content = append(node.Content, scalarNode(key, "!!str"), valueNode(v.MapIndex(reflect.ValueOf(key)), false))
+		}
+		return node
+	default:
+		return scalarNode(v.String(), "!!str")
+	}
+}
+
+func scalarNode(value, tag string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+}
@@ -0,0 +1,240 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Change descreve um valor alterado por uma recarga da configuração
+type Change struct {
+	Key      string `json:"key"`
+	Previous string `json:"previous"`
+	Current  string `json:"current"`
+}
+
+// Runtime mantém a configuração em uso e aplica as recargas. Apenas os campos com a tag reload são
+// alterados; as mudanças nos demais campos são ignoradas com um aviso, pois dependem de um reinício. Os
+// componentes recebem a nova configuração pelas funções registradas em Subscribe
+type Runtime struct {
+	// mu serializa as recargas e a notificação dos componentes
+	mu          sync.Mutex
+	current     atomic.Pointer[Config]
+	args        []string
+	overrides   map[string]string
+	subscribers []func(*Config)
+}
+
+// NewRuntime cria o Runtime com a configuração carregada na partida. args são as flags usadas no Load,
+// relidas a cada recarga para manter a mesma precedência
+func NewRuntime(initial *Config, args []string) *Runtime {
+	runtime := &Runtime{args: args, overrides: make(map[string]string)}
+	runtime.current.Store(initial)
+	return runtime
+}
+
+// Current retorna a configuração em uso, que não deve ser alterada
+func (r *Runtime) Current() *Config {
+	return r.current.Load()
+}
+
+// Subscribe registra uma função chamada com a nova configuração após cada recarga com alterações
+func (r *Runtime) Subscribe(apply func(*Config)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers = append(r.subscribers, apply)
+}
+
+// Reload lê novamente o arquivo, o ambiente e as flags e aplica os campos recarregáveis alterados. Uma
+// configuração inválida é descartada e a configuração em uso é mantida
+func (r *Runtime) Reload() ([]Change, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reload(r.overrides)
+}
+
+// Override altera campos recarregáveis, indexados pela chave do arquivo (ex.: worker.max_videos). Os
+// valores têm o mesmo formato do arquivo e prevalecem sobre as demais fontes até o reinício; um valor
+// vazio ou nulo remove a alteração e volta ao valor das outras fontes
+func (r *Runtime) Override(values map[string]any) ([]Change, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reloadable := make(map[string]bool)
+	for _, f := range collectFields(reflect.ValueOf(&Config{}).Elem(), nil) {
+		reloadable[f.key()] = f.reload
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	overrides := make(map[string]string, len(r.overrides))
+	for key, value := range r.overrides {
+		overrides[key] = value
+	}
+	for _, key := range keys {
+		known, exists := reloadable[key]
+		if !exists {
+			errs = append(errs, fmt.Errorf("chave desconhecida: %s", key))
+			continue
+		}
+		if !known {
+			errs = append(errs, fmt.Errorf("%s não pode ser alterado sem reiniciar a aplicação", key))
+			continue
+		}
+		value := ""
+		if values[key] != nil {
+			value = formatFileValue(values[key])
+		}
+		if value == "" {
+			delete(overrides, key)
+		} else {
+			overrides[key] = value
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	changes, err := r.reload(overrides)
+	if err != nil {
+		return nil, err
+	}
+	r.overrides = overrides
+	return changes, nil
+}
+
+// Watch recarrega a configuração quando o arquivo é alterado, verificando a data de modificação e o
+// tamanho a cada intervalo, até o contexto ser cancelado
+func (r *Runtime) Watch(ctx context.Context, interval time.Duration) {
+	file := r.Current().File()
+	if file == "" || interval <= 0 {
+		return
+	}
+	slog.Info("verificando alterações no arquivo de configuração", "file", file, "interval", interval)
+	lastModified, lastSize := fileVersion(file)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		modified, size := fileVersion(file)
+		if modified.Equal(lastModified) && size == lastSize {
+			continue
+		}
+		lastModified, lastSize = modified, size
+		slog.Info("arquivo de configuração alterado, recarregando", "file", file)
+		if _, err := r.Reload(); err != nil {
+			slog.Error("recarga da configuração descartada", "error", err)
+		}
+	}
+}
+
+func fileVersion(file string) (time.Time, int64) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return time.Time{}, -1
+	}
+	return info.ModTime(), info.Size()
+}
+
+func (r *Runtime) reload(overrides map[string]string) ([]Change, error) {
+	next, err := load(r.args, overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	current := r.Current()
+	merged := *current
+	currentFields := collectFields(reflect.ValueOf(current).Elem(), nil)
+	nextFields := collectFields(reflect.ValueOf(next).Elem(), nil)
+	mergedFields := collectFields(reflect.ValueOf(&merged).Elem(), nil)
+
+	var changes []Change
+	for i, f := range currentFields {
+		previousValue, nextValue := f.value.Interface(), nextFields[i].value.Interface()
+		if reflect.DeepEqual(previousValue, nextValue) {
+			continue
+		}
+		if !f.reload {
+			// os valores derivados de outros campos, como o prefetch, acompanham a alteração da origem e não
+			// foram alterados diretamente
+			if current.derived[f.key()] && next.derived[f.key()] {
+				slog.Debug("alteração do valor derivado ignorada, fixado na inicialização", "key", f.key())
+				continue
+			}
+			slog.Warn("alteração da configuração ignorada, requer reinício", "key", f.key())
+			continue
+		}
+		mergedFields[i].value.Set(nextFields[i].value)
+		changes = append(changes, Change{Key: f.key(), Previous: formatValue(f), Current: formatValue(nextFields[i])})
+	}
+	if len(changes) == 0 {
+		slog.Info("configuração recarregada sem alterações")
+		return nil, nil
+	}
+	if err := merged.Validate(); err != nil {
+		return nil, err
+	}
+
+	r.current.Store(&merged)
+	for _, change := range changes {
+		slog.Info("configuração alterada", "key", change.Key, "previous", change.Previous, "current", change.Current)
+	}
+	for _, apply := range r.subscribers {
+		apply(&merged)
+	}
+	return changes, nil
+}
+
+// formatValue descreve o valor do campo nos logs e na resposta da rota administrativa
+func formatValue(f *field) string {
+	v := f.value
+	if f.secret {
+		if v.String() == "" {
+			return ""
+		}
+		return redacted
+	}
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+	switch v.Kind() {
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = v.Index(i).String()
+		}
+		return strings.Join(items, ",")
+	case reflect.Map:
+		keys := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		items := make([]string, len(keys))
+		for i, key := range keys {
+			items[i] = fmt.Sprintf("%s:%v", key, v.MapIndex(reflect.ValueOf(key)).Interface())
+		}
+		return strings.Join(items, ",")
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
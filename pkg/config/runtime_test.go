@@ -0,0 +1,203 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestRuntime(t *testing.T, content string) (*Runtime, string) {
+	t.Helper()
+	path := writeFile(t, "config.yaml", content)
+	args := []string{"--config", path}
+	initial, err := Load(args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return NewRuntime(initial, args), path
+}
+
+func TestRuntime_ReloadAppliesReloadableSettings(t *testing.T) {
+	runtime, path := newTestRuntime(t, "worker:\n  max_videos: 4\nserver:\n  port: 8080\n")
+	var applied []*Config
+	runtime.Subscribe(func(settings *Config) {
+		applied = append(applied, settings)
+	})
+
+	content := "worker:\n  max_videos: 8\nserver:\n  port: 9090\nextraction:\n  fps: 2\nlog:\n  level: debug\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	changes, err := runtime.Reload()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	current := runtime.Current()
+	if current.Worker.MaxVideos != 8 || current.Worker.MaxQueued != 8 || current.Extraction.FPS != 2 || current.Log.Level != "debug" {
+		t.Errorf("Expected reloadable settings to change, got %+v, fps %g, log %s", current.Worker, current.Extraction.FPS, current.Log.Level)
+	}
+	if current.Server.Port != 8080 {
+		t.Errorf("Expected port to require restart, got %d", current.Server.Port)
+	}
+	if len(applied) != 1 || applied[0] != current {
+		t.Errorf("Expected subscriber to be called once with the new configuration, got %d calls", len(applied))
+	}
+
+	var keys []string
+	for _, change := range changes {
+		keys = append(keys, change.Key)
+	}
+	if strings.Join(keys, ",") != "worker.max_videos,worker.max_queued,extraction.fps,log.level" {
+		t.Errorf("Unexpected changes: %+v", changes)
+	}
+	if changes[0].Previous != "4" || changes[0].Current != "8" {
+		t.Errorf("Unexpected change values: %+v", changes[0])
+	}
+}
+
+// captureWarnings direciona os logs do pacote para um buffer, restaurando o logger padrão ao fim do teste
+func captureWarnings(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelWarn})))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &logs
+}
+
+func TestRuntime_ReloadKeepsDerivedPrefetchWithoutRestartWarning(t *testing.T) {
+	runtime, path := newTestRuntime(t, "worker:\n  max_videos: 4\n")
+	logs := captureWarnings(t)
+
+	if err := os.WriteFile(path, []byte("worker:\n  max_videos: 8\n"), 0o600); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	changes, err := runtime.Reload()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if prefetch := runtime.Current().Broker.Prefetch; prefetch != 4 {
+		t.Errorf("Expected the derived prefetch to keep the startup value 4, got %d", prefetch)
+	}
+	var keys []string
+	for _, change := range changes {
+		keys = append(keys, change.Key)
+	}
+	if strings.Join(keys, ",") != "worker.max_videos,worker.max_queued" {
+		t.Errorf("Unexpected changes: %+v", changes)
+	}
+	if strings.Contains(logs.String(), "requer reinício") {
+		t.Errorf("Expected no restart warning for the derived prefetch, got %q", logs.String())
+	}
+}
+
+func TestRuntime_ReloadWarnsForExplicitPrefetch(t *testing.T) {
+	runtime, path := newTestRuntime(t, "worker:\n  max_videos: 4\nbroker:\n  prefetch: 4\n")
+	logs := captureWarnings(t)
+
+	if err := os.WriteFile(path, []byte("worker:\n  max_videos: 4\nbroker:\n  prefetch: 8\n"), 0o600); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := runtime.Reload(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if prefetch := runtime.Current().Broker.Prefetch; prefetch != 4 {
+		t.Errorf("Expected prefetch to require restart, got %d", prefetch)
+	}
+	if !strings.Contains(logs.String(), "requer reinício") || !strings.Contains(logs.String(), "broker.prefetch") {
+		t.Errorf("Expected a restart warning for broker.prefetch, got %q", logs.String())
+	}
+}
+
+func TestRuntime_ReloadKeepsCurrentOnInvalidConfiguration(t *testing.T) {
+	runtime, path := newTestRuntime(t, "worker:\n  max_videos: 4\n")
+	called := false
+	runtime.Subscribe(func(*Config) { called = true })
+
+	if err := os.WriteFile(path, []byte("worker:\n  max_videos: abc\n"), 0o600); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := runtime.Reload(); err == nil || !strings.Contains(err.Error(), "MAX_VIDEOS inválido") {
+		t.Fatalf("Expected invalid value error, got %v", err)
+	}
+	if runtime.Current().Worker.MaxVideos != 4 || called {
+		t.Errorf("Expected current configuration to be kept")
+	}
+}
+
+func TestRuntime_Override(t *testing.T) {
+	runtime, _ := newTestRuntime(t, "worker:\n  max_videos: 4\n")
+
+	_, err := runtime.Override(map[string]any{"server.port": 9090, "worker.max_video": 1})
+	if err == nil || !strings.Contains(err.Error(), "server.port não pode ser alterado sem reiniciar a aplicação") ||
+		!strings.Contains(err.Error(), "chave desconhecida: worker.max_video") {
+		t.Fatalf("Expected override errors, got %v", err)
+	}
+	if _, err := runtime.Override(map[string]any{"extraction.image_quality": 300}); err == nil {
+		t.Fatal("Expected validation error")
+	}
+
+	changes, err := runtime.Override(map[string]any{"worker.max_videos": float64(6), "features": map[string]any{"email_notifications": false}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	current := runtime.Current()
+	if len(changes) != 3 || current.Worker.MaxVideos != 6 || current.Features.Enabled(FeatureEmailNotifications) {
+		t.Errorf("Unexpected override result: %+v, features %v", changes, current.Features)
+	}
+
+	// a alteração prevalece nas recargas até ser removida
+	if _, err := runtime.Reload(); err != nil || runtime.Current().Worker.MaxVideos != 6 {
+		t.Fatalf("Expected override to survive reload, got %d (%v)", runtime.Current().Worker.MaxVideos, err)
+	}
+	if _, err := runtime.Override(map[string]any{"worker.max_videos": nil}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if runtime.Current().Worker.MaxVideos != 4 {
+		t.Errorf("Expected value from file after removing override, got %d", runtime.Current().Worker.MaxVideos)
+	}
+}
+
+func TestRuntime_WatchReloadsChangedFile(t *testing.T) {
+	runtime, path := newTestRuntime(t, "worker:\n  max_videos: 4\n")
+	applied := make(chan *Config, 1)
+	runtime.Subscribe(func(settings *Config) { applied <- settings })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go runtime.Watch(ctx, 10*time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+
+	if err := os.WriteFile(path, []byte("worker:\n  max_videos: 12\n"), 0o600); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	select {
+	case settings := <-applied:
+		if settings.Worker.MaxVideos != 12 {
+			t.Errorf("Expected reloaded value, got %d", settings.Worker.MaxVideos)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected file change to be reloaded")
+	}
+}
+
+func TestFeatureFlags_Enabled(t *testing.T) {
+	if !(FeatureFlags{}).Enabled(FeatureEmailNotifications) {
+		t.Error("Expected default to be used when the flag is not informed")
+	}
+	if (FeatureFlags{FeatureEmailNotifications: false}).Enabled(FeatureEmailNotifications) {
+		t.Error("Expected informed flag to be used")
+	}
+
+	t.Setenv("FEATURE_FLAGS", "unknown:true")
+	if _, err := Load(nil); err == nil || !strings.Contains(err.Error(), "feature flag desconhecida unknown") {
+		t.Errorf("Expected unknown feature flag error, got %v", err)
+	}
+}
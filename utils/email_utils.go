@@ -4,21 +4,23 @@ import (
 	"fmt"
 	"log/slog"
 	"net/smtp"
-	"os"
+
+	"github.com/backstagefood/video-processor-worker/pkg/config"
 )
 
-func SendEmail(emailAddress, title, body string) {
+// SendEmail envia o aviso pela conta configurada em settings, sem enviar nada quando ela não foi informada
+func SendEmail(settings config.EmailConfig, emailAddress, title, body string) {
 	slog.Info("enviando email de erro para usuario", "emailAddress", emailAddress)
 	// SMTP server configuration
 	smtpHost := "smtp.gmail.com"
 	smtpPort := 587
 
 	// Sender data
-	from := os.Getenv("EMAIL_SENDER_FROM")
-	password := os.Getenv("EMAIL_SENDER_PASSWORD")
+	from := settings.From
+	password := settings.Password
 
 	if len(from) == 0 || len(password) == 0 {
-		slog.Info("email ou senha não foram configurados", "email", from, "password", password, "smtpHost", smtpHost, "smtpPort", smtpPort)
+		slog.Info("email ou senha não foram configurados", "email", from, "smtpHost", smtpHost, "smtpPort", smtpPort)
 		return
 	}
 
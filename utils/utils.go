@@ -11,7 +11,6 @@ import (
 	"io"
 	"log/slog"
 	"mime/multipart"
-	"os"
 	"os/exec"
 	"path/filepath"
 	"strconv"
@@ -20,44 +19,15 @@ import (
 	"unicode"
 )
 
-func GetEnvVarOrDefault[T any](key string, defaultValue T) T {
-	valueStr, exists := os.LookupEnv(key)
-	if !exists {
-		return defaultValue
-	}
-
-	var result T
-	var err error
-
-	switch any(defaultValue).(type) {
-	case string:
-		result = any(valueStr).(T)
-	case int:
-		var val int
-		val, err = strconv.Atoi(valueStr)
-		result = any(val).(T)
-	case int64:
-		var val int64
-		val, err = strconv.ParseInt(valueStr, 10, 64)
-		result = any(val).(T)
-	case float64:
-		var val float64
-		val, err = strconv.ParseFloat(valueStr, 64)
-		result = any(val).(T)
-	case bool:
-		var val bool
-		val, err = strconv.ParseBool(valueStr)
-		result = any(val).(T)
-	default:
-		// For unsupported types, return the default value
-		return defaultValue
-	}
-
-	if err != nil {
-		return defaultValue
+// SplitList separa uma lista delimitada por vírgulas, ignorando itens vazios
+func SplitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
-
-	return result
+	return items
 }
 
 func IsValidVideoFile(filename string) bool {
@@ -91,12 +61,14 @@ func SanitizeEmailForPath(email string) string {
 	return sanitized
 }
 
-func ExtractFrames(videoData []byte, fps float64) ([]image.Image, error) {
+// ExtractFrames extrai os frames do video com o ffmpeg e retorna também o código de saída do ffmpeg,
+// -1 quando ele não chegou a ser executado ou foi interrompido
+func ExtractFrames(ctx context.Context, videoData []byte, fps float64) ([]image.Image, int, error) {
 	if len(videoData) == 0 {
-		return nil, fmt.Errorf("videoData está vazio")
+		return nil, -1, fmt.Errorf("videoData está vazio")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx,
@@ -115,16 +87,16 @@ func ExtractFrames(videoData []byte, fps float64) ([]image.Image, error) {
 	// Configuração de pipes (igual ao seu código original)
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
-		return nil, fmt.Errorf("stdin pipe error: %w", err)
+		return nil, -1, fmt.Errorf("stdin pipe error: %w", err)
 	}
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return nil, fmt.Errorf("stdout pipe error: %w", err)
+		return nil, -1, fmt.Errorf("stdout pipe error: %w", err)
 	}
 
 	// Inicia o FFmpeg
 	if err := cmd.Start(); err != nil {
-		return nil, fmt.Errorf("ffmpeg start error: %w", err)
+		return nil, -1, fmt.Errorf("ffmpeg start error: %w", err)
 	}
 
 	// Escreve os dados no stdin em uma goroutine
@@ -156,32 +128,33 @@ func ExtractFrames(videoData []byte, fps float64) ([]image.Image, error) {
 
 	// Aguarda o término do FFmpeg e trata erros de forma mais robusta
 	err = cmd.Wait()
+	exitCode := cmd.ProcessState.ExitCode()
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			switch exitErr.ExitCode() {
 			case 1:
 				// Código 1 pode ser crítico (ex.: vídeo inválido)
 				if len(frames) == 0 {
-					return nil, fmt.Errorf("ffmpeg falhou (código 1): vídeo inválido ou sem frames")
+					return nil, exitCode, fmt.Errorf("ffmpeg falhou (código 1): vídeo inválido ou sem frames")
 				}
 				slog.Warn("ffmpeg concluiu com avisos (código 1), mas alguns frames foram extraídos")
 			case 183:
 				// Código 183 pode ocorrer em vídeos com problemas de timestamp
 				slog.Warn("ffmpeg detectou problemas de timestamp (código 183)")
-				return frames, fmt.Errorf("ffmpeg detectou problemas de timestamp(código 183)")
+				return frames, exitCode, fmt.Errorf("ffmpeg detectou problemas de timestamp(código 183)")
 			default:
-				return frames, fmt.Errorf("ffmpeg falhou com código %d: %w", exitErr.ExitCode(), err)
+				return frames, exitCode, fmt.Errorf("ffmpeg falhou com código %d: %w", exitErr.ExitCode(), err)
 			}
 		} else {
-			return frames, fmt.Errorf("erro ao aguardar ffmpeg: %w", err)
+			return frames, -1, fmt.Errorf("erro ao aguardar ffmpeg: %w", err)
 		}
 	}
 
 	if len(frames) == 0 {
-		return nil, fmt.Errorf("nenhum frame foi extraído (vídeo inválido ou vazio?)")
+		return nil, exitCode, fmt.Errorf("nenhum frame foi extraído (vídeo inválido ou vazio?)")
 	}
 
-	return frames, nil
+	return frames, exitCode, nil
 }
 
 // Custom scanner split function for JPEG frames
@@ -255,8 +228,8 @@ func GetFileSize(file multipart.File) (int64, error) {
 }
 
 // CreateImageZipInMemory creates a ZIP file in memory containing the provided images
-// and returns a multipart.File interface
-func CreateImageZipInMemory(images []image.Image) (multipart.File, error) {
+// encoded with the given JPEG quality and returns a multipart.File interface
+func CreateImageZipInMemory(images []image.Image, quality int) (multipart.File, error) {
 	// Create a buffer to hold the ZIP data in memory
 	buf := new(bytes.Buffer)
 
@@ -266,7 +239,7 @@ func CreateImageZipInMemory(images []image.Image) (multipart.File, error) {
 
 	// Add each image to the ZIP
 	for i, img := range images {
-		err := addImageToMemoryZip(zipWriter, img, i)
+		err := addImageToMemoryZip(zipWriter, img, i, quality)
 		if err != nil {
 			return nil, err
 		}
@@ -277,13 +250,16 @@ func CreateImageZipInMemory(images []image.Image) (multipart.File, error) {
 		return nil, err
 	}
 
-	// Create a reader from the buffer that implements multipart.File
-	file := &bytesFile{
+	return NewInMemoryFile(buf.Bytes()), nil
+}
+
+// NewInMemoryFile wraps the given data in a multipart.File
+func NewInMemoryFile(data []byte) multipart.File {
+	buf := bytes.NewBuffer(data)
+	return &bytesFile{
 		Reader: bytes.NewReader(buf.Bytes()),
 		buf:    buf,
 	}
-
-	return file, nil
 }
 
 // bytesFile implements multipart.File interface using bytes.Reader
@@ -320,11 +296,11 @@ func (fi fileInfo) Name() string   { return "images.zip" }
 func (fi fileInfo) Sys() any       { return nil }
 
 // addImageToZip remains the same as your original function
-func addImageToMemoryZip(zipWriter *zip.Writer, img image.Image, index int) error {
+func addImageToMemoryZip(zipWriter *zip.Writer, img image.Image, index int, quality int) error {
 	fileName := "image_" + strconv.Itoa(index) + ".jpg"
 	fileWriter, err := zipWriter.Create(fileName)
 	if err != nil {
 		return err
 	}
-	return jpeg.Encode(fileWriter, img, &jpeg.Options{Quality: 90})
+	return jpeg.Encode(fileWriter, img, &jpeg.Options{Quality: quality})
 }
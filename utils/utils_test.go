@@ -5,24 +5,20 @@ import (
 	"image"
 	"image/color"
 	"mime/multipart"
-	"os"
 	"testing"
 )
 
-func TestGetEnvVarOrDefault(t *testing.T) {
-	os.Setenv("TEST_KEY", "123")
-	defer os.Unsetenv("TEST_KEY")
+func TestSplitList(t *testing.T) {
+	result := SplitList(" mp4, mov ,,mkv ")
+	expected := []string{"mp4", "mov", "mkv"}
 
-	// Test with existing environment variable
-	result := GetEnvVarOrDefault("TEST_KEY", 0)
-	if result != 123 {
-		t.Errorf("Expected 123, got %v", result)
+	if len(result) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, result)
 	}
-
-	// Test with non-existing environment variable
-	result = GetEnvVarOrDefault("NON_EXISTENT_KEY", 42)
-	if result != 42 {
-		t.Errorf("Expected 42, got %v", result)
+	for i := range expected {
+		if result[i] != expected[i] {
+			t.Errorf("Expected %s, got %s", expected[i], result[i])
+		}
 	}
 }
 
@@ -96,7 +92,7 @@ func TestCreateImageZipInMemory(t *testing.T) {
 	}
 
 	images := []image.Image{img}
-	file, err := CreateImageZipInMemory(images)
+	file, err := CreateImageZipInMemory(images, 90)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
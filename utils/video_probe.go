@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Containers reconhecidos por SniffVideoContainer
+const (
+	ContainerMP4      = "mp4"
+	ContainerMOV      = "mov"
+	ContainerMatroska = "mkv"
+	ContainerWebM     = "webm"
+	ContainerAVI      = "avi"
+	ContainerFLV      = "flv"
+	ContainerASF      = "asf"
+)
+
+var asfHeaderGUID = []byte{0x30, 0x26, 0xB2, 0x75, 0x8E, 0x66, 0xCF, 0x11, 0xA6, 0xD9, 0x00, 0xAA, 0x00, 0x62, 0xCE, 0x6C}
+
+// SniffVideoContainer identifica o container do video pelos magic bytes do cabeçalho,
+// retornando uma string vazia quando o conteúdo não é reconhecido
+func SniffVideoContainer(header []byte) string {
+	switch {
+	case len(header) >= 12 && bytes.Equal(header[4:8], []byte("ftyp")):
+		// a major brand "qt  " identifica arquivos QuickTime
+		if bytes.Equal(header[8:12], []byte("qt  ")) {
+			return ContainerMOV
+		}
+		return ContainerMP4
+	case len(header) >= 8 && isQuickTimeAtom(header[4:8]):
+		// arquivos QuickTime antigos não possuem o atom ftyp
+		return ContainerMOV
+	case len(header) >= 4 && bytes.Equal(header[:4], []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		// o DocType do EBML diferencia WebM de Matroska
+		if bytes.Contains(header[:min(len(header), 64)], []byte("webm")) {
+			return ContainerWebM
+		}
+		return ContainerMatroska
+	case len(header) >= 12 && bytes.Equal(header[:4], []byte("RIFF")) && bytes.Equal(header[8:12], []byte("AVI ")):
+		return ContainerAVI
+	case len(header) >= 4 && bytes.Equal(header[:3], []byte("FLV")) && header[3] == 0x01:
+		return ContainerFLV
+	case len(header) >= len(asfHeaderGUID) && bytes.Equal(header[:len(asfHeaderGUID)], asfHeaderGUID):
+		return ContainerASF
+	}
+	return ""
+}
+
+func isQuickTimeAtom(atom []byte) bool {
+	switch string(atom) {
+	case "moov", "mdat", "wide", "free", "skip", "pnot":
+		return true
+	}
+	return false
+}
+
+// VideoProbe contém as informações do video obtidas pelo ffprobe
+type VideoProbe struct {
+	FormatName  string
+	Duration    float64
+	VideoCodecs []string
+	AudioCodecs []string
+}
+
+type ffprobeOutput struct {
+	Streams []struct {
+		CodecType string `json:"codec_type"`
+		CodecName string `json:"codec_name"`
+	} `json:"streams"`
+	Format struct {
+		FormatName string `json:"format_name"`
+		Duration   string `json:"duration"`
+	} `json:"format"`
+}
+
+// ProbeVideo executa o ffprobe sobre o conteúdo do video e retorna os codecs e a duração
+func ProbeVideo(ctx context.Context, videoData []byte) (*VideoProbe, error) {
+	if len(videoData) == 0 {
+		return nil, fmt.Errorf("videoData está vazio")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx,
+		"ffprobe",
+		"-v", "error",
+		"-print_format", "json",
+		"-show_format",
+		"-show_streams",
+		"pipe:0",
+	)
+	cmd.Stdin = bytes.NewReader(videoData)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe falhou: %w - %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var probeOutput ffprobeOutput
+	if err := json.Unmarshal(output, &probeOutput); err != nil {
+		return nil, fmt.Errorf("não foi possível ler a saída do ffprobe: %w", err)
+	}
+
+	probe := &VideoProbe{FormatName: probeOutput.Format.FormatName}
+	if probeOutput.Format.Duration != "" {
+		probe.Duration, _ = strconv.ParseFloat(probeOutput.Format.Duration, 64)
+	}
+	for _, stream := range probeOutput.Streams {
+		switch stream.CodecType {
+		case "video":
+			probe.VideoCodecs = append(probe.VideoCodecs, stream.CodecName)
+		case "audio":
+			probe.AudioCodecs = append(probe.AudioCodecs, stream.CodecName)
+		}
+	}
+	return probe, nil
+}
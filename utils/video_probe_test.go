@@ -0,0 +1,29 @@
+package utils
+
+import "testing"
+
+func TestSniffVideoContainer(t *testing.T) {
+	tests := map[string]struct {
+		header   []byte
+		expected string
+	}{
+		"mp4":       {append([]byte{0, 0, 0, 0x20}, []byte("ftypisom")...), ContainerMP4},
+		"mov":       {append([]byte{0, 0, 0, 0x14}, []byte("ftypqt  ")...), ContainerMOV},
+		"old mov":   {append([]byte{0, 0, 0, 0x08}, []byte("wide")...), ContainerMOV},
+		"mkv":       {append([]byte{0x1A, 0x45, 0xDF, 0xA3, 0x42, 0x82, 0x88}, []byte("matroska")...), ContainerMatroska},
+		"webm":      {append([]byte{0x1A, 0x45, 0xDF, 0xA3, 0x42, 0x82, 0x84}, []byte("webm")...), ContainerWebM},
+		"avi":       {[]byte("RIFF\x00\x00\x00\x00AVI LIST"), ContainerAVI},
+		"flv":       {[]byte("FLV\x01\x05"), ContainerFLV},
+		"asf":       {append(append([]byte{}, asfHeaderGUID...), 0x00), ContainerASF},
+		"wav":       {[]byte("RIFF\x00\x00\x00\x00WAVEfmt "), ""},
+		"jpeg":      {[]byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10}, ""},
+		"empty":     {nil, ""},
+		"too short": {[]byte("ft"), ""},
+	}
+
+	for name, tt := range tests {
+		if result := SniffVideoContainer(tt.header); result != tt.expected {
+			t.Errorf("%s: expected %q, got %q", name, tt.expected, result)
+		}
+	}
+}